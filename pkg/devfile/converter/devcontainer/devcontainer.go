@@ -0,0 +1,341 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package devcontainer converts Dev Container configurations (devcontainer.json) to devfiles, and devfiles to
+// Dev Container configurations.
+package devcontainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultDir is the directory of the devcontainer.json file, relative to the directory of the devfile
+	DefaultDir = ".devcontainer"
+	// DevContainerFile is the name of the Dev Container configuration file
+	DevContainerFile = "devcontainer.json"
+	// ComposeFile is the name of the Compose file generated next to the devcontainer.json file for the devfiles
+	// with several container components
+	ComposeFile = "docker-compose.yaml"
+
+	// localWorkspaceFolder is the variable standing for the project directory on the host
+	localWorkspaceFolder = "${localWorkspaceFolder}"
+)
+
+// DevContainer is a Dev Container configuration, as defined by the Dev Container specification.
+// Only the properties converted from and to a devfile are defined, the other properties are kept in Others.
+type DevContainer struct {
+	Name              string                    `json:"name,omitempty"`
+	Image             string                    `json:"image,omitempty"`
+	Build             *Build                    `json:"build,omitempty"`
+	DockerComposeFile StringList                `json:"dockerComposeFile,omitempty"`
+	Service           string                    `json:"service,omitempty"`
+	RunServices       []string                  `json:"runServices,omitempty"`
+	WorkspaceMount    *Mount                    `json:"workspaceMount,omitempty"`
+	WorkspaceFolder   string                    `json:"workspaceFolder,omitempty"`
+	ForwardPorts      []Port                    `json:"forwardPorts,omitempty"`
+	PortsAttributes   map[string]PortAttributes `json:"portsAttributes,omitempty"`
+	ContainerEnv      map[string]string         `json:"containerEnv,omitempty"`
+	Mounts            []Mount                   `json:"mounts,omitempty"`
+	OverrideCommand   *bool                     `json:"overrideCommand,omitempty"`
+	PostCreateCommand *LifecycleCommand         `json:"postCreateCommand,omitempty"`
+	PostStartCommand  *LifecycleCommand         `json:"postStartCommand,omitempty"`
+	HostRequirements  *HostRequirements         `json:"hostRequirements,omitempty"`
+	// Others are the properties which are not converted
+	Others map[string]json.RawMessage `json:"-"`
+}
+
+// Build is the build section of a Dev Container configuration
+type Build struct {
+	Dockerfile string            `json:"dockerfile,omitempty"`
+	Context    string            `json:"context,omitempty"`
+	Args       map[string]string `json:"args,omitempty"`
+	Target     string            `json:"target,omitempty"`
+}
+
+// PortAttributes are the attributes of a forwarded port
+type PortAttributes struct {
+	Label         string `json:"label,omitempty"`
+	Protocol      string `json:"protocol,omitempty"`
+	OnAutoForward string `json:"onAutoForward,omitempty"`
+}
+
+// HostRequirements are the minimal resources needed by a Dev Container
+type HostRequirements struct {
+	Cpus    int    `json:"cpus,omitempty"`
+	Memory  string `json:"memory,omitempty"`
+	Storage string `json:"storage,omitempty"`
+}
+
+// StringList is a list of strings, which can be written as a single string
+type StringList []string
+
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = StringList{s}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
+
+// Port is a forwarded port, either a port number of the container, or a host:port of another Compose service
+type Port struct {
+	Host string
+	Port int
+}
+
+func (p *Port) UnmarshalJSON(data []byte) error {
+	var number int
+	if err := json.Unmarshal(data, &number); err == nil {
+		*p = Port{Port: number}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid port %s", data)
+	}
+	host, port, found := strings.Cut(s, ":")
+	if !found {
+		host, port = "", s
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", s)
+	}
+	*p = Port{Host: host, Port: number}
+	return nil
+}
+
+func (p Port) MarshalJSON() ([]byte, error) {
+	if p.Host == "" {
+		return marshal(p.Port)
+	}
+	return marshal(fmt.Sprintf("%s:%d", p.Host, p.Port))
+}
+
+// Mount is a mount of a Dev Container, written either as an object, or as a comma separated list of key=value
+type Mount struct {
+	Type   string `json:"type,omitempty"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target"`
+}
+
+func (m *Mount) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		type plain Mount
+		return json.Unmarshal(data, (*plain)(m))
+	}
+	*m = Mount{}
+	for _, option := range strings.Split(s, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "type":
+			m.Type = value
+		case "source", "src":
+			m.Source = value
+		case "target", "destination", "dst":
+			m.Target = value
+		}
+	}
+	if m.Target == "" {
+		return fmt.Errorf("mount %q has no target", s)
+	}
+	return nil
+}
+
+// LifecycleCommand is a lifecycle command of a Dev Container: either a command line run in a shell, a command
+// run without a shell, or a set of named commands run in parallel
+type LifecycleCommand struct {
+	Shell    string
+	Exec     []string
+	Parallel map[string]LifecycleCommand
+}
+
+func (c *LifecycleCommand) UnmarshalJSON(data []byte) error {
+	*c = LifecycleCommand{}
+	switch bytes.TrimSpace(data)[0] {
+	case 'n':
+		return nil
+	case '"':
+		return json.Unmarshal(data, &c.Shell)
+	case '[':
+		return json.Unmarshal(data, &c.Exec)
+	case '{':
+		return json.Unmarshal(data, &c.Parallel)
+	default:
+		return fmt.Errorf("invalid lifecycle command %s", data)
+	}
+}
+
+func (c LifecycleCommand) MarshalJSON() ([]byte, error) {
+	switch {
+	case c.Parallel != nil:
+		return marshal(c.Parallel)
+	case c.Exec != nil:
+		return marshal(c.Exec)
+	default:
+		return marshal(c.Shell)
+	}
+}
+
+// knownProperties are the properties of a devcontainer.json file which are not kept in Others
+var knownProperties = map[string]bool{
+	"name": true, "image": true, "build": true, "dockerFile": true, "context": true, "dockerComposeFile": true,
+	"service": true, "runServices": true, "workspaceMount": true, "workspaceFolder": true, "forwardPorts": true,
+	"portsAttributes": true, "containerEnv": true, "mounts": true, "overrideCommand": true,
+	"postCreateCommand": true, "postStartCommand": true, "hostRequirements": true,
+}
+
+func (d *DevContainer) UnmarshalJSON(data []byte) error {
+	type plain DevContainer
+	if err := json.Unmarshal(data, (*plain)(d)); err != nil {
+		return err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key, value := range all {
+		if knownProperties[key] {
+			continue
+		}
+		if d.Others == nil {
+			d.Others = map[string]json.RawMessage{}
+		}
+		d.Others[key] = value
+	}
+
+	// the dockerFile and context properties are the legacy form of the build section
+	if _, ok := all["dockerFile"]; ok && d.Build == nil {
+		d.Build = &Build{}
+		if err := json.Unmarshal(all["dockerFile"], &d.Build.Dockerfile); err != nil {
+			return errors.Wrap(err, "invalid dockerFile")
+		}
+		if context, ok := all["context"]; ok {
+			if err := json.Unmarshal(context, &d.Build.Context); err != nil {
+				return errors.Wrap(err, "invalid context")
+			}
+		}
+	}
+	return nil
+}
+
+// OtherKeys returns the sorted names of the properties which are not converted
+func (d *DevContainer) OtherKeys() []string {
+	keys := make([]string, 0, len(d.Others))
+	for key := range d.Others {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ToJSON serializes the Dev Container configuration into indented JSON
+func (d *DevContainer) ToJSON() ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(d); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// marshal serializes a value into JSON, without escaping the characters of command lines such as &
+func marshal(v interface{}) ([]byte, error) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(out.Bytes(), []byte("\n")), nil
+}
+
+// Parse parses the content of a devcontainer.json file, written in JSON with comments and trailing commas
+func Parse(content []byte) (*DevContainer, error) {
+	devContainer := &DevContainer{}
+	if err := json.Unmarshal(standardizeJSON(content), devContainer); err != nil {
+		return nil, errors.Wrap(err, "failed to parse Dev Container configuration")
+	}
+	return devContainer, nil
+}
+
+// standardizeJSON removes the comments and the trailing commas from JSON with comments
+func standardizeJSON(content []byte) []byte {
+	var out bytes.Buffer
+	inString, escaped := false, false
+	// pendingComma is a comma which is written only if it is not followed by a closing bracket
+	pendingComma := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			out.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch {
+		case c == '/' && i+1 < len(content) && content[i+1] == '/':
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			out.WriteByte('\n')
+			continue
+		case c == '/' && i+1 < len(content) && content[i+1] == '*':
+			end := bytes.Index(content[i+2:], []byte("*/"))
+			if end < 0 {
+				i = len(content)
+			} else {
+				i += end + 3
+			}
+			out.WriteByte(' ')
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			out.WriteByte(c)
+			continue
+		}
+		if pendingComma && c != '}' && c != ']' {
+			out.WriteByte(',')
+		}
+		pendingComma = false
+		switch c {
+		case ',':
+			pendingComma = true
+		case '"':
+			inString = true
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	return out.Bytes()
+}
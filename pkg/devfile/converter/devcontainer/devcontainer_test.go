@@ -0,0 +1,233 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devcontainer
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	devfilepkg "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// checkGolden compares the content with the golden file, after updating it with the -update flag
+func checkGolden(t *testing.T, goldenPath string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("mismatch with golden file %s (-want +got):\n%s", goldenPath, diff)
+	}
+}
+
+func TestToDevfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantWarnings []string
+	}{
+		{
+			name: "image",
+			wantWarnings: []string{
+				"property customizations is not supported",
+				"property features is not supported",
+				"build target is not supported",
+				"hostRequirements storage is not supported",
+				"mount /home/node/.npmrc: bind mounts are only supported for ${localWorkspaceFolder}",
+				"portsAttributes 5000: the port is not forwarded, its attributes are ignored",
+			},
+		},
+		{
+			name: "compose",
+			wantWarnings: []string{
+				"property remoteUser is not supported",
+				"only the first Compose file docker-compose.yml is imported, the Compose files [docker-compose.override.yml] are ignored",
+				"runServices is not supported, all the services are imported",
+				"forwarded port cache:6379: cache is not a service of the Compose file, the port is ignored",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", tt.name)
+			content, err := os.ReadFile(filepath.Join(dir, DefaultDir, DevContainerFile))
+			if err != nil {
+				t.Fatalf("failed to read Dev Container configuration: %v", err)
+			}
+			devfileObj, warnings, err := ToDevfile(content, ToDevfileOptions{DevfilePath: filepath.Join(dir, "devfile.yaml")})
+			if err != nil {
+				t.Fatalf("ToDevfile() unexpected error: %v", err)
+			}
+
+			got, err := yaml.Marshal(devfileObj.Data)
+			if err != nil {
+				t.Fatalf("failed to marshal devfile: %v", err)
+			}
+			checkGolden(t, filepath.Join(dir, "devfile.yaml"), got)
+			if _, _, err = devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Data: got}); err != nil {
+				t.Errorf("converted devfile is not valid: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantWarnings, warnings); diff != "" {
+				t.Errorf("ToDevfile() warnings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFromDevfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantWarnings []string
+	}{
+		{
+			name: "image",
+		},
+		{
+			name: "compose",
+			wantWarnings: []string{
+				"docker-compose.yaml: component api-image: only container and volume components are supported, the component is ignored",
+				"component db: the endpoints are published by the Compose file, they are not forwarded",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join("testdata", tt.name)
+			devfileObj, _, err := devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Path: filepath.Join(dir, "devfile.yaml")})
+			if err != nil {
+				t.Fatalf("failed to parse devfile: %v", err)
+			}
+			conversion, warnings, err := FromDevfile(devfileObj, FromDevfileOptions{})
+			if err != nil {
+				t.Fatalf("FromDevfile() unexpected error: %v", err)
+			}
+			files, err := conversion.Files()
+			if err != nil {
+				t.Fatalf("Files() unexpected error: %v", err)
+			}
+			for _, path := range files.Paths() {
+				checkGolden(t, filepath.Join(dir, "exported", path), files[path])
+			}
+			if diff := cmp.Diff(tt.wantWarnings, warnings); diff != "" {
+				t.Errorf("FromDevfile() warnings mismatch (-want +got):\n%s", diff)
+			}
+
+			// the exported Dev Container converts back into a devfile
+			if _, _, err = ToDevfile(files[filepath.Join(DefaultDir, DevContainerFile)], ToDevfileOptions{
+				DevfilePath: filepath.Join(dir, "exported", "devfile.yaml"),
+			}); err != nil {
+				t.Errorf("ToDevfile() of the exported Dev Container unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	content := []byte(`{
+	// the legacy form of the build section
+	"dockerFile": "Dockerfile", /* relative to the configuration */
+	"context": "..",
+	"dockerComposeFile": "docker-compose.yml",
+	"forwardPorts": [3000, "db:5432"],
+	"mounts": ["source=data,target=/data,type=volume", {"type": "tmpfs", "target": "/tmp"}],
+	"postCreateCommand": ["echo", "// not a comment"],
+	"postStartCommand": {"a": "echo a,", "b": ["echo", "b"],},
+	"remoteUser": "vscode",
+}`)
+	got, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	want := &DevContainer{
+		Build:             &Build{Dockerfile: "Dockerfile", Context: ".."},
+		DockerComposeFile: StringList{"docker-compose.yml"},
+		ForwardPorts:      []Port{{Port: 3000}, {Host: "db", Port: 5432}},
+		Mounts:            []Mount{{Type: "volume", Source: "data", Target: "/data"}, {Type: "tmpfs", Target: "/tmp"}},
+		PostCreateCommand: &LifecycleCommand{Exec: []string{"echo", "// not a comment"}},
+		PostStartCommand: &LifecycleCommand{Parallel: map[string]LifecycleCommand{
+			"a": {Shell: "echo a,"},
+			"b": {Exec: []string{"echo", "b"}},
+		}},
+		Others: map[string]json.RawMessage{"remoteUser": json.RawMessage(`"vscode"`)},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err = Parse([]byte(`{"forwardPorts": ["db:postgres"]}`)); err == nil {
+		t.Errorf("Parse() expected error for an invalid port")
+	}
+}
+
+func TestToDevfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "no image",
+			content: `{"name": "empty"}`,
+			wantErr: "the Dev Container has neither an image, a build section nor a Compose file",
+		},
+		{
+			name:    "build without dockerfile",
+			content: `{"build": {"context": ".."}}`,
+			wantErr: "the build section has no dockerfile",
+		},
+		{
+			name:    "Compose file without service",
+			content: `{"dockerComposeFile": "docker-compose.yml"}`,
+			wantErr: "the service property is required with dockerComposeFile",
+		},
+		{
+			name:    "unknown service",
+			content: `{"dockerComposeFile": "docker-compose.yml", "service": "web"}`,
+			wantErr: "service web is not defined in Compose file docker-compose.yml",
+		},
+		{
+			name:    "invalid memory",
+			content: `{"image": "node:20", "hostRequirements": {"memory": "4 gigabytes"}}`,
+			wantErr: "invalid memory host requirement 4 gigabytes",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ToDevfile([]byte(tt.content), ToDevfileOptions{
+				DevfilePath: filepath.Join("testdata", "compose", "devfile.yaml"),
+			})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ToDevfile() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
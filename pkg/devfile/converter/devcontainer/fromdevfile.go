@@ -0,0 +1,489 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devcontainer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/exporter"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// projectSourceVariables are the working directories standing for the project sources
+var projectSourceVariables = map[string]bool{"${PROJECT_SOURCE}": true, "$PROJECT_SOURCE": true}
+
+// FromDevfileOptions is a struct that contains the options to convert a devfile into a Dev Container configuration
+type FromDevfileOptions struct {
+	// Dir is the directory of the devcontainer.json file, relative to the directory of the devfile. The relative
+	// paths of the devfile, such as the Dockerfile uris, are made relative to it. Defaults to DefaultDir.
+	Dir string
+	// Options are the options used to filter the devfile components and commands
+	Options common.DevfileOptions
+}
+
+// Conversion is the result of the conversion of a devfile into a Dev Container configuration
+type Conversion struct {
+	// Dir is the directory of the devcontainer.json file, relative to the directory of the devfile
+	Dir          string
+	DevContainer *DevContainer
+	// Compose is the Compose file referenced by the Dev Container, for the devfiles with several container
+	// components. It is written to ComposeFile, next to the devcontainer.json file.
+	Compose *generator.Compose
+}
+
+// Files returns the files of the conversion, indexed by path relative to the directory of the devfile
+func (c *Conversion) Files() (exporter.Files, error) {
+	content, err := c.DevContainer.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	files := exporter.Files{path.Join(c.Dir, DevContainerFile): content}
+	if c.Compose != nil {
+		if files[path.Join(c.Dir, ComposeFile)], err = c.Compose.ToYAML(); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// FromDevfile converts a devfile into a Dev Container configuration.
+// It returns the conversion, and warnings for the devfile constructs which cannot be represented in a Dev Container.
+//
+// The Dev Container runs the container component of the default run command, or else the first container component:
+// - a devfile with a single container component becomes a Dev Container based on its image, or on the Dockerfile of
+// the Image component building its image, with its environment as containerEnv and its volume mounts as mounts
+// - a devfile with several container components becomes a Dev Container based on the Compose file generated with
+// generator.GenerateCompose, whose service is the one of the selected component
+//
+// In both cases, the endpoints become forwarded ports, the sourceMapping the workspace folder, and the exec commands
+// bound to postStart events the postCreateCommand and postStartCommand.
+func FromDevfile(devfileObj parser.DevfileObj, opts FromDevfileOptions) (*Conversion, []string, error) {
+	dir := opts.Dir
+	if dir == "" {
+		dir = DefaultDir
+	}
+	c := &fromDevfileConverter{
+		dir:    path.Clean(filepath.ToSlash(dir)),
+		images: map[string]v1.Component{},
+	}
+
+	components, err := devfileObj.Data.GetComponents(opts.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+	volumes := map[string]*v1.VolumeComponent{}
+	for _, component := range components {
+		switch {
+		case component.Container != nil:
+			c.containers = append(c.containers, component)
+		case component.Image != nil:
+			c.images[component.Image.ImageName] = component
+		case component.Volume != nil:
+			volumes[component.Name] = component.Volume
+		default:
+			c.warnf("component %s: only container, image and volume components are supported, the component is ignored", component.Name)
+		}
+	}
+	if len(c.containers) == 0 {
+		return nil, nil, errors.New("the devfile has no container component to convert into a Dev Container")
+	}
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	c.commands = map[string]v1.Command{}
+	for _, command := range commands {
+		c.commands[command.Id] = command
+	}
+	main := c.selectMainComponent(commands)
+
+	metadata := devfileObj.Data.GetMetadata()
+	devContainer := &DevContainer{Name: metadata.DisplayName}
+	if devContainer.Name == "" {
+		devContainer.Name = metadata.Name
+	}
+	conversion := &Conversion{Dir: c.dir, DevContainer: devContainer}
+
+	container := main.Container
+	sourceMapping := ""
+	if container.MountSources == nil || *container.MountSources {
+		sourceMapping = container.SourceMapping
+		if sourceMapping == "" {
+			sourceMapping = generator.DevfileSourceVolumeMount
+		}
+		devContainer.WorkspaceFolder = sourceMapping
+	}
+
+	if len(c.containers) > 1 {
+		conversion.Compose, err = c.generateCompose(devfileObj, opts, main)
+		if err != nil {
+			return nil, nil, err
+		}
+		devContainer.DockerComposeFile = StringList{ComposeFile}
+		devContainer.Service = main.Name
+	} else {
+		c.convertContainer(main, volumes, devContainer, sourceMapping)
+	}
+	c.convertEndpoints(main, devContainer)
+
+	c.convertPostStartEvents(devfileObj.Data.GetEvents(), devContainer, main.Name, sourceMapping)
+	return conversion, c.warnings, nil
+}
+
+// fromDevfileConverter holds the state shared by the functions converting a devfile into a Dev Container
+type fromDevfileConverter struct {
+	// dir is the directory of the devcontainer.json file, relative to the directory of the devfile
+	dir        string
+	containers []v1.Component
+	// images are the Image components, indexed by image name
+	images map[string]v1.Component
+	// commands are all the commands of the devfile, indexed by id
+	commands map[string]v1.Command
+	// converted are the ids of the commands converted into lifecycle commands
+	converted map[string]bool
+	warnings  []string
+}
+
+func (c *fromDevfileConverter) warnf(format string, a ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, a...))
+}
+
+// selectMainComponent selects the container component of the default run command, or else the first container component
+func (c *fromDevfileConverter) selectMainComponent(commands []v1.Command) v1.Component {
+	for _, command := range commands {
+		if command.Exec == nil || command.Exec.Group == nil || command.Exec.Group.Kind != v1.RunCommandGroupKind {
+			continue
+		}
+		if command.Exec.Group.IsDefault == nil || !*command.Exec.Group.IsDefault {
+			continue
+		}
+		for _, container := range c.containers {
+			if container.Name == command.Exec.Component {
+				return container
+			}
+		}
+	}
+	return c.containers[0]
+}
+
+// generateCompose generates the Compose file of a devfile with several container components
+func (c *fromDevfileConverter) generateCompose(devfileObj parser.DevfileObj, opts FromDevfileOptions, main v1.Component) (*generator.Compose, error) {
+	compose, warnings, err := generator.GenerateCompose(devfileObj, generator.ComposeOptions{
+		ProjectSource: c.relative("."),
+		Options:       opts.Options,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range warnings {
+		// the events are converted into lifecycle commands of the Dev Container rather than in the Compose file
+		if warning != "events are not supported" {
+			c.warnf("%s: %s", ComposeFile, warning)
+		}
+	}
+	for _, container := range c.containers {
+		if container.Name != main.Name && len(container.Container.Endpoints) > 0 {
+			c.warnf("component %s: the endpoints are published by the Compose file, they are not forwarded", container.Name)
+		}
+	}
+	return compose, nil
+}
+
+// convertContainer converts the single container component of a devfile, with the Image component building its image
+func (c *fromDevfileConverter) convertContainer(main v1.Component, volumes map[string]*v1.VolumeComponent, devContainer *DevContainer, sourceMapping string) {
+	container := main.Container
+
+	devContainer.Image = container.Image
+	if image, ok := c.images[container.Image]; ok {
+		delete(c.images, container.Image)
+		if build := c.convertImage(image); build != nil {
+			devContainer.Image = ""
+			devContainer.Build = build
+		}
+	}
+	for _, imageName := range sortedKeys(c.images) {
+		c.warnf("component %s: the image %s is not the image of component %s, the component is ignored", c.images[imageName].Name, imageName, main.Name)
+	}
+
+	command := append(append([]string{}, container.Command...), container.Args...)
+	switch {
+	case reflect.DeepEqual(command, idleCommand) || reflect.DeepEqual(command, []string{"sleep", "infinity"}):
+	case len(command) == 0:
+		devContainer.OverrideCommand = new(bool)
+	default:
+		devContainer.OverrideCommand = new(bool)
+		c.warnf("component %s: the command of the container is not supported, the command of the image is run", main.Name)
+	}
+
+	for _, env := range container.Env {
+		if devContainer.ContainerEnv == nil {
+			devContainer.ContainerEnv = map[string]string{}
+		}
+		devContainer.ContainerEnv[env.Name] = env.Value
+	}
+
+	if sourceMapping != "" {
+		devContainer.WorkspaceMount = &Mount{Type: "bind", Source: localWorkspaceFolder, Target: sourceMapping}
+	} else {
+		c.warnf("component %s: mountSources false is not supported, the project sources are mounted", main.Name)
+	}
+	for _, volumeMount := range container.VolumeMounts {
+		target := generator.GetVolumeMountPath(volumeMount)
+		volume, ok := volumes[volumeMount.Name]
+		switch {
+		case !ok:
+			c.warnf("component %s: the volume %s is not a volume component, it is not mounted", main.Name, volumeMount.Name)
+		case volume.Ephemeral != nil && *volume.Ephemeral:
+			devContainer.Mounts = append(devContainer.Mounts, Mount{Type: "tmpfs", Target: target})
+		default:
+			devContainer.Mounts = append(devContainer.Mounts, Mount{Type: "volume", Source: volumeMount.Name, Target: target})
+		}
+		if ok && volume.Size != "" {
+			c.warnf("volume %s: the size of volumes is not supported", volumeMount.Name)
+		}
+	}
+
+	if container.CpuRequest != "" || container.MemoryRequest != "" {
+		devContainer.HostRequirements = c.convertRequests(main.Name, container.CpuRequest, container.MemoryRequest)
+	}
+	if container.CpuLimit != "" || container.MemoryLimit != "" {
+		c.warnf("component %s: resource limits are not supported", main.Name)
+	}
+	if container.DedicatedPod != nil && *container.DedicatedPod {
+		c.warnf("component %s: dedicatedPod is not supported", main.Name)
+	}
+	if main.Attributes.Exists(generator.ContainerOverridesAttribute) {
+		c.warnf("component %s: the %s attribute is not supported", main.Name, generator.ContainerOverridesAttribute)
+	}
+	if main.Attributes.Exists(generator.PodOverridesAttribute) {
+		c.warnf("component %s: the %s attribute is not supported", main.Name, generator.PodOverridesAttribute)
+	}
+	if container.Annotation != nil && (len(container.Annotation.Deployment) > 0 || len(container.Annotation.Service) > 0) {
+		c.warnf("component %s: annotations are not supported", main.Name)
+	}
+}
+
+// convertImage converts an Image component building its image from a local Dockerfile into a build section
+func (c *fromDevfileConverter) convertImage(image v1.Component) *Build {
+	dockerfile := image.Image.Dockerfile
+	if dockerfile == nil || dockerfile.Uri == "" || strings.Contains(dockerfile.Uri, "://") {
+		c.warnf("component %s: only images built from a Dockerfile uri relative to the devfile are supported, the image is pulled", image.Name)
+		return nil
+	}
+	buildContext := dockerfile.BuildContext
+	if buildContext == "" {
+		buildContext = "."
+	}
+	build := &Build{
+		Dockerfile: c.relative(dockerfile.Uri),
+		Context:    c.relative(buildContext),
+	}
+	for _, arg := range dockerfile.Args {
+		name, value, _ := strings.Cut(arg, "=")
+		if build.Args == nil {
+			build.Args = map[string]string{}
+		}
+		build.Args[name] = value
+	}
+	if dockerfile.RootRequired != nil && *dockerfile.RootRequired {
+		c.warnf("component %s: rootRequired is not supported", image.Name)
+	}
+	return build
+}
+
+// convertRequests converts the cpu and memory requests of a container into host requirements
+func (c *fromDevfileConverter) convertRequests(componentName, cpuRequest, memoryRequest string) *HostRequirements {
+	requirements := &HostRequirements{}
+	if cpuRequest != "" {
+		if quantity, err := resource.ParseQuantity(cpuRequest); err == nil {
+			// the host requirements are a whole number of CPUs
+			requirements.Cpus = int((quantity.MilliValue() + 999) / 1000)
+		} else {
+			c.warnf("component %s: invalid cpu request %s", componentName, cpuRequest)
+		}
+	}
+	if memoryRequest != "" {
+		if quantity, err := resource.ParseQuantity(memoryRequest); err == nil {
+			// the host requirements are a whole number of gigabytes or megabytes
+			if megabytes := (quantity.Value() + (1 << 20) - 1) >> 20; megabytes%1024 == 0 {
+				requirements.Memory = strconv.FormatInt(megabytes/1024, 10) + "gb"
+			} else {
+				requirements.Memory = strconv.FormatInt(megabytes, 10) + "mb"
+			}
+		} else {
+			c.warnf("component %s: invalid memory request %s", componentName, memoryRequest)
+		}
+	}
+	return requirements
+}
+
+// convertEndpoints converts the endpoints of the main container into forwarded ports
+func (c *fromDevfileConverter) convertEndpoints(main v1.Component, devContainer *DevContainer) {
+	for _, endpoint := range main.Container.Endpoints {
+		attributes := PortAttributes{Label: endpoint.Name}
+		switch endpoint.Exposure {
+		case v1.NoneEndpointExposure:
+			c.warnf("component %s: endpoint %s is not exposed, the port is not forwarded", main.Name, endpoint.Name)
+			continue
+		case v1.InternalEndpointExposure:
+			attributes.OnAutoForward = "ignore"
+		}
+		switch endpoint.Protocol {
+		case v1.HTTPEndpointProtocol, v1.WSEndpointProtocol:
+			attributes.Protocol = "http"
+		case v1.HTTPSEndpointProtocol, v1.WSSEndpointProtocol:
+			attributes.Protocol = "https"
+		case v1.UDPEndpointProtocol:
+			c.warnf("component %s: endpoint %s uses UDP, the port is not forwarded", main.Name, endpoint.Name)
+			continue
+		}
+		if endpoint.Path != "" {
+			c.warnf("component %s: the path of endpoint %s is not supported", main.Name, endpoint.Name)
+		}
+		devContainer.ForwardPorts = append(devContainer.ForwardPorts, Port{Port: endpoint.TargetPort})
+		if devContainer.PortsAttributes == nil {
+			devContainer.PortsAttributes = map[string]PortAttributes{}
+		}
+		devContainer.PortsAttributes[strconv.Itoa(endpoint.TargetPort)] = attributes
+	}
+}
+
+// convertPostStartEvents converts the exec commands bound to postStart events into lifecycle commands: the command
+// PostCreateCommandID, when it is the first one, into the postCreateCommand, and the others into the postStartCommand.
+func (c *fromDevfileConverter) convertPostStartEvents(events v1.Events, devContainer *DevContainer, componentName, sourceMapping string) {
+	c.converted = map[string]bool{}
+	ids := events.PostStart
+	if len(ids) > 0 && ids[0] == PostCreateCommandID {
+		devContainer.PostCreateCommand = c.lifecycleCommand(ids[:1], componentName, sourceMapping)
+		ids = ids[1:]
+	}
+	devContainer.PostStartCommand = c.lifecycleCommand(ids, componentName, sourceMapping)
+
+	for _, event := range []struct {
+		name string
+		ids  []string
+	}{
+		{"preStart", events.PreStart},
+		{"preStop", events.PreStop},
+		{"postStop", events.PostStop},
+	} {
+		if len(event.ids) > 0 {
+			c.warnf("%s events are not supported", event.name)
+		}
+	}
+	var others []string
+	for _, id := range sortedKeys(c.commands) {
+		if !c.converted[id] {
+			others = append(others, id)
+		}
+	}
+	if len(others) > 0 {
+		c.warnf("commands %v are not bound to postStart events, they are not converted", others)
+	}
+}
+
+// lifecycleCommand converts the commands bound to postStart events into a lifecycle command. The commands run in
+// sequence are joined into a single command line, and the commands of a single parallel composite command are kept
+// as parallel commands, named after their id without the id of the composite command as prefix.
+func (c *fromDevfileConverter) lifecycleCommand(ids []string, componentName, sourceMapping string) *LifecycleCommand {
+	if len(ids) == 1 && c.isParallel(ids[0]) {
+		c.converted[ids[0]] = true
+		parallel := map[string]LifecycleCommand{}
+		for _, id := range c.commands[ids[0]].Composite.Commands {
+			if commandLines := c.commandLines(id, componentName, sourceMapping); len(commandLines) > 0 {
+				parallel[strings.TrimPrefix(id, ids[0]+"-")] = LifecycleCommand{Shell: strings.Join(commandLines, " && ")}
+			}
+		}
+		if len(parallel) == 0 {
+			return nil
+		}
+		return &LifecycleCommand{Parallel: parallel}
+	}
+	var commandLines []string
+	for _, id := range ids {
+		commandLines = append(commandLines, c.commandLines(id, componentName, sourceMapping)...)
+	}
+	if len(commandLines) == 0 {
+		return nil
+	}
+	return &LifecycleCommand{Shell: strings.Join(commandLines, " && ")}
+}
+
+func (c *fromDevfileConverter) isParallel(id string) bool {
+	command, ok := c.commands[id]
+	return ok && command.Composite != nil && command.Composite.Parallel != nil && *command.Composite.Parallel
+}
+
+// commandLines returns the command lines of a command bound to a postStart event, running the commands of a
+// composite command in sequence
+func (c *fromDevfileConverter) commandLines(id, componentName, sourceMapping string) []string {
+	command, ok := c.commands[id]
+	if !ok {
+		c.warnf("command %s is not defined", id)
+		return nil
+	}
+	c.converted[id] = true
+	switch {
+	case command.Composite != nil:
+		if c.isParallel(id) {
+			c.warnf("command %s: the commands run in parallel are run in sequence", id)
+		}
+		var commandLines []string
+		for _, sub := range command.Composite.Commands {
+			commandLines = append(commandLines, c.commandLines(sub, componentName, sourceMapping)...)
+		}
+		return commandLines
+	case command.Exec != nil:
+		exec := command.Exec
+		if exec.Component != componentName {
+			c.warnf("command %s: only the commands of component %s are supported, the command is not converted", id, componentName)
+			return nil
+		}
+		if len(exec.Env) > 0 {
+			c.warnf("command %s: the environment of commands is not supported", id)
+		}
+		commandLine := exec.CommandLine
+		if exec.WorkingDir != "" && exec.WorkingDir != sourceMapping && !projectSourceVariables[exec.WorkingDir] {
+			commandLine = fmt.Sprintf("cd %q && %s", exec.WorkingDir, commandLine)
+		}
+		return []string{commandLine}
+	default:
+		c.warnf("command %s: only exec and composite commands are supported, the command is not converted", id)
+		return nil
+	}
+}
+
+// relative converts a path relative to the devfile into a path relative to the devcontainer.json file
+func (c *fromDevfileConverter) relative(p string) string {
+	if path.IsAbs(p) {
+		return p
+	}
+	relative, err := filepath.Rel(filepath.FromSlash(c.dir), filepath.FromSlash(p))
+	if err != nil {
+		return p
+	}
+	return filepath.ToSlash(relative)
+}
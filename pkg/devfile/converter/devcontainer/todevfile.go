@@ -0,0 +1,573 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package devcontainer
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/importer"
+	"github.com/devfile/library/v2/pkg/devfile/importer/compose"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// MainComponentName is the name of the container component converted from a Dev Container based on an image or
+	// a Dockerfile
+	MainComponentName = "devcontainer"
+	// PostCreateCommandID is the id of the command converted from the postCreateCommand of a Dev Container
+	PostCreateCommandID = "post-create"
+	// PostStartCommandID is the id of the command converted from the postStartCommand of a Dev Container
+	PostStartCommandID = "post-start"
+
+	maxEndpointLength = 15
+)
+
+// idleCommand keeps running the container when the Dev Container overrides the command of the image
+var idleCommand = []string{"tail", "-f", "/dev/null"}
+
+// idleCommandLines are the usual command lines keeping a container running
+var idleCommandLines = map[string]bool{"sleep infinity": true, "tail -f /dev/null": true}
+
+// ToDevfileOptions is a struct that contains the options to convert a Dev Container configuration into a devfile
+type ToDevfileOptions struct {
+	// Name is the name of the devfile. Defaults to the name of the Dev Container.
+	Name string
+	// Dir is the directory of the devcontainer.json file, relative to the directory of the devfile. The relative
+	// paths of the configuration, such as the Dockerfile or the Compose files, are resolved against it.
+	// Defaults to DefaultDir.
+	Dir string
+	// DevfilePath is the path the devfile is written to. Defaults to importer.DefaultDevfilePath.
+	DevfilePath string
+	// SchemaVersion is the schema version of the devfile. Defaults to importer.DefaultSchemaVersion.
+	SchemaVersion string
+	// Fs is the filesystem the Compose files are read from. Defaults to filesystem.DefaultFs.
+	Fs filesystem.Filesystem
+}
+
+// ToDevfile converts the content of a devcontainer.json file into a devfile.
+// It returns the devfile, and warnings for the Dev Container properties which cannot be represented in a devfile.
+//
+// A Dev Container based on an image or a Dockerfile becomes the container component MainComponentName, and the build
+// section an Image component building its image. For a Dev Container based on Compose files, the first Compose file
+// is imported with the compose importer, and the properties of the Dev Container apply to the component of its service:
+// - the containerEnv becomes the environment of the container
+// - the forwarded ports become endpoints, public unless their onAutoForward attribute is "ignore"
+// - the volume and tmpfs mounts become volume components, and a bind mount of the workspace becomes the sourceMapping
+// - the hostRequirements become the cpu and memory requests of the container
+// - the postCreateCommand and postStartCommand become exec commands bound to postStart events, in that order
+func ToDevfile(content []byte, opts ToDevfileOptions) (parser.DevfileObj, []string, error) {
+	devContainer, err := Parse(content)
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if opts.Dir == "" {
+		opts.Dir = DefaultDir
+	}
+	if opts.DevfilePath == "" {
+		opts.DevfilePath = importer.DefaultDevfilePath
+	}
+	if opts.Fs == nil {
+		opts.Fs = filesystem.DefaultFs{}
+	}
+	if opts.Name == "" {
+		opts.Name = devContainer.Name
+	}
+
+	c := &toDevfileConverter{
+		devContainer:  devContainer,
+		dir:           path.Clean(filepath.ToSlash(opts.Dir)),
+		names:         importer.NewNames(),
+		endpointNames: importer.NewNames(),
+		commandIDs:    importer.NewNames(),
+		volumes:       map[string]string{},
+	}
+	for _, key := range devContainer.OtherKeys() {
+		c.warnf("property %s is not supported", key)
+	}
+
+	var devfileObj parser.DevfileObj
+	if len(devContainer.DockerComposeFile) > 0 {
+		devfileObj, err = c.importCompose(opts)
+	} else {
+		devfileObj, err = c.importImage(opts)
+	}
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	main := c.components[c.main].Container
+
+	for _, name := range sortedKeys(devContainer.ContainerEnv) {
+		setEnv(&main.Container, name, devContainer.ContainerEnv[name])
+	}
+	if err = c.convertHostRequirements(&main.Container); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	c.convertWorkspace(&main.Container)
+	c.convertMounts(&main.Container)
+	c.convertPorts()
+
+	workingDir := ""
+	if devContainer.WorkspaceFolder != "" && devContainer.WorkspaceFolder != main.SourceMapping {
+		workingDir = devContainer.WorkspaceFolder
+	}
+	var commands []v1.Command
+	var postStart []string
+	for _, lifecycle := range []struct {
+		id      string
+		command *LifecycleCommand
+	}{
+		{PostCreateCommandID, devContainer.PostCreateCommand},
+		{PostStartCommandID, devContainer.PostStartCommand},
+	} {
+		converted := c.convertLifecycleCommand(lifecycle.id, lifecycle.command, c.components[c.main].Name, workingDir)
+		if len(converted) > 0 {
+			commands = append(commands, converted...)
+			postStart = append(postStart, converted[len(converted)-1].Id)
+		}
+	}
+
+	if opts.Name != "" {
+		metadata := devfileObj.Data.GetMetadata()
+		metadata.Name = importer.SanitizeName(opts.Name, 0)
+		if metadata.Name != opts.Name {
+			metadata.DisplayName = opts.Name
+		}
+		devfileObj.Data.SetMetadata(metadata)
+	}
+	for _, component := range c.components[:c.imported] {
+		if err = devfileObj.Data.UpdateComponent(component); err != nil {
+			return parser.DevfileObj{}, nil, err
+		}
+	}
+	components := append(append(append([]v1.Component{}, c.components[c.imported:]...), c.volumeComponents...), c.imageComponents...)
+	if err = devfileObj.Data.AddComponents(components); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if err = devfileObj.Data.AddCommands(commands); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if len(postStart) > 0 {
+		if err = devfileObj.Data.AddEvents(v1.Events{DevWorkspaceEvents: v1.DevWorkspaceEvents{PostStart: postStart}}); err != nil {
+			return parser.DevfileObj{}, nil, err
+		}
+	}
+	return devfileObj, c.warnings, nil
+}
+
+// toDevfileConverter holds the state shared by the functions converting a Dev Container into a devfile
+type toDevfileConverter struct {
+	devContainer *DevContainer
+	// dir is the directory of the devcontainer.json file, relative to the directory of the devfile
+	dir           string
+	names         *importer.Names
+	endpointNames *importer.Names
+	commandIDs    *importer.Names
+	// components are the container components; the first imported ones are already part of the devfile
+	components []v1.Component
+	imported   int
+	// main is the index of the component of the Dev Container
+	main             int
+	volumeComponents []v1.Component
+	imageComponents  []v1.Component
+	// volumes are the names of the volume components, indexed by Dev Container volume name
+	volumes  map[string]string
+	warnings []string
+}
+
+func (c *toDevfileConverter) warnf(format string, a ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, a...))
+}
+
+// importCompose imports the Compose file of a Dev Container based on Compose files
+func (c *toDevfileConverter) importCompose(opts ToDevfileOptions) (parser.DevfileObj, error) {
+	devContainer := c.devContainer
+	if devContainer.Service == "" {
+		return parser.DevfileObj{}, errors.New("the service property is required with dockerComposeFile")
+	}
+	file := devContainer.DockerComposeFile[0]
+	if len(devContainer.DockerComposeFile) > 1 {
+		c.warnf("only the first Compose file %s is imported, the Compose files %v are ignored", file, devContainer.DockerComposeFile[1:])
+	}
+	if devContainer.Image != "" || devContainer.Build != nil {
+		c.warnf("the image and build properties are ignored with dockerComposeFile")
+	}
+	if len(devContainer.RunServices) > 0 {
+		c.warnf("runServices is not supported, all the services are imported")
+	}
+
+	composePath := path.Join(c.dir, filepath.ToSlash(file))
+	content, err := opts.Fs.ReadFile(filepath.Join(filepath.Dir(opts.DevfilePath), filepath.FromSlash(composePath)))
+	if err != nil {
+		return parser.DevfileObj{}, errors.Wrapf(err, "failed to read Compose file %s", file)
+	}
+	devfileObj, warnings, err := compose.Import(content, compose.Options{
+		Name:          opts.Name,
+		DevfilePath:   opts.DevfilePath,
+		SchemaVersion: opts.SchemaVersion,
+		BaseDir:       path.Dir(composePath),
+	})
+	if err != nil {
+		return parser.DevfileObj{}, errors.Wrapf(err, "failed to import Compose file %s", file)
+	}
+	for _, warning := range warnings {
+		c.warnf("%s: %s", file, warning)
+	}
+
+	components, err := devfileObj.Data.GetComponents(common.DevfileOptions{})
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	c.main = -1
+	for _, component := range components {
+		c.names.Unique(component.Name, 0)
+		if component.Container == nil {
+			continue
+		}
+		for _, endpoint := range component.Container.Endpoints {
+			c.endpointNames.Unique(endpoint.Name, maxEndpointLength)
+		}
+		if component.Name == importer.SanitizeName(devContainer.Service, 0) {
+			c.main = len(c.components)
+		}
+		c.components = append(c.components, component)
+	}
+	if c.main < 0 {
+		return parser.DevfileObj{}, fmt.Errorf("service %s is not defined in Compose file %s", devContainer.Service, file)
+	}
+	c.imported = len(c.components)
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	for _, command := range commands {
+		// the command of the services of Dev Containers usually only keeps their container running
+		if command.Id == compose.RunCommandID && command.Exec != nil && idleCommandLines[command.Exec.CommandLine] {
+			if err = devfileObj.Data.DeleteCommand(command.Id); err != nil {
+				return parser.DevfileObj{}, err
+			}
+			continue
+		}
+		c.commandIDs.Unique(command.Id, 0)
+	}
+	return devfileObj, nil
+}
+
+// importImage converts the image or the build section of a Dev Container into a container component, and an Image
+// component building its image
+func (c *toDevfileConverter) importImage(opts ToDevfileOptions) (parser.DevfileObj, error) {
+	devContainer := c.devContainer
+	if devContainer.Image == "" && devContainer.Build == nil {
+		return parser.DevfileObj{}, errors.New("the Dev Container has neither an image, a build section nor a Compose file")
+	}
+	devfileObj, err := importer.NewDevfileObj(opts.DevfilePath, opts.SchemaVersion)
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+
+	container := &v1.ContainerComponent{
+		Container: v1.Container{Image: devContainer.Image},
+	}
+	name := c.names.Unique(MainComponentName, 0)
+	if devContainer.OverrideCommand == nil || *devContainer.OverrideCommand {
+		container.Command = idleCommand[:1]
+		container.Args = idleCommand[1:]
+	}
+
+	if build := devContainer.Build; build != nil {
+		if build.Dockerfile == "" {
+			return parser.DevfileObj{}, errors.New("the build section has no dockerfile")
+		}
+		if container.Image == "" {
+			container.Image = name
+		}
+		buildContext := build.Context
+		if buildContext == "" {
+			buildContext = "."
+		}
+		var args []string
+		for _, arg := range sortedKeys(build.Args) {
+			args = append(args, arg+"="+build.Args[arg])
+		}
+		if build.Target != "" {
+			c.warnf("build target is not supported")
+		}
+		c.imageComponents = append(c.imageComponents, v1.Component{
+			Name: c.names.Unique(name+"-image", 0),
+			ComponentUnion: v1.ComponentUnion{
+				Image: &v1.ImageComponent{
+					Image: v1.Image{
+						ImageName: container.Image,
+						ImageUnion: v1.ImageUnion{
+							Dockerfile: &v1.DockerfileImage{
+								DockerfileSrc: v1.DockerfileSrc{Uri: path.Join(c.dir, filepath.ToSlash(build.Dockerfile))},
+								Dockerfile: v1.Dockerfile{
+									BuildContext: path.Join(c.dir, filepath.ToSlash(buildContext)),
+									Args:         args,
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}
+
+	c.components = append(c.components, v1.Component{
+		Name:           name,
+		ComponentUnion: v1.ComponentUnion{Container: container},
+	})
+	return devfileObj, nil
+}
+
+// convertWorkspace converts the bind mount of the workspace into the sourceMapping of the container
+func (c *toDevfileConverter) convertWorkspace(container *v1.Container) {
+	devContainer := c.devContainer
+	switch {
+	case devContainer.WorkspaceMount != nil:
+		c.convertMount(container, *devContainer.WorkspaceMount)
+	case len(devContainer.DockerComposeFile) == 0 && devContainer.WorkspaceFolder != "":
+		// the workspace folder of a Dev Container based on an image is the folder of the workspace mount
+		container.MountSources = nil
+		container.SourceMapping = devContainer.WorkspaceFolder
+	}
+}
+
+// convertMounts converts the mounts of the Dev Container into volume components
+func (c *toDevfileConverter) convertMounts(container *v1.Container) {
+	for _, mount := range c.devContainer.Mounts {
+		c.convertMount(container, mount)
+	}
+}
+
+func (c *toDevfileConverter) convertMount(container *v1.Container, mount Mount) {
+	switch mount.Type {
+	case "bind":
+		if strings.TrimSuffix(mount.Source, "/") != localWorkspaceFolder {
+			c.warnf("mount %s: bind mounts are only supported for %s", mount.Target, localWorkspaceFolder)
+			return
+		}
+		container.MountSources = nil
+		container.SourceMapping = mount.Target
+	case "volume", "":
+		source := mount.Source
+		if source == "" {
+			source = mount.Target
+		}
+		componentName, ok := c.volumes[source]
+		if !ok {
+			componentName = c.names.Unique(source, 0)
+			c.volumes[source] = componentName
+			c.volumeComponents = append(c.volumeComponents, v1.Component{
+				Name:           componentName,
+				ComponentUnion: v1.ComponentUnion{Volume: &v1.VolumeComponent{}},
+			})
+		}
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: componentName, Path: mount.Target})
+	case "tmpfs":
+		componentName := c.names.Unique(mount.Target, 0)
+		c.volumeComponents = append(c.volumeComponents, v1.Component{
+			Name: componentName,
+			ComponentUnion: v1.ComponentUnion{
+				Volume: &v1.VolumeComponent{Volume: v1.Volume{Ephemeral: pointer.Bool(true)}},
+			},
+		})
+		container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: componentName, Path: mount.Target})
+	default:
+		c.warnf("mount %s: %s mounts are not supported", mount.Target, mount.Type)
+	}
+}
+
+// convertPorts converts the forwarded ports into endpoints of the container of the Dev Container, or of the
+// container of the Compose service they are forwarded from
+func (c *toDevfileConverter) convertPorts() {
+	devContainer := c.devContainer
+	forwarded := map[string]bool{}
+	for _, port := range devContainer.ForwardPorts {
+		key := strconv.Itoa(port.Port)
+		component := &c.components[c.main]
+		if port.Host != "" && port.Host != "localhost" && port.Host != "127.0.0.1" {
+			key = fmt.Sprintf("%s:%d", port.Host, port.Port)
+			component = nil
+			for i := range c.components {
+				if i < c.imported && c.components[i].Name == importer.SanitizeName(port.Host, 0) {
+					component = &c.components[i]
+				}
+			}
+			if component == nil {
+				c.warnf("forwarded port %s: %s is not a service of the Compose file, the port is ignored", key, port.Host)
+				continue
+			}
+		}
+		forwarded[key] = true
+		c.addEndpoint(component.Container, port.Port, devContainer.PortsAttributes[key])
+	}
+	for _, key := range sortedKeys(devContainer.PortsAttributes) {
+		if !forwarded[key] {
+			c.warnf("portsAttributes %s: the port is not forwarded, its attributes are ignored", key)
+		}
+	}
+}
+
+func (c *toDevfileConverter) addEndpoint(container *v1.ContainerComponent, targetPort int, attributes PortAttributes) {
+	exposure := v1.PublicEndpointExposure
+	if attributes.OnAutoForward == "ignore" {
+		exposure = v1.InternalEndpointExposure
+	}
+	var protocol v1.EndpointProtocol
+	switch strings.ToLower(attributes.Protocol) {
+	case "":
+	case "http":
+		protocol = v1.HTTPEndpointProtocol
+	case "https":
+		protocol = v1.HTTPSEndpointProtocol
+	default:
+		c.warnf("forwarded port %d: protocol %s is not supported", targetPort, attributes.Protocol)
+	}
+
+	for i := range container.Endpoints {
+		endpoint := &container.Endpoints[i]
+		if endpoint.TargetPort != targetPort {
+			continue
+		}
+		if exposure == v1.PublicEndpointExposure || endpoint.Exposure == v1.NoneEndpointExposure {
+			endpoint.Exposure = exposure
+		}
+		if protocol != "" {
+			endpoint.Protocol = protocol
+		}
+		return
+	}
+
+	name := attributes.Label
+	if name == "" {
+		name = fmt.Sprintf("port-%d", targetPort)
+	}
+	container.Endpoints = append(container.Endpoints, v1.Endpoint{
+		Name:       c.endpointNames.Unique(name, maxEndpointLength),
+		TargetPort: targetPort,
+		Exposure:   exposure,
+		Protocol:   protocol,
+	})
+}
+
+// memoryPattern matches a Dev Container memory requirement: a number with a tb, gb, mb or kb unit
+var memoryPattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*(tb|gb|mb|kb)$`)
+
+// convertHostRequirements converts the cpus and memory host requirements into the requests of the container
+func (c *toDevfileConverter) convertHostRequirements(container *v1.Container) error {
+	requirements := c.devContainer.HostRequirements
+	if requirements == nil {
+		return nil
+	}
+	if requirements.Cpus > 0 {
+		container.CpuRequest = strconv.Itoa(requirements.Cpus)
+	}
+	if requirements.Memory != "" {
+		matches := memoryPattern.FindStringSubmatch(strings.ToLower(requirements.Memory))
+		if matches == nil {
+			return fmt.Errorf("invalid memory host requirement %s", requirements.Memory)
+		}
+		unit := map[string]string{"tb": "Ti", "gb": "Gi", "mb": "Mi", "kb": "Ki"}[matches[2]]
+		quantity, err := resource.ParseQuantity(matches[1] + unit)
+		if err != nil {
+			return fmt.Errorf("invalid memory host requirement %s: %w", requirements.Memory, err)
+		}
+		container.MemoryRequest = quantity.String()
+	}
+	if requirements.Storage != "" {
+		c.warnf("hostRequirements storage is not supported")
+	}
+	return nil
+}
+
+// convertLifecycleCommand converts a lifecycle command into exec commands run in the given component. The commands
+// run in parallel are grouped by a parallel composite command. The last returned command is the one to execute.
+func (c *toDevfileConverter) convertLifecycleCommand(id string, command *LifecycleCommand, component, workingDir string) []v1.Command {
+	if command == nil {
+		return nil
+	}
+	if command.Parallel != nil {
+		var commands []v1.Command
+		var ids []string
+		for _, name := range sortedKeys(command.Parallel) {
+			sub := command.Parallel[name]
+			converted := c.convertLifecycleCommand(id+"-"+name, &sub, component, workingDir)
+			if len(converted) > 0 {
+				commands = append(commands, converted...)
+				ids = append(ids, converted[len(converted)-1].Id)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return append(commands, v1.Command{
+			Id: c.commandIDs.Unique(id, 0),
+			CommandUnion: v1.CommandUnion{
+				Composite: &v1.CompositeCommand{Commands: ids, Parallel: pointer.Bool(true)},
+			},
+		})
+	}
+
+	commandLine := command.Shell
+	if command.Exec != nil {
+		commandLine = importer.ShellJoin(command.Exec)
+	}
+	if commandLine == "" {
+		return nil
+	}
+	return []v1.Command{{
+		Id: c.commandIDs.Unique(id, 0),
+		CommandUnion: v1.CommandUnion{
+			Exec: &v1.ExecCommand{
+				CommandLine: commandLine,
+				Component:   component,
+				WorkingDir:  workingDir,
+			},
+		},
+	}}
+}
+
+// setEnv sets an environment variable of the container, replacing any variable with the same name
+func setEnv(container *v1.Container, name, value string) {
+	for i := range container.Env {
+		if container.Env[i].Name == name {
+			container.Env[i].Value = value
+			return
+		}
+	}
+	container.Env = append(container.Env, v1.EnvVar{Name: name, Value: value})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
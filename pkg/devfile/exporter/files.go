@@ -0,0 +1,55 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package exporter holds the helpers shared by the exporters of devfiles to deployment tools formats.
+package exporter
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/pkg/errors"
+)
+
+// Files is the content of exported files, indexed by their slash-separated path relative to the export directory
+type Files map[string][]byte
+
+// Paths returns the paths of the files, sorted
+func (f Files) Paths() []string {
+	paths := make([]string, 0, len(f))
+	for p := range f {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Write writes the files into the given directory, creating the intermediate directories if needed
+func (f Files) Write(dir string, fs filesystem.Filesystem) error {
+	if fs == nil {
+		fs = filesystem.DefaultFs{}
+	}
+	for _, p := range f.Paths() {
+		fullPath := filepath.Join(dir, filepath.FromSlash(p))
+		if err := fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+			return errors.Wrapf(err, "failed to create directory for %s", fullPath)
+		}
+		if err := fs.WriteFile(fullPath, f[p], 0644); err != nil {
+			return errors.Wrapf(err, "failed to write %s", fullPath)
+		}
+	}
+	return nil
+}
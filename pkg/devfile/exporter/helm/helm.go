@@ -0,0 +1,426 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package helm exports a devfile as a Helm chart, built on top of the objects generated by generator.GenerateManifests.
+package helm
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/devfile/exporter"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ChartFile is the name of the chart definition file
+	ChartFile = "Chart.yaml"
+	// ValuesFile is the name of the default values file
+	ValuesFile = "values.yaml"
+	// TemplatesDir is the name of the directory holding the templates
+	TemplatesDir = "templates"
+
+	chartAPIVersion     = "v2"
+	defaultChartVersion = "0.1.0"
+)
+
+// Options is a struct that contains the options to export a devfile as a Helm chart
+type Options struct {
+	// ManifestsOptions are the options used to generate the objects rendered by the chart templates
+	ManifestsOptions generator.ManifestsOptions
+	// ChartVersion is the version of the chart. Defaults to the devfile metadata version, or 0.1.0.
+	ChartVersion string
+}
+
+// Chart is a Helm chart generated from a devfile
+type Chart struct {
+	// Files is the content of the chart files, indexed by their path relative to the chart directory
+	Files exporter.Files
+}
+
+// chartMetadata is the content of the Chart.yaml file
+type chartMetadata struct {
+	APIVersion  string   `json:"apiVersion"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Type        string   `json:"type"`
+	Version     string   `json:"version"`
+	AppVersion  string   `json:"appVersion,omitempty"`
+	Icon        string   `json:"icon,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+}
+
+// endpointValues are the values of an endpoint
+type endpointValues struct {
+	Host string `json:"host"`
+}
+
+// values is the content of the values.yaml file
+type values struct {
+	// Replicas is the number of replicas of the Deployments and StatefulSets
+	Replicas int32 `json:"replicas"`
+	// Images are the images of the containers, indexed by container component name
+	Images map[string]string `json:"images,omitempty"`
+	// Resources are the resource requirements of the containers, indexed by container component name
+	Resources map[string]interface{} `json:"resources,omitempty"`
+	// Endpoints are the values of the public endpoints, indexed by endpoint name
+	Endpoints map[string]endpointValues `json:"endpoints,omitempty"`
+	// Variables are the devfile variables
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// GenerateChart generates a Helm chart from the devfile.
+//
+// The templates render the objects generated by generator.GenerateManifests, one template per kind as returned by
+// exporter.GetManifestsFiles, and the default values are such that the rendered chart is equivalent to these objects.
+// The values expose:
+// - replicas: the number of replicas of the Deployments and StatefulSets
+// - images.<component>: the image of each container component
+// - resources.<component>: the resource requirements of each container component
+// - endpoints.<endpoint>.host: the host of the Ingress or Route of each public endpoint
+// - variables.<name>: the devfile variables
+//
+// To expose the devfile variables to the templates, the devfile should be parsed without substituting variables
+// (e.g. with parser.ParseDevfile). Variable references left in the generated objects are then rendered from the
+// variables values, while the images are substituted with the default values of the variables.
+func GenerateChart(devfileObj parser.DevfileObj, opts Options) (*Chart, error) {
+	manifestsOptions := opts.ManifestsOptions
+	if manifestsOptions.Replicas == nil {
+		manifestsOptions.Replicas = pointer.Int32(1)
+	}
+	manifests, err := generator.GenerateManifests(devfileObj, manifestsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	variables := devfileObj.Data.GetDevfileWorkspaceSpec().Variables
+	vals := values{
+		Replicas:  *manifestsOptions.Replicas,
+		Images:    map[string]string{},
+		Resources: map[string]interface{}{},
+		Endpoints: map[string]endpointValues{},
+		Variables: variables,
+	}
+	t := templater{variables: variables}
+
+	chart := &Chart{Files: exporter.Files{}}
+
+	chartMeta, err := getChartMetadata(devfileObj, opts)
+	if err != nil {
+		return nil, err
+	}
+	chart.Files[ChartFile], err = yaml.Marshal(chartMeta)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := exporter.GetManifestsFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+	handlers := map[string]func(obj map[string]interface{}, vals *values) error{
+		exporter.DeploymentsFile:  t.templateReplicatedWorkload,
+		exporter.StatefulSetsFile: t.templateReplicatedWorkload,
+		exporter.JobsFile:         t.templateWorkload,
+		exporter.CronJobsFile:     t.templateWorkload,
+		exporter.IngressesFile:    t.templateIngress,
+		exporter.RoutesFile:       t.templateRoute,
+	}
+	for _, file := range files {
+		handler := handlers[file.Name]
+		var docs []string
+		for _, obj := range file.Objects {
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return nil, err
+			}
+			if handler != nil {
+				if err = handler(u, &vals); err != nil {
+					return nil, err
+				}
+			}
+			doc, err := t.render(u)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+		}
+		chart.Files[filepath.ToSlash(filepath.Join(TemplatesDir, file.Name))] = []byte(strings.Join(docs, "---\n"))
+	}
+
+	// Images are substituted with the default values of the variables, as values are not rendered as templates
+	for name, image := range vals.Images {
+		vals.Images[name] = substituteVariables(image, variables)
+	}
+	chart.Files[ValuesFile], err = yaml.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+
+	return chart, nil
+}
+
+// Write writes the chart files into the given directory
+func (c *Chart) Write(dir string, fs filesystem.Filesystem) error {
+	return c.Files.Write(dir, fs)
+}
+
+// WriteChart generates a Helm chart from the devfile, and writes it into the given directory
+func WriteChart(devfileObj parser.DevfileObj, dir string, opts Options, fs filesystem.Filesystem) error {
+	chart, err := GenerateChart(devfileObj, opts)
+	if err != nil {
+		return err
+	}
+	return chart.Write(dir, fs)
+}
+
+func getChartMetadata(devfileObj parser.DevfileObj, opts Options) (chartMetadata, error) {
+	metadata := devfileObj.Data.GetMetadata()
+	name := opts.ManifestsOptions.Name
+	if name == "" {
+		name = metadata.Name
+	}
+	version := opts.ChartVersion
+	if version == "" {
+		version = metadata.Version
+	}
+	if version == "" {
+		version = defaultChartVersion
+	}
+	description := metadata.Description
+	if description == "" {
+		description = fmt.Sprintf("A Helm chart generated from the %s devfile", name)
+	}
+	return chartMetadata{
+		APIVersion:  chartAPIVersion,
+		Name:        name,
+		Description: description,
+		Type:        "application",
+		Version:     version,
+		AppVersion:  metadata.Version,
+		Icon:        metadata.Icon,
+		Keywords:    metadata.Tags,
+	}, nil
+}
+
+// placeholder is a value set in an object before it is marshalled, to be replaced by a template expression
+type placeholder struct {
+	// expr is the template expression replacing the placeholder value
+	expr string
+	// block is true if the expression renders a YAML block, which must be indented under the key holding the placeholder
+	block bool
+	// optional is true if the whole field is rendered only if the expression is not empty
+	optional bool
+}
+
+// optionalFieldPrefix prefixes the keys of optional fields, so that they are sorted last in their object when marshalled
+const optionalFieldPrefix = "zz-helm-optional-"
+
+var placeholderRegex = regexp.MustCompile(`__helm_placeholder_(\d+)__`)
+
+// templater replaces values of the generated objects with template expressions
+type templater struct {
+	variables    map[string]string
+	placeholders []placeholder
+}
+
+// placeholder registers a placeholder and returns the value to set in the object
+func (t *templater) placeholder(p placeholder) string {
+	t.placeholders = append(t.placeholders, p)
+	return fmt.Sprintf("__helm_placeholder_%d__", len(t.placeholders)-1)
+}
+
+// setOptional sets an optional field in the given object, rendered only if the expression is not empty
+func (t *templater) setOptional(obj map[string]interface{}, field, expr string) {
+	obj[optionalFieldPrefix+field] = t.placeholder(placeholder{expr: expr, optional: true})
+}
+
+// templateReplicatedWorkload templates the number of replicas of a Deployment or a StatefulSet, and its containers
+func (t *templater) templateReplicatedWorkload(obj map[string]interface{}, vals *values) error {
+	spec, _ := obj["spec"].(map[string]interface{})
+	if spec == nil {
+		return fmt.Errorf("%v without spec", obj["kind"])
+	}
+	spec["replicas"] = t.placeholder(placeholder{expr: "{{ .Values.replicas }}"})
+	return t.templateWorkload(obj, vals)
+}
+
+// templateWorkload templates the images and the resource requirements of the containers of a workload
+func (t *templater) templateWorkload(obj map[string]interface{}, vals *values) error {
+	spec, _ := obj["spec"].(map[string]interface{})
+	if spec == nil {
+		return fmt.Errorf("%v without spec", obj["kind"])
+	}
+	if jobTemplate, ok := spec["jobTemplate"].(map[string]interface{}); ok {
+		spec, _ = jobTemplate["spec"].(map[string]interface{})
+	}
+
+	template, _ := spec["template"].(map[string]interface{})
+	podSpec, _ := template["spec"].(map[string]interface{})
+	containers, _ := podSpec["containers"].([]interface{})
+	for _, c := range containers {
+		container, _ := c.(map[string]interface{})
+		if container == nil {
+			continue
+		}
+		name, _ := container["name"].(string)
+		if image, ok := container["image"].(string); ok {
+			vals.Images[name] = image
+			container["image"] = t.placeholder(placeholder{expr: fmt.Sprintf("{{ index .Values.images %q | quote }}", name)})
+		}
+		resources := container["resources"]
+		if resources == nil {
+			resources = map[string]interface{}{}
+		}
+		vals.Resources[name] = resources
+		container["resources"] = t.placeholder(placeholder{expr: fmt.Sprintf("toYaml (index .Values.resources %q)", name), block: true})
+	}
+	return nil
+}
+
+func (t *templater) templateIngress(obj map[string]interface{}, vals *values) error {
+	endpoint, host, err := t.endpointHost(obj, vals)
+	if err != nil {
+		return err
+	}
+	hostExpr := fmt.Sprintf("(index .Values.endpoints %q).host", endpoint)
+	spec, _ := obj["spec"].(map[string]interface{})
+	rules, _ := spec["rules"].([]interface{})
+	for _, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		if rule == nil {
+			continue
+		}
+		delete(rule, "host")
+		t.setOptional(rule, "host", hostExpr)
+	}
+	tlsList, _ := spec["tls"].([]interface{})
+	for _, tl := range tlsList {
+		tls, _ := tl.(map[string]interface{})
+		hosts, _ := tls["hosts"].([]interface{})
+		for i := range hosts {
+			if hosts[i] == host {
+				hosts[i] = t.placeholder(placeholder{expr: fmt.Sprintf("{{ %s | quote }}", hostExpr)})
+			}
+		}
+	}
+	return nil
+}
+
+func (t *templater) templateRoute(obj map[string]interface{}, vals *values) error {
+	endpoint, _, err := t.endpointHost(obj, vals)
+	if err != nil {
+		return err
+	}
+	spec, _ := obj["spec"].(map[string]interface{})
+	delete(spec, "host")
+	t.setOptional(spec, "host", fmt.Sprintf("(index .Values.endpoints %q).host", endpoint))
+	return nil
+}
+
+// endpointHost returns the name of the endpoint exposed by the given Ingress or Route, and its host,
+// and registers the host in the values
+func (t *templater) endpointHost(obj map[string]interface{}, vals *values) (string, string, error) {
+	metadata, _ := obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	labels, _ := metadata["labels"].(map[string]interface{})
+	instance, _ := labels[generator.InstanceLabel].(string)
+	endpoint := strings.TrimPrefix(name, instance+"-")
+	if endpoint == "" {
+		return "", "", fmt.Errorf("unable to find the endpoint exposed by %s", name)
+	}
+
+	var host string
+	spec, _ := obj["spec"].(map[string]interface{})
+	if h, ok := spec["host"].(string); ok {
+		host = h
+	}
+	if rules, ok := spec["rules"].([]interface{}); ok && len(rules) > 0 {
+		if rule, ok := rules[0].(map[string]interface{}); ok {
+			host, _ = rule["host"].(string)
+		}
+	}
+	vals.Endpoints[endpoint] = endpointValues{Host: host}
+	return endpoint, host, nil
+}
+
+// render marshals the object and replaces the placeholders and the devfile variable references with template expressions
+func (t *templater) render(obj map[string]interface{}) (string, error) {
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	// Escape the existing template delimiters first, so that they are rendered as is
+	content := templatizeVariables(string(b), t.variables)
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		match := placeholderRegex.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		var index int
+		fmt.Sscanf(line[match[2]:match[3]], "%d", &index)
+		p := t.placeholders[index]
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if strings.HasPrefix(strings.TrimLeft(line, " "), "- ") {
+			indent += 2
+		}
+		switch {
+		case p.optional:
+			key := strings.TrimPrefix(strings.TrimSpace(line[:match[0]]), "- ")
+			key = strings.TrimSuffix(strings.TrimPrefix(key, optionalFieldPrefix), ":")
+			pad := strings.Repeat(" ", indent)
+			lines[i] = fmt.Sprintf("%s{{- with %s }}\n%s%s: {{ . | quote }}\n%s{{- end }}", pad, p.expr, pad, key, pad)
+		case p.block:
+			lines[i] = line[:match[0]] + fmt.Sprintf("{{- %s | nindent %d }}", p.expr, indent+2) + line[match[1]:]
+		default:
+			lines[i] = line[:match[0]] + p.expr + line[match[1]:]
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+var variableRegex = regexp.MustCompile(`\{\{\s*(.*?)\s*\}\}`)
+
+// templatizeVariables replaces the references to known devfile variables with template expressions,
+// and escapes the other template delimiters
+func templatizeVariables(content string, variables map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(content, func(ref string) string {
+		name := variableRegex.FindStringSubmatch(ref)[1]
+		if _, ok := variables[name]; ok {
+			return fmt.Sprintf("{{ index .Values.variables %q }}", name)
+		}
+		return fmt.Sprintf("{{ %q }}", ref)
+	})
+}
+
+// substituteVariables replaces the references to known devfile variables with their values
+func substituteVariables(content string, variables map[string]string) string {
+	return variableRegex.ReplaceAllStringFunc(content, func(ref string) string {
+		name := variableRegex.FindStringSubmatch(ref)[1]
+		if value, ok := variables[name]; ok {
+			return value
+		}
+		return ref
+	})
+}
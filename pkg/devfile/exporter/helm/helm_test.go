@@ -0,0 +1,215 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"text/template"
+
+	devfilepkg "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func readDevfile(t *testing.T, name string) []byte {
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read devfile: %v", err)
+	}
+	return content
+}
+
+func TestGenerateChart(t *testing.T) {
+	tests := []struct {
+		name      string
+		devfile   string
+		opts      Options
+		goldenDir string
+	}{
+		{
+			name:    "with ingresses",
+			devfile: "devfile.yaml",
+			opts: Options{
+				ManifestsOptions: generator.ManifestsOptions{
+					IngressDomain: "apps.example.com",
+					TLSSecretName: "my-tls",
+				},
+			},
+			goldenDir: "chart",
+		},
+		{
+			name:    "with routes",
+			devfile: "devfile.yaml",
+			opts: Options{
+				ManifestsOptions: generator.ManifestsOptions{
+					Replicas:  pointer.Int32(3),
+					UseRoutes: true,
+				},
+				ChartVersion: "2.0.0",
+			},
+			goldenDir: "chart-routes",
+		},
+		{
+			name:    "with a StatefulSet",
+			devfile: "devfile-statefulset.yaml",
+			opts: Options{
+				ManifestsOptions: generator.ManifestsOptions{
+					Replicas: pointer.Int32(2),
+				},
+			},
+			goldenDir: "chart-statefulset",
+		},
+		{
+			name:    "with a CronJob, a HorizontalPodAutoscaler and NetworkPolicies",
+			devfile: "devfile-jobs.yaml",
+			opts: Options{
+				ManifestsOptions: generator.ManifestsOptions{
+					IngressDomain:   "apps.example.com",
+					NetworkPolicies: &generator.NetworkPolicyPeers{},
+				},
+			},
+			goldenDir: "chart-jobs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj, err := parser.ParseDevfile(parser.ParserArgs{Data: readDevfile(t, tt.devfile)})
+			if err != nil {
+				t.Fatalf("failed to parse devfile: %v", err)
+			}
+			chart, err := GenerateChart(devfileObj, tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateChart() unexpected error: %v", err)
+			}
+
+			goldenDir := filepath.Join("testdata", tt.goldenDir)
+			if *update {
+				if err = os.RemoveAll(goldenDir); err != nil {
+					t.Fatal(err)
+				}
+				if err = chart.Write(goldenDir, filesystem.DefaultFs{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for p, content := range chart.Files {
+				want, err := os.ReadFile(filepath.Join(goldenDir, filepath.FromSlash(p)))
+				if err != nil {
+					t.Errorf("failed to read golden file: %v", err)
+					continue
+				}
+				if diff := cmp.Diff(string(want), string(content)); diff != "" {
+					t.Errorf("GenerateChart() mismatch with golden file %s (-want +got):\n%s", p, diff)
+				}
+			}
+
+			// The chart rendered with the default values must be equivalent to the manifests generated from the devfile with substituted variables
+			substituted, _, err := devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Data: readDevfile(t, tt.devfile)})
+			if err != nil {
+				t.Fatalf("failed to parse devfile: %v", err)
+			}
+			manifestsOptions := tt.opts.ManifestsOptions
+			if manifestsOptions.Replicas == nil {
+				manifestsOptions.Replicas = pointer.Int32(1)
+			}
+			manifests, err := generator.GenerateManifests(substituted, manifestsOptions)
+			if err != nil {
+				t.Fatalf("GenerateManifests() unexpected error: %v", err)
+			}
+			wantYAML, err := manifests.ToYAML()
+			if err != nil {
+				t.Fatal(err)
+			}
+			gotYAML := renderChart(t, chart)
+			if diff := cmp.Diff(parseDocuments(t, wantYAML), parseDocuments(t, gotYAML)); diff != "" {
+				t.Errorf("rendered chart mismatch with generated manifests (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// renderChart renders the chart templates with the default values, using the subset of the Helm template functions used by the chart
+func renderChart(t *testing.T, chart *Chart) []byte {
+	var vals map[string]interface{}
+	if err := yaml.Unmarshal(chart.Files[ValuesFile], &vals); err != nil {
+		t.Fatalf("failed to parse values: %v", err)
+	}
+	funcs := template.FuncMap{
+		"quote": func(v interface{}) string {
+			return fmt.Sprintf("%q", fmt.Sprint(v))
+		},
+		"toYaml": func(v interface{}) string {
+			b, err := yaml.Marshal(v)
+			if err != nil {
+				t.Fatalf("toYaml: %v", err)
+			}
+			return strings.TrimSuffix(string(b), "\n")
+		},
+		"nindent": func(n int, s string) string {
+			pad := strings.Repeat(" ", n)
+			return "\n" + pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+		},
+	}
+	var templates []string
+	for p := range chart.Files {
+		if strings.HasPrefix(p, TemplatesDir+"/") {
+			templates = append(templates, p)
+		}
+	}
+	sort.Strings(templates)
+	var docs [][]byte
+	for _, p := range templates {
+		tmpl, err := template.New(p).Funcs(funcs).Parse(string(chart.Files[p]))
+		if err != nil {
+			t.Fatalf("failed to parse template %s: %v", p, err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, map[string]interface{}{"Values": vals}); err != nil {
+			t.Fatalf("failed to render template %s: %v", p, err)
+		}
+		docs = append(docs, buf.Bytes())
+	}
+	return bytes.Join(docs, []byte("---\n"))
+}
+
+// parseDocuments parses a multi-document YAML stream, and returns the documents indexed by kind and name
+func parseDocuments(t *testing.T, content []byte) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, doc := range strings.Split(string(content), "---\n") {
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("failed to parse document: %v\n%s", err, doc)
+		}
+		if obj == nil {
+			continue
+		}
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		result[fmt.Sprintf("%v/%v", obj["kind"], metadata["name"])] = obj
+	}
+	return result
+}
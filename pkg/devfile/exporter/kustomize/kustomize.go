@@ -0,0 +1,339 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kustomize exports a devfile as a Kustomize base, built on top of the objects generated by
+// generator.GenerateManifests, with an overlay for each target environment.
+package kustomize
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/devfile/exporter"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/distribution/reference"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// KustomizationFile is the name of the kustomization file of the base and of the overlays
+	KustomizationFile = "kustomization.yaml"
+	// BaseDir is the name of the directory holding the base
+	BaseDir = "base"
+	// OverlaysDir is the name of the directory holding the overlay of each environment
+	OverlaysDir = "overlays"
+	// ResourcesPatchFile is the name of the patch setting the resource requirements of the containers in an overlay
+	ResourcesPatchFile = "resources-patch.yaml"
+
+	kustomizationAPIVersion = "kustomize.config.k8s.io/v1beta1"
+	kustomizationKind       = "Kustomization"
+)
+
+// Options is a struct that contains the options to export a devfile as a Kustomize base and overlays
+type Options struct {
+	// ManifestsOptions are the options used to generate the objects of the base
+	ManifestsOptions generator.ManifestsOptions
+	// Environments are the environments an overlay is generated for
+	Environments []Environment
+}
+
+// Environment describes the overlay generated for a target environment. Values not set default to the ones of the base,
+// so the overlay skeleton lists everything that can be patched.
+type Environment struct {
+	// Name is the name of the environment, used as the name of the overlay directory
+	Name string
+	// Namespace is the namespace the resources are deployed to in the environment
+	Namespace string
+	// ImageTags are the tags of the container images, indexed by container component name
+	ImageTags map[string]string
+	// Replicas is the number of replicas of the Deployments and StatefulSets
+	Replicas *int32
+	// Resources are the resource requirements of the containers, indexed by container component name
+	Resources map[string]corev1.ResourceRequirements
+}
+
+// Kustomization is a Kustomize base and its overlays generated from a devfile
+type Kustomization struct {
+	// Files is the content of the kustomization files, indexed by their path relative to the export directory
+	Files exporter.Files
+}
+
+// kustomization is the content of a kustomization.yaml file. Only the fields used by the exporter are defined.
+type kustomization struct {
+	APIVersion string     `json:"apiVersion"`
+	Kind       string     `json:"kind"`
+	Namespace  string     `json:"namespace,omitempty"`
+	Resources  []string   `json:"resources,omitempty"`
+	Images     []image    `json:"images,omitempty"`
+	Replicas   []replicas `json:"replicas,omitempty"`
+	Patches    []patch    `json:"patches,omitempty"`
+}
+
+// image is a kustomization image transformation
+type image struct {
+	Name   string `json:"name"`
+	NewTag string `json:"newTag,omitempty"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// replicas is a kustomization replicas transformation
+type replicas struct {
+	Name  string `json:"name"`
+	Count int32  `json:"count"`
+}
+
+// patch is a kustomization patch, read from a file
+type patch struct {
+	Path string `json:"path"`
+}
+
+// GenerateKustomization generates a Kustomize base from the devfile, and an overlay for each environment of the options.
+//
+// The base lists the objects generated by generator.GenerateManifests, grouped by kind in separate files as returned
+// by exporter.GetManifestsFiles. As the workloads are built with generator.GetPodTemplateSpec, the pod-overrides and
+// container-overrides attributes are already applied in the base. Each overlay references the base, and patches:
+// - the tags of the container images
+// - the number of replicas of the Deployments and StatefulSets
+// - the resource requirements of the containers, with a strategic merge patch of the Deployments, StatefulSets, Jobs
+// and CronJobs
+func GenerateKustomization(devfileObj parser.DevfileObj, opts Options) (*Kustomization, error) {
+	seen := map[string]bool{}
+	for _, env := range opts.Environments {
+		if env.Name == "" {
+			return nil, fmt.Errorf("environment name must not be empty")
+		}
+		if seen[env.Name] {
+			return nil, fmt.Errorf("environment %q is defined more than once", env.Name)
+		}
+		seen[env.Name] = true
+	}
+
+	manifestsOptions := opts.ManifestsOptions
+	if manifestsOptions.Replicas == nil {
+		manifestsOptions.Replicas = pointer.Int32(1)
+	}
+	manifests, err := generator.GenerateManifests(devfileObj, manifestsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Kustomization{Files: exporter.Files{}}
+
+	base := newKustomization()
+	files, err := exporter.GetManifestsFiles(manifests)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := generator.ObjectsToYAML(file.Objects)
+		if err != nil {
+			return nil, err
+		}
+		k.Files[path.Join(BaseDir, file.Name)] = content
+		base.Resources = append(base.Resources, file.Name)
+	}
+	if k.Files[path.Join(BaseDir, KustomizationFile)], err = yaml.Marshal(base); err != nil {
+		return nil, err
+	}
+
+	for _, env := range opts.Environments {
+		overlayFiles, err := generateOverlay(getWorkloads(manifests), *manifestsOptions.Replicas, env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate overlay for environment %q: %w", env.Name, err)
+		}
+		for p, content := range overlayFiles {
+			k.Files[path.Join(OverlaysDir, env.Name, p)] = content
+		}
+	}
+
+	return k, nil
+}
+
+// Write writes the kustomization files into the given directory
+func (k *Kustomization) Write(dir string, fs filesystem.Filesystem) error {
+	return k.Files.Write(dir, fs)
+}
+
+// WriteKustomization generates a Kustomize base and overlays from the devfile, and writes them into the given directory
+func WriteKustomization(devfileObj parser.DevfileObj, dir string, opts Options, fs filesystem.Filesystem) error {
+	k, err := GenerateKustomization(devfileObj, opts)
+	if err != nil {
+		return err
+	}
+	return k.Write(dir, fs)
+}
+
+// workload is a workload generated by generator.GenerateManifests, patched by the overlays
+type workload struct {
+	typeMeta metav1.TypeMeta
+	name     string
+	// podSpecPath is the path of the pod spec in the workload
+	podSpecPath []string
+	podSpec     corev1.PodSpec
+	// replicated is true if the number of replicas of the workload can be set
+	replicated bool
+}
+
+// getWorkloads returns the Deployments, StatefulSets, Jobs and CronJobs of the manifests
+func getWorkloads(manifests *generator.Manifests) []workload {
+	podSpecPath := []string{"spec", "template", "spec"}
+	var workloads []workload
+	for _, deployment := range manifests.Deployments {
+		workloads = append(workloads, workload{typeMeta: deployment.TypeMeta, name: deployment.Name,
+			podSpecPath: podSpecPath, podSpec: deployment.Spec.Template.Spec, replicated: true})
+	}
+	for _, statefulSet := range manifests.StatefulSets {
+		workloads = append(workloads, workload{typeMeta: statefulSet.TypeMeta, name: statefulSet.Name,
+			podSpecPath: podSpecPath, podSpec: statefulSet.Spec.Template.Spec, replicated: true})
+	}
+	for _, job := range manifests.Jobs {
+		workloads = append(workloads, workload{typeMeta: job.TypeMeta, name: job.Name,
+			podSpecPath: podSpecPath, podSpec: job.Spec.Template.Spec})
+	}
+	for _, cronJob := range manifests.CronJobs {
+		workloads = append(workloads, workload{typeMeta: cronJob.TypeMeta, name: cronJob.Name,
+			podSpecPath: []string{"spec", "jobTemplate", "spec", "template", "spec"}, podSpec: cronJob.Spec.JobTemplate.Spec.Template.Spec})
+	}
+	return workloads
+}
+
+// generateOverlay returns the files of the overlay of an environment, indexed by their path relative to the overlay directory
+func generateOverlay(workloads []workload, defaultReplicas int32, env Environment) (exporter.Files, error) {
+	overlay := newKustomization()
+	overlay.Namespace = env.Namespace
+	overlay.Resources = []string{path.Join("..", "..", BaseDir)}
+
+	count := defaultReplicas
+	if env.Replicas != nil {
+		count = *env.Replicas
+	}
+
+	images := map[string]image{}
+	var resourcesPatches []string
+	for _, w := range workloads {
+		if w.replicated {
+			overlay.Replicas = append(overlay.Replicas, replicas{Name: w.name, Count: count})
+		}
+
+		var containers []interface{}
+		for _, container := range w.podSpec.Containers {
+			img, err := splitImage(container.Image)
+			if err != nil {
+				return nil, fmt.Errorf("invalid image of container %s: %w", container.Name, err)
+			}
+			if newTag, ok := env.ImageTags[container.Name]; ok {
+				img.NewTag = newTag
+				img.Digest = ""
+			}
+			if previous, ok := images[img.Name]; ok && previous != img {
+				return nil, fmt.Errorf("image %q is used with different references %q and %q", img.Name, previous.reference(), img.reference())
+			}
+			images[img.Name] = img
+
+			resources := container.Resources
+			if r, ok := env.Resources[container.Name]; ok {
+				resources = r
+			}
+			unstructuredResources, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&resources)
+			if err != nil {
+				return nil, err
+			}
+			containers = append(containers, map[string]interface{}{
+				"name":      container.Name,
+				"resources": unstructuredResources,
+			})
+		}
+
+		// The patch is built as an unstructured object, as the zero values of a typed workload would patch the base
+		obj := map[string]interface{}{"containers": containers}
+		for i := len(w.podSpecPath) - 1; i >= 0; i-- {
+			obj = map[string]interface{}{w.podSpecPath[i]: obj}
+		}
+		obj["apiVersion"] = w.typeMeta.APIVersion
+		obj["kind"] = w.typeMeta.Kind
+		obj["metadata"] = map[string]interface{}{"name": w.name}
+		content, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		resourcesPatches = append(resourcesPatches, string(content))
+	}
+
+	imageNames := make([]string, 0, len(images))
+	for name := range images {
+		imageNames = append(imageNames, name)
+	}
+	sort.Strings(imageNames)
+	for _, name := range imageNames {
+		overlay.Images = append(overlay.Images, images[name])
+	}
+
+	files := exporter.Files{}
+	if len(resourcesPatches) > 0 {
+		files[ResourcesPatchFile] = []byte(strings.Join(resourcesPatches, "---\n"))
+		overlay.Patches = append(overlay.Patches, patch{Path: ResourcesPatchFile})
+	}
+	content, err := yaml.Marshal(overlay)
+	if err != nil {
+		return nil, err
+	}
+	files[KustomizationFile] = content
+	return files, nil
+}
+
+func newKustomization() kustomization {
+	return kustomization{
+		APIVersion: kustomizationAPIVersion,
+		Kind:       kustomizationKind,
+	}
+}
+
+// splitImage splits an image reference into the image name matched by the kustomize images transformation, its tag
+// and its digest. The name is the familiar name of the image, as written in the devfile, such as nodejs for
+// docker.io/library/nodejs.
+func splitImage(img string) (image, error) {
+	named, err := reference.ParseNormalizedNamed(img)
+	if err != nil {
+		return image{}, fmt.Errorf("failed to parse image %q: %w", img, err)
+	}
+	result := image{Name: reference.FamiliarName(named)}
+	if tagged, ok := named.(reference.Tagged); ok {
+		result.NewTag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		result.Digest = digested.Digest().String()
+	}
+	return result, nil
+}
+
+// reference returns the image reference patched by the image transformation, such as nodejs:18
+func (i image) reference() string {
+	ref := i.Name
+	if i.NewTag != "" {
+		ref += ":" + i.NewTag
+	}
+	if i.Digest != "" {
+		ref += "@" + i.Digest
+	}
+	return ref
+}
@@ -0,0 +1,183 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kustomize
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	devfilepkg "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerateKustomization(t *testing.T) {
+	tests := []struct {
+		name      string
+		devfile   string
+		opts      Options
+		goldenDir string
+		wantErr   string
+	}{
+		{
+			name:    "base and overlays",
+			devfile: "devfile.yaml",
+			opts: Options{
+				ManifestsOptions: generator.ManifestsOptions{
+					IngressDomain: "apps.example.com",
+				},
+				Environments: []Environment{
+					{
+						Name: "dev",
+					},
+					{
+						Name:      "prod",
+						Namespace: "nodejs-prod",
+						ImageTags: map[string]string{"runtime": "1.2.0"},
+						Replicas:  pointer.Int32(3),
+						Resources: map[string]corev1.ResourceRequirements{
+							"runtime": {
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("2Gi"),
+									corev1.ResourceCPU:    resource.MustParse("1"),
+								},
+							},
+						},
+					},
+				},
+			},
+			goldenDir: "kustomization",
+		},
+		{
+			name:    "StatefulSet",
+			devfile: "devfile-statefulset.yaml",
+			opts: Options{
+				Environments: []Environment{
+					{
+						Name:      "prod",
+						ImageTags: map[string]string{"db": "1-42"},
+						Replicas:  pointer.Int32(3),
+						Resources: map[string]corev1.ResourceRequirements{
+							"db": {
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("2Gi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			goldenDir: "kustomization-statefulset",
+		},
+		{
+			name:    "duplicate environment",
+			devfile: "devfile.yaml",
+			opts: Options{
+				Environments: []Environment{{Name: "dev"}, {Name: "dev"}},
+			},
+			wantErr: `environment "dev" is defined more than once`,
+		},
+		{
+			name:    "environment without name",
+			devfile: "devfile.yaml",
+			opts: Options{
+				Environments: []Environment{{}},
+			},
+			wantErr: "environment name must not be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := os.ReadFile(filepath.Join("testdata", tt.devfile))
+			if err != nil {
+				t.Fatalf("failed to read devfile: %v", err)
+			}
+			devfileObj, _, err := devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Data: content})
+			if err != nil {
+				t.Fatalf("failed to parse devfile: %v", err)
+			}
+			k, err := GenerateKustomization(devfileObj, tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("GenerateKustomization() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateKustomization() unexpected error: %v", err)
+			}
+
+			goldenDir := filepath.Join("testdata", tt.goldenDir)
+			if *update {
+				if err = os.RemoveAll(goldenDir); err != nil {
+					t.Fatal(err)
+				}
+				if err = k.Write(goldenDir, filesystem.DefaultFs{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for p, content := range k.Files {
+				want, err := os.ReadFile(filepath.Join(goldenDir, filepath.FromSlash(p)))
+				if err != nil {
+					t.Errorf("failed to read golden file: %v", err)
+					continue
+				}
+				if diff := cmp.Diff(string(want), string(content)); diff != "" {
+					t.Errorf("GenerateKustomization() mismatch with golden file %s (-want +got):\n%s", p, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		image   string
+		want    image
+		wantErr bool
+	}{
+		{image: "nodejs", want: image{Name: "nodejs"}},
+		{image: "nodejs:18", want: image{Name: "nodejs", NewTag: "18"}},
+		{image: "docker.io/library/nodejs:18", want: image{Name: "nodejs", NewTag: "18"}},
+		{image: "localhost:5000/nodejs", want: image{Name: "localhost:5000/nodejs"}},
+		{image: "localhost:5000/nodejs:18", want: image{Name: "localhost:5000/nodejs", NewTag: "18"}},
+		{image: "quay.io/org/nodejs@" + digest, want: image{Name: "quay.io/org/nodejs", Digest: digest}},
+		{image: "localhost:5000/nodejs:18@" + digest, want: image{Name: "localhost:5000/nodejs", NewTag: "18", Digest: digest}},
+		{image: "Nodejs:18", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			got, err := splitImage(tt.image)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("splitImage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Names of the files holding the objects generated by generator.GenerateManifests, one file per kind
+const (
+	ServiceAccountsFile          = "serviceaccount.yaml"
+	RolesFile                    = "role.yaml"
+	RoleBindingsFile             = "rolebinding.yaml"
+	PersistentVolumeClaimsFile   = "pvc.yaml"
+	NetworkPoliciesFile          = "networkpolicy.yaml"
+	DeploymentsFile              = "deployment.yaml"
+	StatefulSetsFile             = "statefulset.yaml"
+	JobsFile                     = "job.yaml"
+	CronJobsFile                 = "cronjob.yaml"
+	HorizontalPodAutoscalersFile = "hpa.yaml"
+	PodDisruptionBudgetsFile     = "pdb.yaml"
+	ServicesFile                 = "service.yaml"
+	IngressesFile                = "ingress.yaml"
+	RoutesFile                   = "route.yaml"
+	HTTPRoutesFile               = "httproute.yaml"
+	GRPCRoutesFile               = "grpcroute.yaml"
+	KubernetesResourcesFile      = "kubernetes-components.yaml"
+)
+
+// ManifestsFile is a file holding the generated objects of a kind
+type ManifestsFile struct {
+	// Name is the name of the file, such as deployment.yaml
+	Name    string
+	Objects []runtime.Object
+}
+
+// GetManifestsFiles groups the objects generated by generator.GenerateManifests by kind, in the order they should be
+// applied to a cluster. Kinds without any object are omitted.
+// An error is returned if some generated objects are not held by any file, so that they are never silently dropped.
+func GetManifestsFiles(manifests *generator.Manifests) ([]ManifestsFile, error) {
+	all := []ManifestsFile{
+		{Name: ServiceAccountsFile, Objects: toObjects(manifests.ServiceAccounts)},
+		{Name: RolesFile, Objects: toObjects(manifests.Roles)},
+		{Name: RoleBindingsFile, Objects: toObjects(manifests.RoleBindings)},
+		{Name: PersistentVolumeClaimsFile, Objects: toObjects(manifests.PersistentVolumeClaims)},
+		{Name: NetworkPoliciesFile, Objects: toObjects(manifests.NetworkPolicies)},
+		{Name: DeploymentsFile, Objects: toObjects(manifests.Deployments)},
+		{Name: StatefulSetsFile, Objects: toObjects(manifests.StatefulSets)},
+		{Name: JobsFile, Objects: toObjects(manifests.Jobs)},
+		{Name: CronJobsFile, Objects: toObjects(manifests.CronJobs)},
+		{Name: HorizontalPodAutoscalersFile, Objects: toObjects(manifests.HorizontalPodAutoscalers)},
+		{Name: PodDisruptionBudgetsFile, Objects: toObjects(manifests.PodDisruptionBudgets)},
+		{Name: ServicesFile, Objects: toObjects(manifests.Services)},
+		{Name: IngressesFile, Objects: toObjects(manifests.Ingresses)},
+		{Name: RoutesFile, Objects: toObjects(manifests.Routes)},
+		{Name: HTTPRoutesFile, Objects: toObjects(manifests.HTTPRoutes)},
+		{Name: GRPCRoutesFile, Objects: toObjects(manifests.GRPCRoutes)},
+		{Name: KubernetesResourcesFile, Objects: toObjects(manifests.KubernetesResources)},
+	}
+
+	held := map[runtime.Object]bool{}
+	var files []ManifestsFile
+	for _, file := range all {
+		if len(file.Objects) == 0 {
+			continue
+		}
+		for _, obj := range file.Objects {
+			held[obj] = true
+		}
+		files = append(files, file)
+	}
+
+	kinds := map[string]bool{}
+	for _, obj := range manifests.Objects() {
+		if !held[obj] {
+			kinds[obj.GetObjectKind().GroupVersionKind().Kind] = true
+		}
+	}
+	if len(kinds) > 0 {
+		missing := make([]string, 0, len(kinds))
+		for kind := range kinds {
+			missing = append(missing, kind)
+		}
+		sort.Strings(missing)
+		return nil, fmt.Errorf("the generated objects of kinds %s cannot be exported", strings.Join(missing, ", "))
+	}
+	return files, nil
+}
+
+func toObjects[T runtime.Object](items []T) []runtime.Object {
+	objects := make([]runtime.Object, 0, len(items))
+	for _, item := range items {
+		objects = append(objects, item)
+	}
+	return objects
+}
@@ -0,0 +1,234 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Attributes configuring the autoscaling and the disruption budget of the pods. They are set either on the devfile,
+// for all the pods, or on a container component, for its pod. Only one container component of a pod can set them.
+//
+//	attributes:
+//	  autoscaling:
+//	    minReplicas: 2
+//	    maxReplicas: 10
+//	    targetCPUUtilization: 80
+//	  disruption-budget:
+//	    maxUnavailable: 1
+const (
+	// AutoscalingAttribute is an AutoscalingConfig object
+	AutoscalingAttribute = "autoscaling"
+	// DisruptionBudgetAttribute is a DisruptionBudgetConfig object
+	DisruptionBudgetAttribute = "disruption-budget"
+)
+
+// AutoscalingConfig is the configuration of the HorizontalPodAutoscaler of a pod, set by AutoscalingAttribute
+type AutoscalingConfig struct {
+	// MinReplicas is the minimum number of replicas, defaults to 1
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+	// MaxReplicas is the maximum number of replicas
+	MaxReplicas int32 `json:"maxReplicas"`
+	// TargetCPUUtilization is the target average CPU utilization of the pods, in percent of their CPU requests
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
+	// TargetMemoryUtilization is the target average memory utilization of the pods, in percent of their memory requests
+	TargetMemoryUtilization *int32 `json:"targetMemoryUtilization,omitempty"`
+}
+
+// DisruptionBudgetConfig is the configuration of the PodDisruptionBudget of a pod, set by DisruptionBudgetAttribute.
+// Exactly one of MinAvailable and MaxUnavailable must be set.
+type DisruptionBudgetConfig struct {
+	// MinAvailable is the number or the percentage of pods which must remain available during a disruption
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+	// MaxUnavailable is the number or the percentage of pods which can be unavailable during a disruption
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// HorizontalPodAutoscalerParams is a struct that contains the required data to create a HorizontalPodAutoscaler object
+type HorizontalPodAutoscalerParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// ScaleTargetRef references the Deployment or the StatefulSet scaled by the HorizontalPodAutoscaler
+	ScaleTargetRef autoscalingv2.CrossVersionObjectReference
+	// PodTemplateSpec is the pod template of the scaled workload
+	PodTemplateSpec *corev1.PodTemplateSpec
+}
+
+// GetHorizontalPodAutoscaler returns the autoscaling/v2 HorizontalPodAutoscaler of a Deployment or a StatefulSet, configured
+// by the AutoscalingAttribute attribute of the container components it runs or of the devfile, or nil if it is not set.
+// The containers of the pod template must request the resources whose utilization is targeted.
+func GetHorizontalPodAutoscaler(devfileObj parser.DevfileObj, hpaParams HorizontalPodAutoscalerParams) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	var config AutoscalingConfig
+	found, err := getPodAttribute(devfileObj, hpaParams.PodTemplateSpec.Spec.Containers, AutoscalingAttribute, &config)
+	if err != nil || !found {
+		return nil, err
+	}
+	if config.MaxReplicas < 1 {
+		return nil, fmt.Errorf("invalid %s attribute: maxReplicas must be at least 1", AutoscalingAttribute)
+	}
+	if config.MinReplicas != nil && (*config.MinReplicas < 1 || *config.MinReplicas > config.MaxReplicas) {
+		return nil, fmt.Errorf("invalid %s attribute: minReplicas must be between 1 and maxReplicas", AutoscalingAttribute)
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	for _, target := range []struct {
+		resource    corev1.ResourceName
+		utilization *int32
+	}{
+		{corev1.ResourceCPU, config.TargetCPUUtilization},
+		{corev1.ResourceMemory, config.TargetMemoryUtilization},
+	} {
+		if target.utilization == nil {
+			continue
+		}
+		if *target.utilization < 1 {
+			return nil, fmt.Errorf("invalid %s attribute: the target %s utilization must be a positive percentage", AutoscalingAttribute, target.resource)
+		}
+		for _, container := range hpaParams.PodTemplateSpec.Spec.Containers {
+			if _, ok := container.Resources.Requests[target.resource]; !ok {
+				return nil, fmt.Errorf("invalid %s attribute: the %s utilization is targeted, but container %s has no %s request",
+					AutoscalingAttribute, target.resource, container.Name, target.resource)
+			}
+		}
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: target.resource,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: target.utilization,
+				},
+			},
+		})
+	}
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta:   hpaParams.TypeMeta,
+		ObjectMeta: hpaParams.ObjectMeta,
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: hpaParams.ScaleTargetRef,
+			MinReplicas:    config.MinReplicas,
+			MaxReplicas:    config.MaxReplicas,
+			Metrics:        metrics,
+		},
+	}, nil
+}
+
+// PodDisruptionBudgetParams is a struct that contains the required data to create a PodDisruptionBudget object
+type PodDisruptionBudgetParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// PodSelectorLabels select the pods protected by the PodDisruptionBudget, as passed to GetDeployment
+	PodSelectorLabels map[string]string
+	// PodTemplateSpec is the pod template of the workload running the pods
+	PodTemplateSpec *corev1.PodTemplateSpec
+}
+
+// GetPodDisruptionBudget returns the policy/v1 PodDisruptionBudget of the pods of a workload, configured by the
+// DisruptionBudgetAttribute attribute of the container components it runs or of the devfile, or nil if it is not set
+func GetPodDisruptionBudget(devfileObj parser.DevfileObj, pdbParams PodDisruptionBudgetParams) (*policyv1.PodDisruptionBudget, error) {
+	var config DisruptionBudgetConfig
+	found, err := getPodAttribute(devfileObj, pdbParams.PodTemplateSpec.Spec.Containers, DisruptionBudgetAttribute, &config)
+	if err != nil || !found {
+		return nil, err
+	}
+	if (config.MinAvailable == nil) == (config.MaxUnavailable == nil) {
+		return nil, fmt.Errorf("invalid %s attribute: exactly one of minAvailable and maxUnavailable must be set", DisruptionBudgetAttribute)
+	}
+
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta:   pdbParams.TypeMeta,
+		ObjectMeta: pdbParams.ObjectMeta,
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   config.MinAvailable,
+			MaxUnavailable: config.MaxUnavailable,
+			Selector:       &metav1.LabelSelector{MatchLabels: pdbParams.PodSelectorLabels},
+		},
+	}, nil
+}
+
+// getPodAttribute decodes into value the attribute of the container components running in the given containers,
+// or else the attribute of the devfile. It returns false if the attribute is not set.
+func getPodAttribute(devfileObj parser.DevfileObj, containers []corev1.Container, key string, value interface{}) (bool, error) {
+	if len(containers) == 0 {
+		return false, errors.New("the pod has no container")
+	}
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return false, err
+	}
+	var globalAttributes attributes.Attributes
+	// attributes is not supported in versions less than 2.1.0
+	if devfileObj.Data.GetSchemaVersion() > string(data.APISchemaVersion200) {
+		if globalAttributes, err = devfileObj.Data.GetAttributes(); err != nil {
+			return false, err
+		}
+	}
+	running := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		running[container.Name] = true
+	}
+	var podComponents []v1.Component
+	for _, comp := range containerComponents {
+		if running[comp.Name] {
+			podComponents = append(podComponents, comp)
+		}
+	}
+	return decodePodAttribute(globalAttributes, podComponents, key, value)
+}
+
+// decodePodAttribute decodes into value the attribute of the components running in a pod,
+// or else the given global attribute. It returns false if the attribute is not set.
+func decodePodAttribute(globalAttributes attributes.Attributes, podComponents []v1.Component, key string, value interface{}) (bool, error) {
+	var podAttributes attributes.Attributes
+	var attributeComponent string
+	for _, comp := range podComponents {
+		if !comp.Attributes.Exists(key) {
+			continue
+		}
+		if attributeComponent != "" {
+			return false, fmt.Errorf("components %s and %s both set the %s attribute, which can only be set by one component of a pod",
+				attributeComponent, comp.Name, key)
+		}
+		podAttributes, attributeComponent = comp.Attributes, comp.Name
+	}
+	if attributeComponent == "" {
+		if !globalAttributes.Exists(key) {
+			return false, nil
+		}
+		podAttributes = globalAttributes
+	}
+
+	if err := podAttributes.GetInto(key, value); err != nil {
+		if attributeComponent != "" {
+			return false, fmt.Errorf("invalid %s attribute of component %s: %w", key, attributeComponent, err)
+		}
+		return false, fmt.Errorf("invalid %s attribute: %w", key, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,275 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+// podTemplateWithContainers returns a pod template running the given containers
+func podTemplateWithContainers(containers ...corev1.Container) *corev1.PodTemplateSpec {
+	return &corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}
+}
+
+func TestGetHorizontalPodAutoscaler(t *testing.T) {
+	cpuContainer := func(name string) corev1.Container {
+		return corev1.Container{
+			Name:      name,
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}},
+		}
+	}
+	scaleTargetRef := autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "app"}
+	tests := []struct {
+		name        string
+		devfile     string
+		podTemplate *corev1.PodTemplateSpec
+		wantSpec    *autoscalingv2.HorizontalPodAutoscalerSpec
+		wantErr     string
+	}{
+		{
+			name: "component attribute",
+			devfile: `components:
+  - name: web
+    attributes:
+      autoscaling:
+        minReplicas: 2
+        maxReplicas: 4
+        targetCPUUtilization: 80
+    container:
+      image: web
+`,
+			podTemplate: podTemplateWithContainers(cpuContainer("web")),
+			wantSpec: &autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: scaleTargetRef,
+				MinReplicas:    pointer.Int32(2),
+				MaxReplicas:    4,
+				Metrics: []autoscalingv2.MetricSpec{{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name:   corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{Type: autoscalingv2.UtilizationMetricType, AverageUtilization: pointer.Int32(80)},
+					},
+				}},
+			},
+		},
+		{
+			name: "devfile attribute",
+			devfile: `attributes:
+  autoscaling:
+    maxReplicas: 3
+components:
+  - name: web
+    container:
+      image: web
+`,
+			podTemplate: podTemplateWithContainers(corev1.Container{Name: "web"}),
+			wantSpec: &autoscalingv2.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: scaleTargetRef,
+				MaxReplicas:    3,
+			},
+		},
+		{
+			name: "attribute of a component in another pod",
+			devfile: `components:
+  - name: web
+    container:
+      image: web
+  - name: worker
+    attributes:
+      autoscaling:
+        maxReplicas: 3
+    container:
+      image: worker
+      dedicatedPod: true
+`,
+			podTemplate: podTemplateWithContainers(corev1.Container{Name: "web"}),
+		},
+		{
+			name: "no attribute",
+			devfile: `components:
+  - name: web
+    container:
+      image: web
+`,
+			podTemplate: podTemplateWithContainers(corev1.Container{Name: "web"}),
+		},
+		{
+			name: "utilization without request",
+			devfile: `components:
+  - name: web
+    attributes:
+      autoscaling:
+        maxReplicas: 4
+        targetCPUUtilization: 80
+    container:
+      image: web
+  - name: sidecar
+    container:
+      image: sidecar
+`,
+			podTemplate: podTemplateWithContainers(cpuContainer("web"), corev1.Container{Name: "sidecar"}),
+			wantErr:     "invalid autoscaling attribute: the cpu utilization is targeted, but container sidecar has no cpu request",
+		},
+		{
+			name: "minReplicas greater than maxReplicas",
+			devfile: `attributes:
+  autoscaling:
+    minReplicas: 5
+    maxReplicas: 4
+components:
+  - name: web
+    container:
+      image: web
+`,
+			podTemplate: podTemplateWithContainers(corev1.Container{Name: "web"}),
+			wantErr:     "invalid autoscaling attribute: minReplicas must be between 1 and maxReplicas",
+		},
+		{
+			name: "attribute set by two components of the pod",
+			devfile: `components:
+  - name: web
+    attributes:
+      autoscaling:
+        maxReplicas: 4
+    container:
+      image: web
+  - name: sidecar
+    attributes:
+      autoscaling:
+        maxReplicas: 2
+    container:
+      image: sidecar
+`,
+			podTemplate: podTemplateWithContainers(corev1.Container{Name: "web"}, corev1.Container{Name: "sidecar"}),
+			wantErr:     "components web and sidecar both set the autoscaling attribute, which can only be set by one component of a pod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: autoscaling
+` + tt.devfile)})
+			hpa, err := GetHorizontalPodAutoscaler(devfileObj, HorizontalPodAutoscalerParams{
+				ObjectMeta:      metav1.ObjectMeta{Name: "app"},
+				ScaleTargetRef:  scaleTargetRef,
+				PodTemplateSpec: tt.podTemplate,
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetHorizontalPodAutoscaler() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetHorizontalPodAutoscaler() unexpected error: %v", err)
+			}
+			var gotSpec *autoscalingv2.HorizontalPodAutoscalerSpec
+			if hpa != nil {
+				gotSpec = &hpa.Spec
+			}
+			if diff := cmp.Diff(tt.wantSpec, gotSpec); diff != "" {
+				t.Errorf("GetHorizontalPodAutoscaler() spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetPodDisruptionBudget(t *testing.T) {
+	minAvailable := intstr.FromString("50%")
+	tests := []struct {
+		name     string
+		devfile  string
+		wantSpec *policyv1.PodDisruptionBudgetSpec
+		wantErr  string
+	}{
+		{
+			name: "component attribute",
+			devfile: `components:
+  - name: web
+    attributes:
+      disruption-budget:
+        minAvailable: 50%
+    container:
+      image: web
+`,
+			wantSpec: &policyv1.PodDisruptionBudgetSpec{
+				MinAvailable: &minAvailable,
+				Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "app"}},
+			},
+		},
+		{
+			name: "no attribute",
+			devfile: `components:
+  - name: web
+    container:
+      image: web
+`,
+		},
+		{
+			name: "both minAvailable and maxUnavailable",
+			devfile: `attributes:
+  disruption-budget:
+    minAvailable: 1
+    maxUnavailable: 1
+components:
+  - name: web
+    container:
+      image: web
+`,
+			wantErr: "invalid disruption-budget attribute: exactly one of minAvailable and maxUnavailable must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: disruption
+` + tt.devfile)})
+			pdb, err := GetPodDisruptionBudget(devfileObj, PodDisruptionBudgetParams{
+				ObjectMeta:        metav1.ObjectMeta{Name: "app"},
+				PodSelectorLabels: map[string]string{"app": "app"},
+				PodTemplateSpec:   podTemplateWithContainers(corev1.Container{Name: "web"}),
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetPodDisruptionBudget() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPodDisruptionBudget() unexpected error: %v", err)
+			}
+			var gotSpec *policyv1.PodDisruptionBudgetSpec
+			if pdb != nil {
+				gotSpec = &pdb.Spec
+			}
+			if diff := cmp.Diff(tt.wantSpec, gotSpec); diff != "" {
+				t.Errorf("GetPodDisruptionBudget() spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/devfile/library/v2/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	psaapi "k8s.io/pod-security-admission/api"
+)
+
+// CommandJobParams is a struct that contains the required data to create a Job running a devfile command
+type CommandJobParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// CommandId is the id of the exec, apply or composite command run by the Job
+	CommandId string
+	// PodObjectMeta is the metadata of the pod template of the Job
+	PodObjectMeta metav1.ObjectMeta
+	// PodSecurityAdmissionPolicy is the policy to be respected by the pod of the Job
+	PodSecurityAdmissionPolicy psaapi.Policy
+	// ProjectsVolume is the volume holding the projects, mounted in the containers with mountSources.
+	// Set ProjectsVolume.PVCName to run the command on existing sources, or ProjectsVolume.CloneProjects to clone them.
+	ProjectsVolume ProjectsVolume
+	// VolumeNameToVolumeInfo are the volumes of the volume components, as returned by GetPVCsFromVolumeComponents.
+	// The volumes mounted by the container components running the command are mounted in the pod.
+	VolumeNameToVolumeInfo map[string]VolumeInfo
+	// BackoffLimit is the number of retries before the Job fails. Defaults to DefaultJobBackoffLimit.
+	BackoffLimit *int32
+	// TTLSecondsAfterFinished is the time after which the finished Job is deleted. The Job is kept if nil.
+	TTLSecondsAfterFinished *int32
+	// ImagePullPolicy, ImagePullSecrets and ServiceAccountName configure the pod of the Job as for GetPodTemplateSpec
+	ImagePullPolicy    corev1.PullPolicy
+	ImagePullSecrets   []corev1.LocalObjectReference
+	ServiceAccountName string
+}
+
+// commandStep is a command running in a container of the pod of a command Job
+type commandStep struct {
+	commandId string
+	component string
+	// exec is the exec command run by the step, or nil if the step applies its container component
+	exec *v1.ExecCommand
+}
+
+// GetCommandJob returns a Job running a devfile command once, configured as described by GetJob:
+// - an exec command runs its command line in a container of its component, in its working directory and with its
+// environment variables, instead of the entrypoint of the component
+// - an apply command of a container component runs the container of the component with its own entrypoint
+// - the commands of a sequential composite command run in init containers, in order, except the last one which runs
+// in the container of the pod. The commands of a parallel composite command run in several containers of the pod,
+// so a parallel composite command can only run at the end of the Job.
+//
+// The containers mount the volumes of their components and, for the components with mountSources,
+// the projects volume at their PROJECTS_ROOT, with their PROJECT_SOURCE environment variable. Each container is named
+// <component name>-<command id>-<position of the command>, as the init containers of the preStart events.
+// The pod is placed on the nodes as described by GetPodTemplateSpec, and the pod and container overrides of the components
+// apply to the pod and to their containers.
+func GetCommandJob(devfileObj parser.DevfileObj, jobParams CommandJobParams) (*batchv1.Job, error) {
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	stages, err := getCommandStages(common.GetCommandsMap(commands), jobParams.CommandId)
+	if err != nil {
+		return nil, err
+	}
+
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	componentsMap := make(map[string]v1.Component, len(containerComponents))
+	for _, comp := range containerComponents {
+		componentsMap[comp.Name] = comp
+	}
+	allContainers, err := getAllContainersWithPullPolicy(devfileObj, common.DevfileOptions{}, jobParams.ImagePullPolicy)
+	if err != nil {
+		return nil, err
+	}
+	containersMap := make(map[string]corev1.Container, len(allContainers))
+	for _, container := range allContainers {
+		containersMap[container.Name] = container
+	}
+
+	// the containers are first named after their components, so that the volumes of the components are mounted in them
+	var stepContainers []corev1.Container
+	var steps []commandStep
+	for i, stage := range stages {
+		if len(stage) > 1 && i < len(stages)-1 {
+			return nil, fmt.Errorf("command %s runs parallel commands before other commands, a parallel composite command can only run at the end of a Job", jobParams.CommandId)
+		}
+		for _, step := range stage {
+			container, ok := containersMap[step.component]
+			if !ok {
+				return nil, fmt.Errorf("command %s runs in component %s, which is not a container component", step.commandId, step.component)
+			}
+			container = *container.DeepCopy()
+			container.Ports = nil
+			if step.exec != nil {
+				container.Command = append(append([]string{}, shellCommand...), getExecScript(step.exec))
+				container.Args = nil
+			}
+			stepContainers = append(stepContainers, container)
+			steps = append(steps, step)
+		}
+	}
+
+	stepVolumeInfos := make(map[string]VolumeInfo)
+	for _, step := range steps {
+		for _, volumeMount := range componentsMap[step.component].Container.VolumeMounts {
+			if volumeInfo, ok := jobParams.VolumeNameToVolumeInfo[volumeMount.Name]; ok {
+				stepVolumeInfos[volumeMount.Name] = volumeInfo
+			}
+		}
+	}
+	volumeNames := make([]string, 0, len(stepVolumeInfos))
+	for volumeName := range stepVolumeInfos {
+		volumeNames = append(volumeNames, volumeName)
+	}
+	sort.Strings(volumeNames)
+	var volumes []corev1.Volume
+	for _, volumeName := range volumeNames {
+		vols, err := GetVolumesAndVolumeMounts(devfileObj, VolumeParams{
+			Containers:             stepContainers,
+			VolumeNameToVolumeInfo: map[string]VolumeInfo{volumeName: stepVolumeInfos[volumeName]},
+		}, common.DevfileOptions{})
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, vols...)
+	}
+	for i := range stepContainers {
+		name := util.TruncateString(fmt.Sprintf("%s-%s", steps[i].component, steps[i].commandId), containerNameMaxLen)
+		stepContainers[i].Name = fmt.Sprintf("%s-%d", name, i+1)
+	}
+
+	lastStage := len(stepContainers) - len(stages[len(stages)-1])
+	podTemplateSpecParams := podTemplateSpecParams{
+		ObjectMeta:     jobParams.PodObjectMeta,
+		InitContainers: stepContainers[:lastStage],
+		Containers:     stepContainers[lastStage:],
+		Volumes:        volumes,
+	}
+	if err = addProjectsVolume(devfileObj, jobParams.ProjectsVolume, &podTemplateSpecParams); err != nil {
+		return nil, err
+	}
+	podTemplateSpec, err := getPodTemplateSpec(podTemplateSpecParams)
+	if err != nil {
+		return nil, err
+	}
+	var globalAttributes attributes.Attributes
+	// attributes is not supported in versions less than 2.1.0, so we skip it
+	if devfileObj.Data.GetSchemaVersion() > string(data.APISchemaVersion200) {
+		globalAttributes, _ = devfileObj.Data.GetAttributes()
+	}
+	err = setPodIdentity(globalAttributes, &podTemplateSpec.Spec, jobParams.ImagePullSecrets, jobParams.ServiceAccountName)
+	if err != nil {
+		return nil, err
+	}
+	stepComponents := make(map[string]bool)
+	var components []v1.Component
+	for _, step := range steps {
+		if !stepComponents[step.component] {
+			stepComponents[step.component] = true
+			components = append(components, componentsMap[step.component])
+		}
+	}
+	err = setPodPlacement(globalAttributes, components, devfileObj.Data.GetMetadata().Architectures, podTemplateSpec)
+	if err != nil {
+		return nil, err
+	}
+	podTemplateSpec, _, err = patchForPolicy(podTemplateSpec, jobParams.PodSecurityAdmissionPolicy, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if needsPodOverrides(globalAttributes, components) {
+		patchedPodTemplateSpec, err := applyPodOverrides(globalAttributes, components, podTemplateSpec)
+		if err != nil {
+			return nil, err
+		}
+		patchedPodTemplateSpec.ObjectMeta = podTemplateSpecParams.ObjectMeta
+		podTemplateSpec = patchedPodTemplateSpec
+	}
+
+	// the containers are renamed after the commands, their components are looked up by container name
+	stepOverrides := make(map[string]v1.Component, len(steps))
+	for i, step := range steps {
+		stepOverrides[stepContainers[i].Name] = componentsMap[step.component]
+	}
+	for _, containers := range [][]corev1.Container{podTemplateSpec.Spec.InitContainers, podTemplateSpec.Spec.Containers} {
+		for i := range containers {
+			comp, ok := stepOverrides[containers[i].Name]
+			if !ok || !comp.Attributes.Exists(ContainerOverridesAttribute) {
+				continue
+			}
+			patched, err := containerOverridesHandler(comp, &containers[i])
+			if err != nil {
+				return nil, err
+			}
+			containers[i] = *patched
+		}
+	}
+
+	return getJob(devfileObj, JobParams{
+		TypeMeta:                jobParams.TypeMeta,
+		ObjectMeta:              jobParams.ObjectMeta,
+		PodTemplateSpec:         podTemplateSpec,
+		BackoffLimit:            jobParams.BackoffLimit,
+		TTLSecondsAfterFinished: jobParams.TTLSecondsAfterFinished,
+	}, func(componentName string) bool {
+		return stepComponents[componentName]
+	})
+}
+
+// getCommandStages returns the steps running the command, grouped in stages running sequentially.
+// The steps of a stage run in parallel.
+func getCommandStages(commandsMap map[string]v1.Command, commandId string) ([][]commandStep, error) {
+	command, ok := commandsMap[commandId]
+	if !ok {
+		return nil, fmt.Errorf("command %s is not defined", commandId)
+	}
+	switch {
+	case command.Exec != nil:
+		return [][]commandStep{{{commandId: commandId, component: command.Exec.Component, exec: command.Exec}}}, nil
+	case command.Apply != nil:
+		return [][]commandStep{{{commandId: commandId, component: command.Apply.Component}}}, nil
+	case command.Composite != nil:
+		if len(command.Composite.Commands) == 0 {
+			return nil, fmt.Errorf("composite command %s has no command", commandId)
+		}
+		parallel := command.Composite.Parallel != nil && *command.Composite.Parallel
+		var stages [][]commandStep
+		for _, subCommandId := range command.Composite.Commands {
+			subStages, err := getCommandStages(commandsMap, subCommandId)
+			if err != nil {
+				return nil, err
+			}
+			if !parallel {
+				stages = append(stages, subStages...)
+				continue
+			}
+			if len(subStages) > 1 {
+				return nil, fmt.Errorf("parallel composite command %s runs the sequential command %s, which cannot run in a single container", commandId, subCommandId)
+			}
+			if len(stages) == 0 {
+				stages = [][]commandStep{nil}
+			}
+			stages[0] = append(stages[0], subStages[0]...)
+		}
+		return stages, nil
+	default:
+		return nil, fmt.Errorf("command %s cannot run in a Job, only exec, apply and composite commands are supported", commandId)
+	}
+}
@@ -0,0 +1,231 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const commandJobsDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: ci
+components:
+  - name: tools
+    attributes:
+      container-overrides:
+        securityContext:
+          runAsUser: 1001
+    container:
+      image: tools
+      command: [tail, -f, /dev/null]
+      volumeMounts:
+        - name: cache
+          path: /cache
+      endpoints:
+        - name: http
+          targetPort: 8080
+  - name: db
+    container:
+      image: db
+      mountSources: false
+  - name: cache
+    volume: {}
+  - name: app-image
+    image:
+      imageName: app
+      dockerfile:
+        uri: Dockerfile
+commands:
+  - id: build
+    exec:
+      component: tools
+      commandLine: make build
+      workingDir: ${PROJECT_SOURCE}
+      group:
+        kind: build
+  - id: unit
+    exec:
+      component: tools
+      commandLine: make test
+      group:
+        kind: test
+  - id: integration
+    exec:
+      component: db
+      commandLine: run-integration
+  - id: start-db
+    apply:
+      component: db
+  - id: tests
+    composite:
+      commands: [unit, integration]
+      parallel: true
+  - id: ci
+    composite:
+      commands: [build, tests]
+  - id: parallel-first
+    composite:
+      commands: [tests, build]
+  - id: parallel-sequence
+    composite:
+      commands: [ci, unit]
+      parallel: true
+  - id: build-image
+    apply:
+      component: app-image
+`
+
+// jobContainer is the part of a container of a command Job checked by the tests
+type jobContainer struct {
+	Name         string
+	Command      []string
+	VolumeMounts []string
+}
+
+func TestGetCommandJob(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(commandJobsDevfile)})
+	tests := []struct {
+		name               string
+		commandId          string
+		wantInitContainers []jobContainer
+		wantContainers     []jobContainer
+		wantVolumes        []string
+		wantErr            string
+	}{
+		{
+			name:      "exec command",
+			commandId: "build",
+			wantContainers: []jobContainer{{
+				Name:         "tools-build-1",
+				Command:      []string{"/bin/sh", "-c", `cd "${PROJECT_SOURCE}" && make build`},
+				VolumeMounts: []string{"cache:/cache", "projects:/projects"},
+			}},
+			wantVolumes: []string{"cache", "projects"},
+		},
+		{
+			name:      "apply command",
+			commandId: "start-db",
+			wantContainers: []jobContainer{{
+				Name: "db-start-db-1",
+			}},
+		},
+		{
+			name:      "sequential composite command ending with a parallel composite command",
+			commandId: "ci",
+			wantInitContainers: []jobContainer{{
+				Name:         "tools-build-1",
+				Command:      []string{"/bin/sh", "-c", `cd "${PROJECT_SOURCE}" && make build`},
+				VolumeMounts: []string{"cache:/cache", "projects:/projects"},
+			}},
+			wantContainers: []jobContainer{
+				{
+					Name:         "tools-unit-2",
+					Command:      []string{"/bin/sh", "-c", "make test"},
+					VolumeMounts: []string{"cache:/cache", "projects:/projects"},
+				},
+				{
+					Name:    "db-integration-3",
+					Command: []string{"/bin/sh", "-c", "run-integration"},
+				},
+			},
+			wantVolumes: []string{"cache", "projects"},
+		},
+		{
+			name:      "parallel composite command before other commands",
+			commandId: "parallel-first",
+			wantErr:   "command parallel-first runs parallel commands before other commands, a parallel composite command can only run at the end of a Job",
+		},
+		{
+			name:      "sequential command in a parallel composite command",
+			commandId: "parallel-sequence",
+			wantErr:   "parallel composite command parallel-sequence runs the sequential command ci, which cannot run in a single container",
+		},
+		{
+			name:      "apply command of an image component",
+			commandId: "build-image",
+			wantErr:   "command build-image runs in component app-image, which is not a container component",
+		},
+		{
+			name:      "undefined command",
+			commandId: "deploy",
+			wantErr:   "command deploy is not defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job, err := GetCommandJob(devfileObj, CommandJobParams{
+				ObjectMeta: metav1.ObjectMeta{Name: "ci"},
+				CommandId:  tt.commandId,
+				VolumeNameToVolumeInfo: map[string]VolumeInfo{
+					"cache": {PVCName: "ci-cache", VolumeName: "cache"},
+				},
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetCommandJob() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetCommandJob() unexpected error: %v", err)
+			}
+
+			spec := job.Spec.Template.Spec
+			if diff := cmp.Diff(tt.wantInitContainers, toJobContainers(spec.InitContainers)); diff != "" {
+				t.Errorf("GetCommandJob() init containers mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantContainers, toJobContainers(spec.Containers)); diff != "" {
+				t.Errorf("GetCommandJob() containers mismatch (-want +got):\n%s", diff)
+			}
+			var volumes []string
+			for _, volume := range spec.Volumes {
+				volumes = append(volumes, volume.Name)
+			}
+			if diff := cmp.Diff(tt.wantVolumes, volumes); diff != "" {
+				t.Errorf("GetCommandJob() volumes mismatch (-want +got):\n%s", diff)
+			}
+			if spec.RestartPolicy != corev1.RestartPolicyNever {
+				t.Errorf("GetCommandJob() restart policy = %s, want %s", spec.RestartPolicy, corev1.RestartPolicyNever)
+			}
+			for _, container := range append(spec.InitContainers, spec.Containers...) {
+				if len(container.Ports) > 0 {
+					t.Errorf("GetCommandJob() container %s exposes ports", container.Name)
+				}
+				overridden := container.SecurityContext != nil && container.SecurityContext.RunAsUser != nil
+				if wantOverridden := container.Image == "tools"; overridden != wantOverridden {
+					t.Errorf("GetCommandJob() container %s has container overrides: %v, want %v", container.Name, overridden, wantOverridden)
+				}
+			}
+		})
+	}
+}
+
+func toJobContainers(containers []corev1.Container) []jobContainer {
+	var result []jobContainer
+	for _, container := range containers {
+		c := jobContainer{Name: container.Name, Command: container.Command}
+		for _, volumeMount := range container.VolumeMounts {
+			c.VolumeMounts = append(c.VolumeMounts, volumeMount.Name+":"+volumeMount.MountPath)
+		}
+		result = append(result, c)
+	}
+	return result
+}
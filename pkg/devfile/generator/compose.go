@@ -0,0 +1,373 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ComposeVolumeType is the type of a Compose service volume backed by a named volume
+	ComposeVolumeType = "volume"
+	// ComposeBindType is the type of a Compose service volume backed by a host path
+	ComposeBindType = "bind"
+	// ComposeTmpfsType is the type of a Compose service volume backed by a tmpfs
+	ComposeTmpfsType = "tmpfs"
+
+	// DefaultComposeProjectSource is the host path of the project sources bind mounted in the Compose services
+	DefaultComposeProjectSource = "."
+)
+
+// ComposeOptions is a struct that contains the options to generate a Compose file
+type ComposeOptions struct {
+	// Name is the name of the Compose project. Defaults to the devfile metadata name.
+	Name string
+	// ProjectSource is the host path of the project sources, bind mounted in the services of the container
+	// components with mountSources. Defaults to DefaultComposeProjectSource.
+	ProjectSource string
+	// Options are the options used to filter the devfile components
+	Options common.DevfileOptions
+}
+
+// Compose is a Compose file, as defined by the Compose specification. Only the fields generated from a devfile are defined.
+type Compose struct {
+	Name     string                    `json:"name,omitempty"`
+	Services map[string]ComposeService `json:"services"`
+	Volumes  map[string]ComposeVolume  `json:"volumes,omitempty"`
+}
+
+// ComposeService is a service of a Compose file
+type ComposeService struct {
+	Image       string                 `json:"image,omitempty"`
+	Entrypoint  []string               `json:"entrypoint,omitempty"`
+	Command     []string               `json:"command,omitempty"`
+	Environment map[string]string      `json:"environment,omitempty"`
+	Ports       []ComposePort          `json:"ports,omitempty"`
+	Expose      []string               `json:"expose,omitempty"`
+	Volumes     []ComposeServiceVolume `json:"volumes,omitempty"`
+	NetworkMode string                 `json:"network_mode,omitempty"`
+	Deploy      *ComposeDeploy         `json:"deploy,omitempty"`
+}
+
+// ComposePort is a port published by a Compose service
+type ComposePort struct {
+	Name      string `json:"name,omitempty"`
+	Target    int    `json:"target"`
+	Published string `json:"published,omitempty"`
+	Protocol  string `json:"protocol,omitempty"`
+}
+
+// ComposeServiceVolume is a volume mounted in a Compose service
+type ComposeServiceVolume struct {
+	Type   string        `json:"type"`
+	Source string        `json:"source,omitempty"`
+	Target string        `json:"target"`
+	Tmpfs  *ComposeTmpfs `json:"tmpfs,omitempty"`
+}
+
+// ComposeTmpfs are the options of a tmpfs service volume
+type ComposeTmpfs struct {
+	// Size is the size of the tmpfs, in bytes
+	Size int64 `json:"size,omitempty"`
+}
+
+// ComposeVolume is a named volume of a Compose file
+type ComposeVolume struct{}
+
+// ComposeDeploy is the deployment configuration of a Compose service
+type ComposeDeploy struct {
+	Resources ComposeResources `json:"resources"`
+}
+
+// ComposeResources are the resource constraints of a Compose service
+type ComposeResources struct {
+	Limits       *ComposeResource `json:"limits,omitempty"`
+	Reservations *ComposeResource `json:"reservations,omitempty"`
+}
+
+// ComposeResource is a resource constraint of a Compose service
+type ComposeResource struct {
+	// Cpus is the number of CPUs, as a decimal number
+	Cpus string `json:"cpus,omitempty"`
+	// Memory is the amount of memory, in bytes
+	Memory string `json:"memory,omitempty"`
+}
+
+// ToYAML serializes the Compose file into YAML
+func (c *Compose) ToYAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+// GenerateCompose generates a Compose file from the devfile, to run it locally without a cluster.
+// It returns the Compose file, and warnings for the devfile constructs which cannot be represented in Compose.
+//
+// Each container component becomes a service named after the component:
+// - the container command and args become the service entrypoint and command
+// - the resource limits and requests become the service resource limits and reservations
+// - the volume mounts of non-ephemeral volume components become named volumes, and the ones of ephemeral volume components become tmpfs
+// - if mountSources is set, the project sources are bind mounted at the sourceMapping of the container
+//
+// As the container components without dedicatedPod share the network of a pod, their services share the network of
+// the service of the first of them, which publishes all their endpoints. Public endpoints are published on the host
+// with the same port number, internal endpoints are only exposed to the other services, and endpoints with a "none"
+// exposure are not exposed.
+func GenerateCompose(devfileObj parser.DevfileObj, opts ComposeOptions) (*Compose, []string, error) {
+	name := opts.Name
+	if name == "" {
+		name = devfileObj.GetMetadataName()
+	}
+	projectSource := opts.ProjectSource
+	if projectSource == "" {
+		projectSource = DefaultComposeProjectSource
+	}
+
+	var warnings []string
+	warnf := func(format string, a ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, a...))
+	}
+
+	compose := &Compose{
+		Name:     name,
+		Services: map[string]ComposeService{},
+		Volumes:  map[string]ComposeVolume{},
+	}
+
+	components, err := devfileObj.Data.GetComponents(opts.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+	volumeComponents := map[string]*v1.VolumeComponent{}
+	var containerComponents []v1.Component
+	for _, comp := range components {
+		switch {
+		case comp.Container != nil:
+			containerComponents = append(containerComponents, comp)
+		case comp.Volume != nil:
+			volumeComponents[comp.Name] = comp.Volume
+		default:
+			warnf("component %s: only container and volume components are supported, the component is ignored", comp.Name)
+		}
+	}
+
+	// getAllContainers provides the environment of the containers, including PROJECTS_ROOT and PROJECT_SOURCE
+	containers, err := getAllContainers(devfileObj, opts.Options)
+	if err != nil {
+		return nil, nil, err
+	}
+	containersByName := map[string]corev1.Container{}
+	for _, container := range containers {
+		containersByName[container.Name] = container
+	}
+
+	// podService is the service sharing its network with the containers without dedicatedPod
+	podService := ""
+	volumeUsers := map[string][]string{}
+	publishedPorts := map[string]string{}
+	for _, comp := range containerComponents {
+		container := comp.Container
+		service := ComposeService{
+			Image:      container.Image,
+			Entrypoint: container.Command,
+			Command:    container.Args,
+		}
+
+		for _, env := range containersByName[comp.Name].Env {
+			if service.Environment == nil {
+				service.Environment = map[string]string{}
+			}
+			service.Environment[env.Name] = env.Value
+		}
+
+		resources := containersByName[comp.Name].Resources
+		if len(resources.Limits) > 0 || len(resources.Requests) > 0 {
+			service.Deploy = &ComposeDeploy{
+				Resources: ComposeResources{
+					Limits:       toComposeResource(resources.Limits),
+					Reservations: toComposeResource(resources.Requests),
+				},
+			}
+		}
+
+		if container.MountSources == nil || *container.MountSources {
+			sourceMapping := container.SourceMapping
+			if sourceMapping == "" {
+				sourceMapping = DevfileSourceVolumeMount
+			}
+			service.Volumes = append(service.Volumes, ComposeServiceVolume{
+				Type:   ComposeBindType,
+				Source: projectSource,
+				Target: sourceMapping,
+			})
+		}
+
+		for _, volumeMount := range container.VolumeMounts {
+			volume, ok := volumeComponents[volumeMount.Name]
+			if !ok {
+				return nil, nil, fmt.Errorf("component %s mounts the volume %s, which is not a volume component", comp.Name, volumeMount.Name)
+			}
+			volumeUsers[volumeMount.Name] = append(volumeUsers[volumeMount.Name], comp.Name)
+			if volume.Ephemeral != nil && *volume.Ephemeral {
+				serviceVolume := ComposeServiceVolume{
+					Type:   ComposeTmpfsType,
+					Target: GetVolumeMountPath(volumeMount),
+				}
+				if volume.Size != "" {
+					size, err := resource.ParseQuantity(volume.Size)
+					if err != nil {
+						return nil, nil, fmt.Errorf("error parsing size of volume %s: %w", volumeMount.Name, err)
+					}
+					serviceVolume.Tmpfs = &ComposeTmpfs{Size: size.Value()}
+				}
+				service.Volumes = append(service.Volumes, serviceVolume)
+				continue
+			}
+			compose.Volumes[volumeMount.Name] = ComposeVolume{}
+			service.Volumes = append(service.Volumes, ComposeServiceVolume{
+				Type:   ComposeVolumeType,
+				Source: volumeMount.Name,
+				Target: GetVolumeMountPath(volumeMount),
+			})
+		}
+
+		// the endpoints of the containers sharing a pod are published by the pod service
+		portsService := comp.Name
+		if container.DedicatedPod == nil || !*container.DedicatedPod {
+			if podService == "" {
+				podService = comp.Name
+			} else {
+				service.NetworkMode = "service:" + podService
+				portsService = podService
+			}
+		}
+		compose.Services[comp.Name] = service
+
+		ports, expose := toComposePorts(comp.Name, container.Endpoints, warnf)
+		for _, port := range ports {
+			key := port.Published + "/" + port.Protocol
+			if other, ok := publishedPorts[key]; ok {
+				warnf("component %s: port %s is already published by component %s, the endpoint %s is not published", comp.Name, key, other, port.Name)
+				continue
+			}
+			publishedPorts[key] = comp.Name
+			s := compose.Services[portsService]
+			s.Ports = append(s.Ports, port)
+			compose.Services[portsService] = s
+		}
+		if len(expose) > 0 {
+			s := compose.Services[portsService]
+			s.Expose = append(s.Expose, expose...)
+			compose.Services[portsService] = s
+		}
+
+		if comp.Attributes.Exists(ContainerOverridesAttribute) {
+			warnf("component %s: the %s attribute is not supported", comp.Name, ContainerOverridesAttribute)
+		}
+		if comp.Attributes.Exists(PodOverridesAttribute) {
+			warnf("component %s: the %s attribute is not supported", comp.Name, PodOverridesAttribute)
+		}
+		if container.Annotation != nil && (len(container.Annotation.Deployment) > 0 || len(container.Annotation.Service) > 0) {
+			warnf("component %s: annotations are not supported", comp.Name)
+		}
+	}
+
+	volumeNames := make([]string, 0, len(volumeComponents))
+	for volumeName := range volumeComponents {
+		volumeNames = append(volumeNames, volumeName)
+	}
+	sort.Strings(volumeNames)
+	for _, volumeName := range volumeNames {
+		volume := volumeComponents[volumeName]
+		users := volumeUsers[volumeName]
+		ephemeral := volume.Ephemeral != nil && *volume.Ephemeral
+		switch {
+		case len(users) == 0:
+			warnf("volume %s: the volume is not mounted by any container component, it is ignored", volumeName)
+		case ephemeral && len(users) > 1:
+			warnf("volume %s: ephemeral volumes are mounted as a tmpfs, which is not shared between the components %v", volumeName, users)
+		case !ephemeral && volume.Size != "":
+			warnf("volume %s: the size of named volumes is not supported", volumeName)
+		}
+	}
+
+	// the only time GetAttributes will return an error is if the schema version is 2.0.0, without top-level attributes
+	if globalAttributes, err := devfileObj.Data.GetAttributes(); err == nil && globalAttributes.Exists(PodOverridesAttribute) {
+		warnf("the %s attribute is not supported", PodOverridesAttribute)
+	}
+	if events := devfileObj.Data.GetEvents(); len(events.PreStart) > 0 || len(events.PostStart) > 0 || len(events.PreStop) > 0 || len(events.PostStop) > 0 {
+		warnf("events are not supported")
+	}
+
+	if len(compose.Services) == 0 {
+		return nil, warnings, errors.New("the devfile has no container component to generate a Compose service from")
+	}
+	return compose, warnings, nil
+}
+
+// toComposePorts converts the endpoints of a container component into the ports published on the host, for public
+// endpoints, and the ports exposed to the other services, for internal endpoints
+func toComposePorts(component string, endpoints []v1.Endpoint, warnf func(format string, a ...interface{})) ([]ComposePort, []string) {
+	var ports []ComposePort
+	var expose []string
+	for _, endpoint := range endpoints {
+		protocol := "tcp"
+		if endpoint.Protocol == v1.UDPEndpointProtocol {
+			protocol = "udp"
+		}
+		port := strconv.Itoa(endpoint.TargetPort)
+		switch endpoint.Exposure {
+		case v1.NoneEndpointExposure:
+			continue
+		case v1.InternalEndpointExposure:
+			expose = append(expose, port+"/"+protocol)
+		default:
+			ports = append(ports, ComposePort{
+				Name:      endpoint.Name,
+				Target:    endpoint.TargetPort,
+				Published: port,
+				Protocol:  protocol,
+			})
+		}
+		if endpoint.Secure != nil && *endpoint.Secure {
+			warnf("component %s: endpoint %s is secure, which is not supported, the port is exposed without TLS", component, endpoint.Name)
+		}
+	}
+	return ports, expose
+}
+
+// toComposeResource converts Kubernetes resource quantities into a Compose resource constraint
+func toComposeResource(list corev1.ResourceList) *ComposeResource {
+	if len(list) == 0 {
+		return nil
+	}
+	r := &ComposeResource{}
+	if cpu, ok := list[corev1.ResourceCPU]; ok {
+		r.Cpus = strconv.FormatFloat(float64(cpu.MilliValue())/1000, 'f', -1, 64)
+	}
+	if memory, ok := list[corev1.ResourceMemory]; ok {
+		r.Memory = strconv.FormatInt(memory.Value(), 10)
+	}
+	return r
+}
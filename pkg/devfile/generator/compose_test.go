@@ -0,0 +1,48 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerateCompose(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", "compose/devfile.yaml")})
+
+	compose, warnings, err := GenerateCompose(devfileObj, ComposeOptions{ProjectSource: "./src"})
+	if err != nil {
+		t.Fatalf("GenerateCompose() unexpected error: %v", err)
+	}
+	got, err := compose.ToYAML()
+	if err != nil {
+		t.Fatalf("ToYAML() unexpected error: %v", err)
+	}
+	assertGolden(t, "compose/compose.yaml", got)
+
+	wantWarnings := []string{
+		"component config: only container and volume components are supported, the component is ignored",
+		"component runtime: endpoint https-admin is secure, which is not supported, the port is exposed without TLS",
+		"volume cache: the size of named volumes is not supported",
+		"events are not supported",
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Errorf("GenerateCompose() warnings mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,132 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DevWorkspaceKind is the kind of the DevWorkspace custom resource
+	DevWorkspaceKind = "DevWorkspace"
+	// DevWorkspaceTemplateKind is the kind of the DevWorkspaceTemplate custom resource
+	DevWorkspaceTemplateKind = "DevWorkspaceTemplate"
+)
+
+// DevWorkspaceParentMode defines how the parent of a devfile is emitted in a DevWorkspace custom resource
+type DevWorkspaceParentMode string
+
+const (
+	// FlattenedParent emits the content of the parent merged into the custom resource, without parent.
+	// The parent of a devfile parsed with FlattenedDevfile set to false is resolved and merged, with its plugins,
+	// as the parser does when flattening the devfile.
+	FlattenedParent DevWorkspaceParentMode = "Flattened"
+	// ReferencedParent emits the parent as a reference to the DevWorkspaceTemplate custom resource of the parent,
+	// keeping the parent overrides. The devfile must be parsed with FlattenedDevfile set to false.
+	ReferencedParent DevWorkspaceParentMode = "Referenced"
+)
+
+// DevWorkspaceParams is a struct that contains the required data to create a DevWorkspace or a DevWorkspaceTemplate object
+type DevWorkspaceParams struct {
+	// ObjectMeta is the metadata of the custom resource. The name defaults to the devfile metadata name.
+	ObjectMeta metav1.ObjectMeta
+	// Started is the started flag of a DevWorkspace. It is ignored for a DevWorkspaceTemplate.
+	Started bool
+	// ParentMode defines how the parent of the devfile is emitted. Defaults to FlattenedParent.
+	ParentMode DevWorkspaceParentMode
+	// ParentReference is the DevWorkspaceTemplate the parent refers to with ReferencedParent. It can only be omitted
+	// if the parent of the devfile is already a Kubernetes reference.
+	ParentReference *v1.KubernetesCustomResourceImportReference
+	// ParserArgs are the parser args resolving the parent with FlattenedParent, such as the registry URLs or the
+	// Kubernetes client. Their devfile source is ignored.
+	ParserArgs parser.ParserArgs
+}
+
+// GetDevWorkspace gets a DevWorkspace object holding the content of the devfile, with its projects, events,
+// attributes and variables, so that the devfile can be run by a DevWorkspace operator
+func GetDevWorkspace(devfileObj parser.DevfileObj, devWorkspaceParams DevWorkspaceParams) (*v1.DevWorkspace, error) {
+	objectMeta, templateSpec, err := getDevWorkspaceTemplateSpec(devfileObj, devWorkspaceParams)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.DevWorkspace{
+		TypeMeta:   GetTypeMeta(DevWorkspaceKind, v1.SchemeGroupVersion.String()),
+		ObjectMeta: objectMeta,
+		Spec: v1.DevWorkspaceSpec{
+			Started:  devWorkspaceParams.Started,
+			Template: *templateSpec,
+		},
+	}, nil
+}
+
+// GetDevWorkspaceTemplate gets a DevWorkspaceTemplate object holding the content of the devfile, with its projects,
+// events, attributes and variables, so that the devfile can be referenced as a parent or a plugin
+func GetDevWorkspaceTemplate(devfileObj parser.DevfileObj, devWorkspaceParams DevWorkspaceParams) (*v1.DevWorkspaceTemplate, error) {
+	objectMeta, templateSpec, err := getDevWorkspaceTemplateSpec(devfileObj, devWorkspaceParams)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.DevWorkspaceTemplate{
+		TypeMeta:   GetTypeMeta(DevWorkspaceTemplateKind, v1.SchemeGroupVersion.String()),
+		ObjectMeta: objectMeta,
+		Spec:       *templateSpec,
+	}, nil
+}
+
+// getDevWorkspaceTemplateSpec returns the object meta of the custom resource, and the template spec of the devfile
+// with its parent emitted as defined by the parent mode
+func getDevWorkspaceTemplateSpec(devfileObj parser.DevfileObj, devWorkspaceParams DevWorkspaceParams) (metav1.ObjectMeta, *v1.DevWorkspaceTemplateSpec, error) {
+	objectMeta := *devWorkspaceParams.ObjectMeta.DeepCopy()
+	if objectMeta.Name == "" {
+		objectMeta.Name = devfileObj.GetMetadataName()
+	}
+	if objectMeta.Name == "" {
+		return metav1.ObjectMeta{}, nil, errors.New("a name is required, either in the object meta or in the devfile metadata")
+	}
+
+	templateSpec := devfileObj.Data.GetDevfileWorkspaceSpec().DeepCopy()
+	if templateSpec.Parent == nil {
+		return objectMeta, templateSpec, nil
+	}
+
+	switch devWorkspaceParams.ParentMode {
+	case FlattenedParent, "":
+		flattenedObj, err := parser.FlattenDevfile(devfileObj, devWorkspaceParams.ParserArgs)
+		if err != nil {
+			return metav1.ObjectMeta{}, nil, fmt.Errorf("failed to flatten the parent of the devfile: %w", err)
+		}
+		templateSpec = flattenedObj.Data.GetDevfileWorkspaceSpec().DeepCopy()
+	case ReferencedParent:
+		switch {
+		case devWorkspaceParams.ParentReference != nil:
+			templateSpec.Parent.ImportReference = v1.ImportReference{
+				ImportReferenceUnion: v1.ImportReferenceUnion{
+					Kubernetes: devWorkspaceParams.ParentReference.DeepCopy(),
+				},
+			}
+		case templateSpec.Parent.Kubernetes == nil:
+			return metav1.ObjectMeta{}, nil, errors.New("a parent reference is required to reference a parent which is not a Kubernetes reference")
+		}
+	default:
+		return metav1.ObjectMeta{}, nil, fmt.Errorf("unknown parent mode %q", devWorkspaceParams.ParentMode)
+	}
+	return objectMeta, templateSpec, nil
+}
@@ -0,0 +1,136 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetDevWorkspace(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", "devworkspace/devfile.yaml")})
+	devWorkspace, err := GetDevWorkspace(devfileObj, DevWorkspaceParams{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-ns",
+			Labels:    map[string]string{"team": "web"},
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("GetDevWorkspace() unexpected error: %v", err)
+	}
+	got, err := yaml.Marshal(devWorkspace)
+	if err != nil {
+		t.Fatalf("failed to marshal DevWorkspace: %v", err)
+	}
+	assertGolden(t, "devworkspace/devworkspace.yaml", got)
+}
+
+func TestGetDevWorkspaceTemplate(t *testing.T) {
+	tests := []struct {
+		name       string
+		devfile    string
+		raw        bool
+		params     DevWorkspaceParams
+		wantGolden string
+		wantErr    string
+		// wantErrPrefix is the beginning of an error holding the path of the devfile, which depends on the machine
+		wantErrPrefix string
+	}{
+		{
+			name:       "flattened devfile",
+			devfile:    "devworkspace/devfile.yaml",
+			params:     DevWorkspaceParams{ObjectMeta: metav1.ObjectMeta{Name: "nodejs"}},
+			wantGolden: "devworkspace/devworkspacetemplate.yaml",
+		},
+		{
+			name:    "referenced parent",
+			devfile: "devworkspace/devfile-parent.yaml",
+			raw:     true,
+			params: DevWorkspaceParams{
+				ObjectMeta:      metav1.ObjectMeta{Namespace: "my-ns"},
+				ParentMode:      ReferencedParent,
+				ParentReference: &v1.KubernetesCustomResourceImportReference{Name: "nodejs", Namespace: "devworkspace-templates"},
+			},
+			wantGolden: "devworkspace/devworkspacetemplate-parent.yaml",
+		},
+		{
+			name:       "flattened parent",
+			devfile:    "devworkspace/devfile-local-parent.yaml",
+			raw:        true,
+			params:     DevWorkspaceParams{ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns"}},
+			wantGolden: "devworkspace/devworkspacetemplate-flattened.yaml",
+		},
+		{
+			name:          "parent which cannot be resolved",
+			devfile:       "devworkspace/devfile-missing-parent.yaml",
+			raw:           true,
+			wantErrPrefix: "failed to flatten the parent of the devfile: error parsing devfile because of non-compliant data due to the provided path is not a valid filepath ",
+		},
+		{
+			name:    "referenced parent without reference",
+			devfile: "devworkspace/devfile-parent.yaml",
+			raw:     true,
+			params:  DevWorkspaceParams{ParentMode: ReferencedParent},
+			wantErr: "a parent reference is required to reference a parent which is not a Kubernetes reference",
+		},
+		{
+			name:    "unknown parent mode",
+			devfile: "devworkspace/devfile-parent.yaml",
+			raw:     true,
+			params:  DevWorkspaceParams{ParentMode: "Inlined"},
+			wantErr: `unknown parent mode "Inlined"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var devfileObj parser.DevfileObj
+			if tt.raw {
+				devfileObj = parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", tt.devfile), FlattenedDevfile: pointer.Bool(false)})
+			} else {
+				devfileObj = parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", tt.devfile)})
+			}
+			template, err := GetDevWorkspaceTemplate(devfileObj, tt.params)
+			if tt.wantErrPrefix != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErrPrefix) {
+					t.Errorf("GetDevWorkspaceTemplate() error = %v, want %s...", err, tt.wantErrPrefix)
+				}
+				return
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetDevWorkspaceTemplate() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetDevWorkspaceTemplate() unexpected error: %v", err)
+			}
+			got, err := yaml.Marshal(template)
+			if err != nil {
+				t.Fatalf("failed to marshal DevWorkspaceTemplate: %v", err)
+			}
+			assertGolden(t, tt.wantGolden, got)
+		})
+	}
+}
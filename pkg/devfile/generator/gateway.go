@@ -0,0 +1,358 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+const (
+	httpRouteKind              = "HTTPRoute"
+	grpcRouteKind              = "GRPCRoute"
+	gatewayV1beta1APIVersion   = "gateway.networking.k8s.io/v1beta1"
+	gatewayV1alpha2APIVersion  = "gateway.networking.k8s.io/v1alpha2"
+	httpsRedirectRouteNameTail = "redirect"
+)
+
+// http2AppProtocols are the application protocols of the endpoints routed by GRPCRoutes, set by AppProtocolAttribute
+var http2AppProtocols = map[string]bool{
+	"kubernetes.io/h2c": true,
+	"h2c":               true,
+	"grpc":              true,
+}
+
+// GatewayParent is the Gateway the generated routes are attached to
+type GatewayParent struct {
+	// ParentRefs are the references to the parent Gateway
+	ParentRefs []gatewayv1beta1.ParentReference
+	// HTTPListenerName is the name of the HTTP listener of the Gateway. If set, the routes of the hosts without secure
+	// endpoints are attached to this listener, and the hosts of secure endpoints are redirected to HTTPS on this listener.
+	HTTPListenerName string
+	// HTTPSListenerName is the name of the HTTPS listener of the Gateway, terminating TLS. If set, the routes of the hosts
+	// of secure endpoints are attached to this listener.
+	HTTPSListenerName string
+}
+
+// GatewayRoutesParams is a struct that contains the required data to create the Gateway API routes of the public endpoints
+type GatewayRoutesParams struct {
+	// ObjectMeta is the metadata of the routes. Each route is named <ObjectMeta.Name>-<endpoint name>, after the first
+	// endpoint it routes, by name.
+	ObjectMeta metav1.ObjectMeta
+	// EndpointServices is the name of the Service exposing each endpoint, indexed by endpoint name, as returned by
+	// GetEndpointServices. Endpoints without a Service are not routed.
+	EndpointServices map[string]string
+	// Domain is the domain used to compute the hostname of the endpoints without IngressHostAttribute,
+	// as <ObjectMeta.Name>-<endpoint name>.<Domain>. If empty, these endpoints are routed for any hostname.
+	Domain string
+	// Parent is the Gateway the routes are attached to
+	Parent GatewayParent
+}
+
+// GatewayRoutes are the Gateway API routes of the public endpoints
+type GatewayRoutes struct {
+	HTTPRoutes []*gatewayv1beta1.HTTPRoute
+	GRPCRoutes []*gatewayv1alpha2.GRPCRoute
+}
+
+// GetGatewayRoutes returns the Gateway API routes of the public endpoints of the container components, attached to the
+// parent Gateway. Endpoints are grouped by host as by GetIngresses: the endpoints with an http2 application protocol set
+// by AppProtocolAttribute are routed by a GRPCRoute for each host, and the other endpoints by an HTTPRoute for each host,
+// matching their paths. An Exact path type matches the exact path, other path types match the path prefix.
+// As a Gateway rejects a GRPCRoute sharing a hostname with an HTTPRoute, a host cannot mix http2 and other endpoints.
+//
+// TLS is terminated by the parent Gateway: the routes of the hosts of secure endpoints are attached to its HTTPS
+// listener, and a single HTTPRoute named <route name>-redirect redirects the host to HTTPS on its HTTP listener.
+func GetGatewayRoutes(devfileObj parser.DevfileObj, routesParams GatewayRoutesParams, options common.DevfileOptions) (*GatewayRoutes, error) {
+	endpoints, err := getPublicEndpoints(devfileObj, options, routesParams.EndpointServices)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := groupEndpointsByHost(routesParams.ObjectMeta.Name, routesParams.Domain, endpoints)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := &GatewayRoutes{}
+	for _, h := range hosts {
+		httpHost, http2Host := hostEndpoints{name: h.name, host: h.host}, hostEndpoints{name: h.name, host: h.host}
+		for _, endpoint := range h.endpoints {
+			isHTTP2, err := isHTTP2Endpoint(endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if isHTTP2 {
+				http2Host.endpoints = append(http2Host.endpoints, endpoint)
+			} else {
+				httpHost.endpoints = append(httpHost.endpoints, endpoint)
+			}
+		}
+
+		switch {
+		case len(http2Host.endpoints) == 0:
+			httpRoutes, err := getHostHTTPRoutes(routesParams, httpHost)
+			if err != nil {
+				return nil, err
+			}
+			routes.HTTPRoutes = append(routes.HTTPRoutes, httpRoutes...)
+		case len(httpHost.endpoints) == 0:
+			grpcRoute, redirectRoute, err := getHostGRPCRoute(routesParams, http2Host)
+			if err != nil {
+				return nil, err
+			}
+			routes.GRPCRoutes = append(routes.GRPCRoutes, grpcRoute)
+			if redirectRoute != nil {
+				routes.HTTPRoutes = append(routes.HTTPRoutes, redirectRoute)
+			}
+		default:
+			endpoint, other := httpHost.endpoints[0], http2Host.endpoints[0]
+			if h.endpoints[0].Name == other.Name {
+				endpoint, other = other, endpoint
+			}
+			return nil, getRouteKindsConflictError(endpoint, other, h.host)
+		}
+	}
+	return routes, nil
+}
+
+// getRouteKindsConflictError returns the error of two endpoints of the same hostname routed by an HTTPRoute and a
+// GRPCRoute, which a Gateway listener does not accept
+func getRouteKindsConflictError(endpoint, otherEndpoint publicEndpoint, host string) error {
+	if host == "" {
+		host = "*"
+	}
+	return fmt.Errorf("endpoints %s and %s of host %q are routed by an HTTPRoute and a GRPCRoute, which cannot share a hostname on a Gateway",
+		endpoint.Name, otherEndpoint.Name, host)
+}
+
+// getHostHTTPRoutes returns the HTTPRoute routing the endpoints of a host, followed by the HTTPRoute redirecting the
+// host to HTTPS if it has secure endpoints
+func getHostHTTPRoutes(routesParams GatewayRoutesParams, h hostEndpoints) ([]*gatewayv1beta1.HTTPRoute, error) {
+	objectMeta, hostnames, secure, err := getGatewayRouteMeta(routesParams, h)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := h.endpoints
+
+	var rules []gatewayv1beta1.HTTPRouteRule
+	// routedPaths is the endpoint routing each path match of the host
+	routedPaths := make(map[string]string)
+	for _, endpoint := range endpoints {
+		path := endpoint.Path
+		if path == "" {
+			path = "/"
+		}
+		pathType, err := getEndpointPathType(endpoint.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		matchType := gatewayv1beta1.PathMatchPathPrefix
+		if pathType == networkingv1.PathTypeExact {
+			matchType = gatewayv1beta1.PathMatchExact
+		}
+		routedPath := string(matchType) + ":" + path
+		if other, ok := routedPaths[routedPath]; ok {
+			return nil, fmt.Errorf("endpoints %s and %s both route the path %s of host %q", other, endpoint.Name, path, getHostOrWildcard(hostnames))
+		}
+		routedPaths[routedPath] = endpoint.Name
+
+		port := gatewayv1beta1.PortNumber(endpoint.TargetPort)
+		rules = append(rules, gatewayv1beta1.HTTPRouteRule{
+			Matches: []gatewayv1beta1.HTTPRouteMatch{
+				{
+					Path: &gatewayv1beta1.HTTPPathMatch{
+						Type:  &matchType,
+						Value: pointer.String(path),
+					},
+				},
+			},
+			BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+				{
+					BackendRef: gatewayv1beta1.BackendRef{
+						BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+							Name: gatewayv1beta1.ObjectName(endpoint.serviceName),
+							Port: &port,
+						},
+					},
+				},
+			},
+		})
+	}
+
+	httpRoutes := []*gatewayv1beta1.HTTPRoute{
+		{
+			TypeMeta:   GetTypeMeta(httpRouteKind, gatewayV1beta1APIVersion),
+			ObjectMeta: objectMeta,
+			Spec: gatewayv1beta1.HTTPRouteSpec{
+				CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+					ParentRefs: getGatewayParentRefs(routesParams.Parent, secure),
+				},
+				Hostnames: hostnames,
+				Rules:     rules,
+			},
+		},
+	}
+	if redirectRoute := getHTTPSRedirectRoute(routesParams.Parent, objectMeta, hostnames, secure); redirectRoute != nil {
+		httpRoutes = append(httpRoutes, redirectRoute)
+	}
+	return httpRoutes, nil
+}
+
+// getHostGRPCRoute returns the GRPCRoute routing the http2 endpoints of a host, and the HTTPRoute redirecting the
+// host to HTTPS if it has secure endpoints. gRPC requests are not routed on their path, so a host can only have one
+// http2 endpoint.
+func getHostGRPCRoute(routesParams GatewayRoutesParams, h hostEndpoints) (*gatewayv1alpha2.GRPCRoute, *gatewayv1beta1.HTTPRoute, error) {
+	objectMeta, hostnames, secure, err := getGatewayRouteMeta(routesParams, h)
+	if err != nil {
+		return nil, nil, err
+	}
+	endpoints := h.endpoints
+	if len(endpoints) > 1 {
+		return nil, nil, fmt.Errorf("endpoints %s and %s both route the gRPC requests of host %q", endpoints[0].Name, endpoints[1].Name, getHostOrWildcard(hostnames))
+	}
+
+	endpoint := endpoints[0]
+	port := gatewayv1beta1.PortNumber(endpoint.TargetPort)
+	grpcRoute := &gatewayv1alpha2.GRPCRoute{
+		TypeMeta:   GetTypeMeta(grpcRouteKind, gatewayV1alpha2APIVersion),
+		ObjectMeta: objectMeta,
+		Spec: gatewayv1alpha2.GRPCRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+				ParentRefs: getGatewayParentRefs(routesParams.Parent, secure),
+			},
+			Hostnames: hostnames,
+			Rules: []gatewayv1alpha2.GRPCRouteRule{
+				{
+					BackendRefs: []gatewayv1alpha2.GRPCBackendRef{
+						{
+							BackendRef: gatewayv1beta1.BackendRef{
+								BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+									Name: gatewayv1beta1.ObjectName(endpoint.serviceName),
+									Port: &port,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return grpcRoute, getHTTPSRedirectRoute(routesParams.Parent, objectMeta, hostnames, secure), nil
+}
+
+// getGatewayRouteMeta returns the metadata of the route of the endpoints of a host, its hostnames,
+// and whether the host has secure endpoints
+func getGatewayRouteMeta(routesParams GatewayRoutesParams, h hostEndpoints) (metav1.ObjectMeta, []gatewayv1beta1.Hostname, bool, error) {
+	objectMeta := *routesParams.ObjectMeta.DeepCopy()
+	objectMeta.Name = h.name
+	host, endpoints := h.host, h.endpoints
+	annotations, err := getHostAnnotations(host, endpoints)
+	if err != nil {
+		return metav1.ObjectMeta{}, nil, false, err
+	}
+	objectMeta.Annotations = mergeMaps(objectMeta.Annotations, annotations)
+
+	var hostnames []gatewayv1beta1.Hostname
+	if host != "" {
+		hostnames = []gatewayv1beta1.Hostname{gatewayv1beta1.Hostname(host)}
+	}
+	secure := false
+	for _, endpoint := range endpoints {
+		if endpoint.Secure != nil && *endpoint.Secure {
+			secure = true
+		}
+	}
+	return objectMeta, hostnames, secure, nil
+}
+
+// getGatewayParentRefs returns the references to the parent Gateway of a route, attached to the listener
+// serving the secure or insecure hosts if set
+func getGatewayParentRefs(parent GatewayParent, secure bool) []gatewayv1beta1.ParentReference {
+	listenerName := parent.HTTPListenerName
+	if secure {
+		listenerName = parent.HTTPSListenerName
+	}
+	parentRefs := make([]gatewayv1beta1.ParentReference, 0, len(parent.ParentRefs))
+	for _, parentRef := range parent.ParentRefs {
+		parentRef = *parentRef.DeepCopy()
+		if listenerName != "" {
+			sectionName := gatewayv1beta1.SectionName(listenerName)
+			parentRef.SectionName = &sectionName
+		}
+		parentRefs = append(parentRefs, parentRef)
+	}
+	return parentRefs
+}
+
+// getHTTPSRedirectRoute returns the HTTPRoute redirecting a host with secure endpoints to HTTPS on the HTTP listener
+// of the parent Gateway, or nil if the host has no secure endpoint or the Gateway has no HTTP listener
+func getHTTPSRedirectRoute(parent GatewayParent, routeMeta metav1.ObjectMeta, hostnames []gatewayv1beta1.Hostname, secure bool) *gatewayv1beta1.HTTPRoute {
+	if !secure || parent.HTTPListenerName == "" {
+		return nil
+	}
+	objectMeta := *routeMeta.DeepCopy()
+	objectMeta.Name = getResourceName(routeMeta.Name, httpsRedirectRouteNameTail)
+	return &gatewayv1beta1.HTTPRoute{
+		TypeMeta:   GetTypeMeta(httpRouteKind, gatewayV1beta1APIVersion),
+		ObjectMeta: objectMeta,
+		Spec: gatewayv1beta1.HTTPRouteSpec{
+			CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{
+				ParentRefs: getGatewayParentRefs(parent, false),
+			},
+			Hostnames: hostnames,
+			Rules: []gatewayv1beta1.HTTPRouteRule{
+				{
+					Filters: []gatewayv1beta1.HTTPRouteFilter{
+						{
+							Type: gatewayv1beta1.HTTPRouteFilterRequestRedirect,
+							RequestRedirect: &gatewayv1beta1.HTTPRequestRedirectFilter{
+								Scheme:     pointer.String("https"),
+								StatusCode: pointer.Int(301),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// isHTTP2Endpoint returns true if the application protocol of the endpoint, set by AppProtocolAttribute, is http2
+func isHTTP2Endpoint(endpoint publicEndpoint) (bool, error) {
+	if !endpoint.Attributes.Exists(AppProtocolAttribute) {
+		return false, nil
+	}
+	appProtocol, err := getEndpointStringAttribute(endpoint.Endpoint, AppProtocolAttribute)
+	if err != nil {
+		return false, err
+	}
+	return http2AppProtocols[appProtocol], nil
+}
+
+// getHostOrWildcard returns the hostname of a route, or * if the route matches any hostname
+func getHostOrWildcard(hostnames []gatewayv1beta1.Hostname) string {
+	if len(hostnames) == 0 {
+		return "*"
+	}
+	return string(hostnames[0])
+}
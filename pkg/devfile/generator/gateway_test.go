@@ -0,0 +1,279 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// gatewayParentRef returns a reference to the gateway parent, attached to the given listener if not empty
+func gatewayParentRef(listenerName string) gatewayv1beta1.ParentReference {
+	parentRef := gatewayv1beta1.ParentReference{Name: "gateway"}
+	if listenerName != "" {
+		sectionName := gatewayv1beta1.SectionName(listenerName)
+		parentRef.SectionName = &sectionName
+	}
+	return parentRef
+}
+
+// httpRouteRule returns a rule routing a path match to the port of the app Service
+func httpRouteRule(matchType gatewayv1beta1.PathMatchType, path string, port gatewayv1beta1.PortNumber) gatewayv1beta1.HTTPRouteRule {
+	return gatewayv1beta1.HTTPRouteRule{
+		Matches: []gatewayv1beta1.HTTPRouteMatch{
+			{Path: &gatewayv1beta1.HTTPPathMatch{Type: &matchType, Value: pointer.String(path)}},
+		},
+		BackendRefs: []gatewayv1beta1.HTTPBackendRef{
+			{BackendRef: gatewayv1beta1.BackendRef{BackendObjectReference: gatewayv1beta1.BackendObjectReference{Name: "app", Port: &port}}},
+		},
+	}
+}
+
+func TestGetGatewayRoutes(t *testing.T) {
+	httpRouteTypeMeta := GetTypeMeta(httpRouteKind, gatewayV1beta1APIVersion)
+	grpcPort := gatewayv1beta1.PortNumber(9000)
+	tests := []struct {
+		name      string
+		endpoints string
+		parent    GatewayParent
+		// withoutDomain routes the endpoints without ingress-host for any hostname
+		withoutDomain bool
+		wantRoutes    *GatewayRoutes
+		wantErr       string
+	}{
+		{
+			name: "endpoints without listeners",
+			endpoints: `        - name: http
+          targetPort: 8080
+          path: /api
+          attributes:
+            ingress-path-type: Exact
+        - name: admin
+          targetPort: 8443
+          secure: true
+`,
+			parent: GatewayParent{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("")}},
+			wantRoutes: &GatewayRoutes{
+				HTTPRoutes: []*gatewayv1beta1.HTTPRoute{
+					{
+						TypeMeta:   httpRouteTypeMeta,
+						ObjectMeta: metav1.ObjectMeta{Name: "app-admin"},
+						Spec: gatewayv1beta1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("")}},
+							Hostnames:       []gatewayv1beta1.Hostname{"app-admin.apps.example.com"},
+							Rules:           []gatewayv1beta1.HTTPRouteRule{httpRouteRule(gatewayv1beta1.PathMatchPathPrefix, "/", 8443)},
+						},
+					},
+					{
+						TypeMeta:   httpRouteTypeMeta,
+						ObjectMeta: metav1.ObjectMeta{Name: "app-http"},
+						Spec: gatewayv1beta1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("")}},
+							Hostnames:       []gatewayv1beta1.Hostname{"app-http.apps.example.com"},
+							Rules:           []gatewayv1beta1.HTTPRouteRule{httpRouteRule(gatewayv1beta1.PathMatchExact, "/api", 8080)},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "secure host and gRPC endpoint with listeners",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            ingress-host: app.example.com
+        - name: api
+          targetPort: 8081
+          path: /api
+          secure: true
+          attributes:
+            ingress-host: app.example.com
+        - name: grpc
+          targetPort: 9000
+          attributes:
+            app-protocol: grpc
+`,
+			parent: GatewayParent{
+				ParentRefs:        []gatewayv1beta1.ParentReference{gatewayParentRef("")},
+				HTTPListenerName:  "http",
+				HTTPSListenerName: "https",
+			},
+			wantRoutes: &GatewayRoutes{
+				HTTPRoutes: []*gatewayv1beta1.HTTPRoute{
+					{
+						TypeMeta:   httpRouteTypeMeta,
+						ObjectMeta: metav1.ObjectMeta{Name: "app-api"},
+						Spec: gatewayv1beta1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("https")}},
+							Hostnames:       []gatewayv1beta1.Hostname{"app.example.com"},
+							Rules: []gatewayv1beta1.HTTPRouteRule{
+								httpRouteRule(gatewayv1beta1.PathMatchPathPrefix, "/api", 8081),
+								httpRouteRule(gatewayv1beta1.PathMatchPathPrefix, "/", 8080),
+							},
+						},
+					},
+					{
+						TypeMeta:   httpRouteTypeMeta,
+						ObjectMeta: metav1.ObjectMeta{Name: "app-api-redirect"},
+						Spec: gatewayv1beta1.HTTPRouteSpec{
+							CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("http")}},
+							Hostnames:       []gatewayv1beta1.Hostname{"app.example.com"},
+							Rules: []gatewayv1beta1.HTTPRouteRule{
+								{
+									Filters: []gatewayv1beta1.HTTPRouteFilter{
+										{
+											Type: gatewayv1beta1.HTTPRouteFilterRequestRedirect,
+											RequestRedirect: &gatewayv1beta1.HTTPRequestRedirectFilter{
+												Scheme:     pointer.String("https"),
+												StatusCode: pointer.Int(301),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				GRPCRoutes: []*gatewayv1alpha2.GRPCRoute{
+					{
+						TypeMeta:   GetTypeMeta(grpcRouteKind, gatewayV1alpha2APIVersion),
+						ObjectMeta: metav1.ObjectMeta{Name: "app-grpc"},
+						Spec: gatewayv1alpha2.GRPCRouteSpec{
+							CommonRouteSpec: gatewayv1beta1.CommonRouteSpec{ParentRefs: []gatewayv1beta1.ParentReference{gatewayParentRef("http")}},
+							Hostnames:       []gatewayv1beta1.Hostname{"app-grpc.apps.example.com"},
+							Rules: []gatewayv1alpha2.GRPCRouteRule{
+								{
+									BackendRefs: []gatewayv1alpha2.GRPCBackendRef{
+										{
+											BackendRef: gatewayv1beta1.BackendRef{
+												BackendObjectReference: gatewayv1beta1.BackendObjectReference{
+													Name: "app",
+													Port: &grpcPort,
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "gRPC endpoints sharing a host",
+			endpoints: `        - name: grpc
+          targetPort: 9000
+          attributes:
+            app-protocol: grpc
+            ingress-host: grpc.example.com
+        - name: http
+          targetPort: 9001
+          attributes:
+            app-protocol: kubernetes.io/h2c
+            ingress-host: grpc.example.com
+`,
+			wantErr: `endpoints grpc and http both route the gRPC requests of host "grpc.example.com"`,
+		},
+		{
+			name: "secure HTTP and gRPC endpoints sharing a host",
+			endpoints: `        - name: api
+          targetPort: 8081
+          secure: true
+          attributes:
+            ingress-host: app.example.com
+        - name: grpc
+          targetPort: 9000
+          secure: true
+          attributes:
+            app-protocol: grpc
+            ingress-host: app.example.com
+`,
+			parent: GatewayParent{
+				ParentRefs:        []gatewayv1beta1.ParentReference{gatewayParentRef("")},
+				HTTPListenerName:  "http",
+				HTTPSListenerName: "https",
+			},
+			wantErr: `endpoints api and grpc of host "app.example.com" are routed by an HTTPRoute and a GRPCRoute, which cannot share a hostname on a Gateway`,
+		},
+		{
+			name: "HTTP and gRPC endpoints routed for any hostname",
+			endpoints: `        - name: grpc
+          targetPort: 9000
+          attributes:
+            app-protocol: grpc
+        - name: http
+          targetPort: 8080
+`,
+			withoutDomain: true,
+			wantErr:       `endpoints grpc and http of host "*" are routed by an HTTPRoute and a GRPCRoute, which cannot share a hostname on a Gateway`,
+		},
+		{
+			name: "HTTP endpoints routing the same path for any hostname",
+			endpoints: `        - name: http
+          targetPort: 8080
+        - name: admin
+          targetPort: 8081
+`,
+			withoutDomain: true,
+			wantErr:       `endpoints admin and http both route the path / of host "*"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(ingressesDevfileHeader + tt.endpoints)})
+			domain := "apps.example.com"
+			if tt.withoutDomain {
+				domain = ""
+			}
+			routes, err := GetGatewayRoutes(devfileObj, GatewayRoutesParams{
+				ObjectMeta:       metav1.ObjectMeta{Name: "app"},
+				EndpointServices: map[string]string{"http": "app", "api": "app", "admin": "app", "grpc": "app"},
+				Domain:           domain,
+				Parent:           tt.parent,
+			}, common.DevfileOptions{})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetGatewayRoutes() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetGatewayRoutes() unexpected error: %v", err)
+			}
+			for _, route := range routes.HTTPRoutes {
+				if len(route.Annotations) == 0 {
+					route.Annotations = nil
+				}
+			}
+			for _, route := range routes.GRPCRoutes {
+				if len(route.Annotations) == 0 {
+					route.Annotations = nil
+				}
+			}
+			if diff := cmp.Diff(tt.wantRoutes, routes); diff != "" {
+				t.Errorf("GetGatewayRoutes() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -79,7 +79,13 @@ func GetObjectMeta(name, namespace string, labels, annotations map[string]string
 //
 // Deprecated: in favor of GetPodTemplateSpec
 func GetContainers(devfileObj parser.DevfileObj, options common.DevfileOptions) ([]corev1.Container, error) {
-	allContainers, err := getAllContainers(devfileObj, options)
+	return getContainersWithPullPolicy(devfileObj, options, "")
+}
+
+// getContainersWithPullPolicy returns the containers returned by GetContainers,
+// pulling their images as returned by getImagePullPolicy with the given default pull policy
+func getContainersWithPullPolicy(devfileObj parser.DevfileObj, options common.DevfileOptions, defaultPullPolicy corev1.PullPolicy) ([]corev1.Container, error) {
+	allContainers, err := getAllContainersWithPullPolicy(devfileObj, options, defaultPullPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -127,17 +133,27 @@ func GetContainers(devfileObj parser.DevfileObj, options common.DevfileOptions)
 //
 // Deprecated: in favor of GetPodTemplateSpec
 func GetInitContainers(devfileObj parser.DevfileObj) ([]corev1.Container, error) {
-	containers, err := getAllContainers(devfileObj, common.DevfileOptions{})
+	initContainers, _, err := getInitContainers(devfileObj, nil, "")
+	return initContainers, err
+}
+
+// getInitContainers gets the init container for every preStart devfile event applying a component accepted by include.
+// A nil include accepts all the components. The images are pulled as returned by getImagePullPolicy with the given
+// default pull policy. The init containers are returned along with the names of the components they are built from,
+// indexed by init container name.
+func getInitContainers(devfileObj parser.DevfileObj, include func(componentName string) bool, defaultPullPolicy corev1.PullPolicy) ([]corev1.Container, map[string]string, error) {
+	containers, err := getAllContainersWithPullPolicy(devfileObj, common.DevfileOptions{}, defaultPullPolicy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	preStartEvents := devfileObj.Data.GetEvents().PreStart
 	var initContainers []corev1.Container
+	initComponents := map[string]string{}
 	if len(preStartEvents) > 0 {
 		var eventCommands []string
 		commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		commandsMap := common.GetCommandsMap(commands)
@@ -153,7 +169,7 @@ func GetInitContainers(devfileObj parser.DevfileObj) ([]corev1.Container, error)
 
 			// Get the container info for the given component
 			for _, container := range containers {
-				if container.Name == component {
+				if container.Name == component && (include == nil || include(component)) {
 					// Override the init container name since there cannot be two containers with the same
 					// name in a pod. This applies to pod containers and pod init containers. The convention
 					// for init container name here is, containername-eventname-<position of command in prestart events>
@@ -166,12 +182,13 @@ func GetInitContainers(devfileObj parser.DevfileObj) ([]corev1.Container, error)
 					container.Name = initContainerName
 
 					initContainers = append(initContainers, container)
+					initComponents[initContainerName] = component
 				}
 			}
 		}
 	}
 
-	return initContainers, nil
+	return initContainers, initComponents, nil
 }
 
 // DeploymentParams is a struct that contains the required data to create a deployment object
@@ -192,6 +209,12 @@ type DeploymentParams struct {
 
 // GetDeployment gets a deployment object
 func GetDeployment(devfileObj parser.DevfileObj, deployParams DeploymentParams) (*appsv1.Deployment, error) {
+	return getDeployment(devfileObj, deployParams, nil)
+}
+
+// getDeployment gets a deployment object annotated with the deployment annotations of the container components accepted by include.
+// A nil include accepts the container components without dedicatedPod.
+func getDeployment(devfileObj parser.DevfileObj, deployParams DeploymentParams, include func(componentName string) bool) (*appsv1.Deployment, error) {
 
 	deploySpecParams := deploymentSpecParams{
 		PodSelectorLabels: deployParams.PodSelectorLabels,
@@ -220,15 +243,14 @@ func GetDeployment(devfileObj parser.DevfileObj, deployParams DeploymentParams)
 		deploySpecParams.PodTemplateSpec = *deployParams.PodTemplateSpec
 	}
 
-	containerAnnotations, err := getContainerAnnotations(devfileObj, common.DevfileOptions{})
+	objectMeta, err := getWorkloadObjectMeta(devfileObj, deployParams.ObjectMeta, include)
 	if err != nil {
 		return nil, err
 	}
-	deployParams.ObjectMeta.Annotations = mergeMaps(deployParams.ObjectMeta.Annotations, containerAnnotations.Deployment)
 
 	deployment := &appsv1.Deployment{
 		TypeMeta:   deployParams.TypeMeta,
-		ObjectMeta: deployParams.ObjectMeta,
+		ObjectMeta: objectMeta,
 		Spec:       *getDeploymentSpec(deploySpecParams),
 	}
 
@@ -240,25 +262,91 @@ type PodTemplateParams struct {
 	ObjectMeta metav1.ObjectMeta
 	Options    common.DevfileOptions
 	// PodSecurityAdmissionPolicy is the policy to be respected by the created pod
-	// The pod will be patched, if necessary, to respect the policies
+	// The pod will be patched, if necessary, to respect the enforce level of the policy
 	PodSecurityAdmissionPolicy psaapi.Policy
+	// PodSecurityAdmissionDryRun reports the violations of PodSecurityAdmissionPolicy without patching the pod
+	PodSecurityAdmissionDryRun bool
+	// LifecycleHooks turns the exec commands of the postStart and preStop events into
+	// postStart and preStop lifecycle hooks of the containers running them
+	LifecycleHooks bool
+	// ProjectsVolume, if set, adds the volume holding the projects to the pod,
+	// mounted at the sourceMapping of the containers with mountSources
+	ProjectsVolume *ProjectsVolume
+	// Probes adds probes to the containers, generated from the endpoints of their components as configured by ProbeAttribute
+	Probes bool
+	// ImagePullPolicy is the pull policy of the images of the container components without ImagePullPolicyAttribute.
+	// If empty, the images pinned by digest are pulled if not present, and the other images are always pulled.
+	ImagePullPolicy corev1.PullPolicy
+	// ImagePullSecrets are the secrets used to pull the images, along with the ImagePullSecretsAttribute attribute of the devfile
+	ImagePullSecrets []corev1.LocalObjectReference
+	// ServiceAccountName is the ServiceAccount running the pod. It overrides the ServiceAccountAttribute attribute of the devfile.
+	ServiceAccountName string
 }
 
 // GetPodTemplateSpec returns a pod template
 // The function:
 // - iterates through all container components, filters out init containers and gets corresponding containers
 // - gets the init container for every preStart devfile event
-// - patches the pod template and containers to satisfy PodSecurityAdmissionPolicy
+// - if podTemplateParams.LifecycleHooks is set, adds the lifecycle hooks for the postStart and preStop devfile events
+// - if podTemplateParams.Probes is set, adds the probes generated from the endpoints of the container components
+// - if podTemplateParams.ProjectsVolume is set, adds the projects volume mounted in the containers with mountSources,
+// and the init container cloning the projects
+// - sets the image pull secrets and the ServiceAccount of the pod
+// - places the pod on the nodes with the architectures of the devfile metadata, as configured by the NodeSelectorAttribute,
+// TolerationsAttribute and TopologySpreadConstraintsAttribute attributes
+// - patches the pod template and containers to satisfy the enforce level of PodSecurityAdmissionPolicy,
+// unless PodSecurityAdmissionDryRun is set
 // - patches the pod template and containers to apply pod and container overrides
 // The containers included in the podTemplateSpec can be filtered using podTemplateParams.Options
 func GetPodTemplateSpec(devfileObj parser.DevfileObj, podTemplateParams PodTemplateParams) (*corev1.PodTemplateSpec, error) {
-	containers, err := GetContainers(devfileObj, podTemplateParams.Options)
+	podTemplateSpec, _, err := getPodTemplateSpecForComponents(devfileObj, podTemplateParams, nil, false)
+	return podTemplateSpec, err
+}
+
+// GetPodTemplateSpecWithPolicyReport returns the pod template returned by GetPodTemplateSpec, and the report of the
+// checks of podTemplateParams.PodSecurityAdmissionPolicy violated by the pod and of the fields patched to respect them.
+// The checks are evaluated on the final pod, after the pod and container overrides, as described by PolicyReport.
+func GetPodTemplateSpecWithPolicyReport(devfileObj parser.DevfileObj, podTemplateParams PodTemplateParams) (*corev1.PodTemplateSpec, *PolicyReport, error) {
+	return getPodTemplateSpecForComponents(devfileObj, podTemplateParams, nil, false)
+}
+
+// getPodTemplateSpecForComponents returns a pod template running the container components accepted by include,
+// as described by GetPodTemplateSpec. A nil include accepts all the container components.
+// keepEventContainers keeps the containers of the components applied by preStart and postStop events,
+// which are otherwise only run by the events.
+// Pod overrides of the components apply only to the pods running them, while global pod overrides apply to every pod.
+func getPodTemplateSpecForComponents(devfileObj parser.DevfileObj, podTemplateParams PodTemplateParams, include func(componentName string) bool, keepEventContainers bool) (*corev1.PodTemplateSpec, *PolicyReport, error) {
+	getContainers := getContainersWithPullPolicy
+	if keepEventContainers {
+		getContainers = getAllContainersWithPullPolicy
+	}
+	allContainers, err := getContainers(devfileObj, podTemplateParams.Options, podTemplateParams.ImagePullPolicy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	var containers []corev1.Container
+	for _, container := range allContainers {
+		if include == nil || include(container.Name) {
+			containers = append(containers, container)
+		}
 	}
-	initContainers, err := GetInitContainers(devfileObj)
+	initContainers, initComponents, err := getInitContainers(devfileObj, include, podTemplateParams.ImagePullPolicy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if podTemplateParams.LifecycleHooks {
+		lifecycles, err := getLifecycles(devfileObj)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range containers {
+			containers[i].Lifecycle = lifecycles[containers[i].Name]
+		}
+	}
+	if podTemplateParams.Probes {
+		if err = addProbes(devfileObj, containers); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	podTemplateSpecParams := podTemplateSpecParams{
@@ -266,6 +354,11 @@ func GetPodTemplateSpec(devfileObj parser.DevfileObj, podTemplateParams PodTempl
 		InitContainers: initContainers,
 		Containers:     containers,
 	}
+	if podTemplateParams.ProjectsVolume != nil {
+		if err = addProjectsVolume(devfileObj, *podTemplateParams.ProjectsVolume, &podTemplateSpecParams); err != nil {
+			return nil, nil, err
+		}
+	}
 	var globalAttributes attributes.Attributes
 	// attributes is not supported in versions less than 2.1.0, so we skip it
 	if devfileObj.Data.GetSchemaVersion() > string(data.APISchemaVersion200) {
@@ -273,43 +366,193 @@ func GetPodTemplateSpec(devfileObj parser.DevfileObj, podTemplateParams PodTempl
 		// so we'll skip checking for error here
 		globalAttributes, _ = devfileObj.Data.GetAttributes()
 	}
-	components, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	allComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	var components []v1.Component
+	for _, comp := range allComponents {
+		if include == nil || include(comp.Name) {
+			components = append(components, comp)
+		}
 	}
 
 	podTemplateSpec, err := getPodTemplateSpec(podTemplateSpecParams)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	err = setPodIdentity(globalAttributes, &podTemplateSpec.Spec, podTemplateParams.ImagePullSecrets, podTemplateParams.ServiceAccountName)
+	if err != nil {
+		return nil, nil, err
+	}
+	running := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		running[container.Name] = true
+	}
+	var podComponents []v1.Component
+	for _, comp := range components {
+		if running[comp.Name] {
+			podComponents = append(podComponents, comp)
+		}
+	}
+	err = setPodPlacement(globalAttributes, podComponents, devfileObj.Data.GetMetadata().Architectures, podTemplateSpec)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	podTemplateSpec, err = patchForPolicy(podTemplateSpec, podTemplateParams.PodSecurityAdmissionPolicy)
+	podTemplateSpec, patchedViolations, err := patchForPolicy(podTemplateSpec, podTemplateParams.PodSecurityAdmissionPolicy, podTemplateParams.PodSecurityAdmissionDryRun)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if needsPodOverrides(globalAttributes, components) {
 		patchedPodTemplateSpec, err := applyPodOverrides(globalAttributes, components, podTemplateSpec)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		patchedPodTemplateSpec.ObjectMeta = podTemplateSpecParams.ObjectMeta
 		podTemplateSpec = patchedPodTemplateSpec
 	}
 
-	podTemplateSpec.Spec.Containers, err = applyContainerOverrides(devfileObj, podTemplateSpec.Spec.Containers)
+	podTemplateSpec.Spec.Containers, err = applyContainerOverrides(devfileObj, podTemplateSpec.Spec.Containers, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	podTemplateSpec.Spec.InitContainers, err = applyContainerOverrides(devfileObj, podTemplateSpec.Spec.InitContainers, initComponents)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	policyReport, err := getPolicyReport(podTemplateSpec, podTemplateParams.PodSecurityAdmissionPolicy, patchedViolations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return podTemplateSpec, policyReport, nil
+}
+
+// PodGroup is a pod running container components of a devfile, as returned by GetPodGroups
+type PodGroup struct {
+	// Component is the name of the container component with dedicatedPod running alone in the pod,
+	// or empty for the pod shared by the container components without dedicatedPod
+	Component string
+	// Components are the names of the container components running in the pod, as containers or as init containers
+	Components []string
+	// Commands are the ids of the exec and apply commands running in a container of the pod
+	Commands []string
+	// PodTemplateSpec is the pod template of the pod
+	PodTemplateSpec *corev1.PodTemplateSpec
+	// PolicyReport reports the checks of the Pod Security Admission policy violated by the pod, as returned by GetPodTemplateSpecWithPolicyReport
+	PolicyReport *PolicyReport
+}
+
+// GetPodGroups returns the pods running the container components of the devfile:
+// - a pod shared by the container components without dedicatedPod, if there is any
+// - a pod for each container component with dedicatedPod, in the order of the components
+// Each pod template is built as described by GetPodTemplateSpec, with the init containers of the preStart events
+// applying its components. A container component with dedicatedPod always runs as the container of its pod,
+// even if it is applied by preStart or postStop events.
+// A shared pod with no container other than init containers is rejected.
+func GetPodGroups(devfileObj parser.DevfileObj, podTemplateParams PodTemplateParams) ([]PodGroup, error) {
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
 	if err != nil {
 		return nil, err
 	}
-	podTemplateSpec.Spec.InitContainers, err = applyContainerOverrides(devfileObj, podTemplateSpec.Spec.InitContainers)
+
+	initComponents, err := getPreStartComponents(devfileObj)
 	if err != nil {
 		return nil, err
 	}
 
-	return podTemplateSpec, nil
+	dedicatedPods := make(map[string]bool)
+	groups := []PodGroup{{}}
+	for _, comp := range containerComponents {
+		if isDedicatedPod(comp) {
+			dedicatedPods[comp.Name] = true
+			groups = append(groups, PodGroup{Component: comp.Name})
+		}
+	}
+
+	var result []PodGroup
+	for _, group := range groups {
+		component := group.Component
+		include := func(componentName string) bool {
+			if component == "" {
+				return !dedicatedPods[componentName]
+			}
+			return componentName == component
+		}
+		group.PodTemplateSpec, group.PolicyReport, err = getPodTemplateSpecForComponents(devfileObj, podTemplateParams, include, component != "")
+		if err != nil {
+			return nil, err
+		}
+		spec := group.PodTemplateSpec.Spec
+		if len(spec.Containers) == 0 {
+			if len(spec.InitContainers) > 0 {
+				return nil, errors.New("the container components without dedicatedPod are only run by preStart events, their pod would have no container")
+			}
+			continue
+		}
+
+		running := make(map[string]bool)
+		for _, container := range spec.Containers {
+			running[container.Name] = true
+		}
+		for _, comp := range containerComponents {
+			if include(comp.Name) && (running[comp.Name] || initComponents[comp.Name]) {
+				group.Components = append(group.Components, comp.Name)
+			}
+		}
+		for _, command := range commands {
+			var commandComponent string
+			switch {
+			case command.Exec != nil:
+				commandComponent = command.Exec.Component
+			case command.Apply != nil:
+				commandComponent = command.Apply.Component
+			default:
+				continue
+			}
+			for _, name := range group.Components {
+				if name == commandComponent {
+					group.Commands = append(group.Commands, command.Id)
+					break
+				}
+			}
+		}
+		result = append(result, group)
+	}
+	return result, nil
 }
 
-func applyContainerOverrides(devfileObj parser.DevfileObj, containers []corev1.Container) ([]corev1.Container, error) {
+// getPreStartComponents returns the names of the components applied by the preStart events
+func getPreStartComponents(devfileObj parser.DevfileObj) (map[string]bool, error) {
+	components := make(map[string]bool)
+	preStartEvents := devfileObj.Data.GetEvents().PreStart
+	if len(preStartEvents) == 0 {
+		return components, nil
+	}
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	commandsMap := common.GetCommandsMap(commands)
+	for _, event := range preStartEvents {
+		for _, commandName := range common.GetCommandsFromEvent(commandsMap, event) {
+			if component := common.GetApplyComponent(commandsMap[commandName]); component != "" {
+				components[component] = true
+			}
+		}
+	}
+	return components, nil
+}
+
+// applyContainerOverrides applies the container overrides of the components to the containers. The component of a
+// container is found in componentNames, indexed by container name, or is named after the container.
+func applyContainerOverrides(devfileObj parser.DevfileObj, containers []corev1.Container, componentNames map[string]string) ([]corev1.Container, error) {
 	containerComponents, err := devfileObj.Data.GetComponents(common.DevfileOptions{
 		ComponentOptions: common.ComponentOptions{
 			ComponentType: v1.ContainerComponentType,
@@ -319,22 +562,25 @@ func applyContainerOverrides(devfileObj parser.DevfileObj, containers []corev1.C
 		return nil, err
 	}
 
-	getContainerByName := func(name string) (*corev1.Container, bool) {
-		for _, container := range containers {
-			if container.Name == name {
-				return &container, true
+	getComponentByName := func(name string) (v1.Component, bool) {
+		for _, comp := range containerComponents {
+			if comp.Name == name {
+				return comp, true
 			}
 		}
-		return nil, false
+		return v1.Component{}, false
 	}
 
+	// containers without a matching component, such as the containers added by the pod overrides, are kept as is
 	result := make([]corev1.Container, 0, len(containers))
-	for _, comp := range containerComponents {
-		container, found := getContainerByName(comp.Name)
-		if !found {
-			continue
+	for i := range containers {
+		container := &containers[i]
+		componentName, ok := componentNames[container.Name]
+		if !ok {
+			componentName = container.Name
 		}
-		if comp.Attributes.Exists(ContainerOverridesAttribute) {
+		comp, found := getComponentByName(componentName)
+		if found && comp.Attributes.Exists(ContainerOverridesAttribute) {
 			patched, err := containerOverridesHandler(comp, container)
 			if err != nil {
 				return nil, err
@@ -352,11 +598,22 @@ type PVCParams struct {
 	TypeMeta   metav1.TypeMeta
 	ObjectMeta metav1.ObjectMeta
 	Quantity   resource.Quantity
+	// StorageClassName is the storage class of the PVC. The default storage class is used if nil.
+	StorageClassName *string
+	// AccessModes are the access modes of the PVC. Defaults to ReadWriteOnce.
+	AccessModes []corev1.PersistentVolumeAccessMode
+	// VolumeMode is the volume mode of the PVC. Defaults to Filesystem.
+	VolumeMode *corev1.PersistentVolumeMode
 }
 
 // GetPVC returns a PVC
 func GetPVC(pvcParams PVCParams) *corev1.PersistentVolumeClaim {
 	pvcSpec := getPVCSpec(pvcParams.Quantity)
+	pvcSpec.StorageClassName = pvcParams.StorageClassName
+	pvcSpec.VolumeMode = pvcParams.VolumeMode
+	if len(pvcParams.AccessModes) > 0 {
+		pvcSpec.AccessModes = pvcParams.AccessModes
+	}
 
 	pvc := &corev1.PersistentVolumeClaim{
 		TypeMeta:   pvcParams.TypeMeta,
@@ -376,12 +633,18 @@ type ServiceParams struct {
 
 // GetService gets the service
 func GetService(devfileObj parser.DevfileObj, serviceParams ServiceParams, options common.DevfileOptions) (*corev1.Service, error) {
+	return getService(devfileObj, serviceParams, options, nil)
+}
 
-	serviceSpec, err := getServiceSpec(devfileObj, serviceParams.SelectorLabels, options)
+// getService gets the service exposing the endpoints of the container components accepted by include.
+// A nil include exposes the endpoints of all the container components, annotated as the components without dedicatedPod.
+func getService(devfileObj parser.DevfileObj, serviceParams ServiceParams, options common.DevfileOptions, include func(componentName string) bool) (*corev1.Service, error) {
+
+	serviceSpec, err := getServiceSpec(devfileObj, serviceParams.SelectorLabels, options, include)
 	if err != nil {
 		return nil, err
 	}
-	containerAnnotations, err := getContainerAnnotations(devfileObj, options)
+	containerAnnotations, err := getContainerAnnotations(devfileObj, options, include)
 	if err != nil {
 		return nil, err
 	}
@@ -402,7 +665,10 @@ type IngressParams struct {
 	IngressSpecParams IngressSpecParams
 }
 
-// GetIngress gets an ingress
+// GetIngress gets an extensions v1beta1 ingress, for legacy clusters not serving the networking v1 API.
+// PathType and IngressClassName of the ingress spec params are not supported by this API and are ignored.
+//
+// Deprecated: use GetNetworkingV1Ingress, or GetIngresses to route all the public endpoints of a devfile.
 func GetIngress(endpoint v1.Endpoint, ingressParams IngressParams) *extensionsv1.Ingress {
 	ingressSpec := getIngressSpec(ingressParams.IngressSpecParams)
 	ingressParams.ObjectMeta.Annotations = mergeMaps(ingressParams.ObjectMeta.Annotations, endpoint.Annotations)
@@ -416,7 +682,8 @@ func GetIngress(endpoint v1.Endpoint, ingressParams IngressParams) *extensionsv1
 	return ingress
 }
 
-// GetNetworkingV1Ingress gets a networking v1 ingress
+// GetNetworkingV1Ingress gets a networking v1 ingress routing a single endpoint.
+// See GetIngresses to route all the public endpoints of a devfile, sharing an Ingress per host.
 func GetNetworkingV1Ingress(endpoint v1.Endpoint, ingressParams IngressParams) *networkingv1.Ingress {
 	ingressSpec := getNetworkingV1IngressSpec(ingressParams.IngressSpecParams)
 	ingressParams.ObjectMeta.Annotations = mergeMaps(ingressParams.ObjectMeta.Annotations, endpoint.Annotations)
@@ -452,6 +719,15 @@ func GetRoute(endpoint v1.Endpoint, routeParams RouteParams) *routev1.Route {
 	return route
 }
 
+// GetRouteWithValidation gets a route as GetRoute, and returns an error if the route spec params do not define a valid
+// route, such as a passthrough route with a path. The params returned by GetEndpointRouteSpecParams are already valid.
+func GetRouteWithValidation(endpoint v1.Endpoint, routeParams RouteParams) (*routev1.Route, error) {
+	if err := validateRouteSpecParams(routeParams.RouteSpecParams); err != nil {
+		return nil, fmt.Errorf("invalid route of endpoint %s: %w", endpoint.Name, err)
+	}
+	return GetRoute(endpoint, routeParams), nil
+}
+
 // GetOwnerReference generates an ownerReference  from the deployment which can then be set as
 // owner for various Kubernetes objects and ensure that when the owner object is deleted from the
 // cluster, all other objects are automatically removed by Kubernetes garbage collector
@@ -565,7 +841,7 @@ func GetVolumesAndVolumeMounts(devfileObj parser.DevfileObj, volumeParams Volume
 	for volName, volInfo := range volumeParams.VolumeNameToVolumeInfo {
 		emptyDirVolume := false
 		for _, volumeComp := range volumeComponent {
-			if volumeComp.Name == volName && *volumeComp.Volume.Ephemeral {
+			if volumeComp.Name == volName && volumeComp.Volume.Ephemeral != nil && *volumeComp.Volume.Ephemeral {
 				emptyDirVolume = true
 				break
 			}
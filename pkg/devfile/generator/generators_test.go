@@ -18,6 +18,7 @@ package generator
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -25,6 +26,7 @@ import (
 	"github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/devfile/api/v2/pkg/attributes"
+	devfilepkg "github.com/devfile/api/v2/pkg/devfile"
 	"github.com/devfile/library/v2/pkg/devfile"
 	"github.com/devfile/library/v2/pkg/devfile/parser"
 	context "github.com/devfile/library/v2/pkg/devfile/parser/context"
@@ -1373,6 +1375,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetProjects(gomock.Any()).Return(nil, nil).AnyTimes()
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1420,6 +1423,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetDevfileContainerComponents(gomock.Any()).Return(nil, errors.New("an error")).AnyTimes()
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1465,6 +1469,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{
 						PodOverridesAttribute: apiext.JSON{Raw: []byte("{\"spec\": {\"serviceAccountName\": \"new-service-account\"}}")}}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1529,6 +1534,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{
 						PodOverridesAttribute: apiext.JSON{Raw: []byte("{\"spec\": {\"serviceAccountName\": \"new-service-account\"}}")}}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1583,6 +1589,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetProjects(gomock.Any()).Return(nil, nil).AnyTimes()
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1638,6 +1645,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 						PodOverridesAttribute: apiext.JSON{Raw: []byte("{\"spec\": {\"serviceAccountName\": \"new-service-account\"}}")},
 					}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1693,6 +1701,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 						ContainerOverridesAttribute: apiext.JSON{Raw: []byte("{\"securityContext\": {\"runAsGroup\": 3000}}")},
 					}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1745,6 +1754,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 						PodOverridesAttribute: apiext.JSON{Raw: []byte("{\"spec\": {\"securityContext\": {\"seccompProfile\": {\"type\": \"Localhost\"}}}}")},
 					}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1814,6 +1824,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetProjects(gomock.Any()).Return(nil, nil).AnyTimes()
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1883,6 +1894,7 @@ func TestGetPodTemplateSpec(t *testing.T) {
 					mockDevfileData.EXPECT().GetProjects(gomock.Any()).Return(nil, nil).AnyTimes()
 					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
 					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
 					return parser.DevfileObj{
 						Data: mockDevfileData,
 					}
@@ -1976,6 +1988,92 @@ func TestGetPodTemplateSpec(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Devfile with container-override and an init container",
+			args: args{
+				devfileObj: func(ctrl *gomock.Controller) parser.DevfileObj {
+					containers := []v1alpha2.Component{
+						{
+							Name: "main",
+							ComponentUnion: v1.ComponentUnion{
+								Container: &v1.ContainerComponent{
+									Container: v1.Container{
+										Image:        "an-image",
+										MountSources: pointer.Bool(false),
+									},
+								},
+							},
+							Attributes: attributes.Attributes{}.FromMap(map[string]interface{}{
+								"container-overrides": map[string]interface{}{"securityContext": map[string]int64{"runAsGroup": 3000}},
+							}, nil),
+						},
+						{
+							Name: "init",
+							ComponentUnion: v1.ComponentUnion{
+								Container: &v1.ContainerComponent{
+									Container: v1.Container{
+										Image:        "an-init-image",
+										MountSources: pointer.Bool(false),
+									},
+								},
+							},
+							Attributes: attributes.Attributes{}.FromMap(map[string]interface{}{
+								"container-overrides": map[string]interface{}{"securityContext": map[string]int64{"runAsUser": 1001}},
+							}, nil),
+						},
+					}
+					commands := []v1alpha2.Command{
+						{
+							Id: "prestart",
+							CommandUnion: v1.CommandUnion{
+								Apply: &v1.ApplyCommand{Component: "init"},
+							},
+						},
+					}
+					events := v1alpha2.Events{
+						DevWorkspaceEvents: v1alpha2.DevWorkspaceEvents{
+							PreStart: []string{"prestart"},
+						},
+					}
+					mockDevfileData := data.NewMockDevfileData(ctrl)
+					mockDevfileData.EXPECT().GetComponents(gomock.Any()).Return(containers, nil).AnyTimes()
+					mockDevfileData.EXPECT().GetDevfileContainerComponents(gomock.Any()).Return(containers, nil).AnyTimes()
+					mockDevfileData.EXPECT().GetCommands(gomock.Any()).Return(commands, nil).AnyTimes()
+					mockDevfileData.EXPECT().GetEvents().Return(events).AnyTimes()
+					mockDevfileData.EXPECT().GetProjects(gomock.Any()).Return(nil, nil).AnyTimes()
+					mockDevfileData.EXPECT().GetAttributes().Return(attributes.Attributes{}, nil)
+					mockDevfileData.EXPECT().GetSchemaVersion().Return("2.1.0").AnyTimes()
+					mockDevfileData.EXPECT().GetMetadata().Return(devfilepkg.DevfileMetadata{}).AnyTimes()
+					return parser.DevfileObj{
+						Data: mockDevfileData,
+					}
+				},
+			},
+			want: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "main",
+							Image:           "an-image",
+							ImagePullPolicy: corev1.PullAlways,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsGroup: pointer.Int64(3000),
+							},
+						},
+					},
+					InitContainers: []corev1.Container{
+						{
+							Name:            "init-prestart-1",
+							Image:           "an-init-image",
+							ImagePullPolicy: corev1.PullAlways,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser: pointer.Int64(1001),
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1996,3 +2094,53 @@ func TestGetPodTemplateSpec(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPodGroups(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", "manifests/devfile-dedicated.yaml")})
+	groups, err := GetPodGroups(devfileObj, PodTemplateParams{})
+	if err != nil {
+		t.Fatalf("GetPodGroups() unexpected error: %v", err)
+	}
+
+	type podGroup struct {
+		Component      string
+		Components     []string
+		Commands       []string
+		Containers     []string
+		InitContainers []string
+	}
+	containerNames := func(containers []corev1.Container) []string {
+		var names []string
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+	var got []podGroup
+	for _, group := range groups {
+		got = append(got, podGroup{
+			Component:      group.Component,
+			Components:     group.Components,
+			Commands:       group.Commands,
+			Containers:     containerNames(group.PodTemplateSpec.Spec.Containers),
+			InitContainers: containerNames(group.PodTemplateSpec.Spec.InitContainers),
+		})
+	}
+	want := []podGroup{
+		{
+			Components: []string{"web"},
+			Commands:   []string{"run"},
+			Containers: []string{"web"},
+		},
+		{
+			Component:      "db",
+			Components:     []string{"db"},
+			Commands:       []string{"init-db", "migrate"},
+			Containers:     []string{"db"},
+			InitContainers: []string{"db-init-db-1"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetPodGroups() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,46 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+// parseTestDevfile parses the devfile read from the given arguments, such as its content or its path
+// under the testdata directory. The devfile is parsed from its path when its parent has a relative URI.
+func parseTestDevfile(t *testing.T, args parser.ParserArgs) parser.DevfileObj {
+	devfileObj, err := parser.ParseDevfile(args)
+	if err != nil {
+		t.Fatalf("failed to parse devfile: %v", err)
+	}
+	return devfileObj
+}
+
+// assertGolden compares the content with the content of the golden file at the given path, relative to the testdata directory
+func assertGolden(t *testing.T, goldenPath string, got []byte) {
+	want, err := os.ReadFile(filepath.Join("testdata", goldenPath))
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("mismatch with golden file %s (-want +got):\n%s", goldenPath, diff)
+	}
+}
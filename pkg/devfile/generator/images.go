@@ -0,0 +1,97 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Attributes configuring how the images of the containers are pulled. ImagePullPolicyAttribute is set on a
+// container component, ImagePullSecretsAttribute on the devfile, for all the pods.
+//
+//	attributes:
+//	  image-pull-secrets: [registry-credentials]
+//	components:
+//	  - name: web
+//	    attributes:
+//	      image-pull-policy: IfNotPresent
+const (
+	// ImagePullPolicyAttribute is the pull policy of the image of a container component: Always, IfNotPresent or Never
+	ImagePullPolicyAttribute = "image-pull-policy"
+	// ImagePullSecretsAttribute is the list of the names of the secrets used to pull the images of the pods
+	ImagePullSecretsAttribute = "image-pull-secrets"
+)
+
+// getImagePullPolicy returns the pull policy of the image of a container component, set by its ImagePullPolicyAttribute
+// attribute, or else defaultPullPolicy. If neither is set, an image pinned by digest is pulled if it is not present,
+// as it cannot change, and any other image is always pulled.
+func getImagePullPolicy(comp v1.Component, defaultPullPolicy corev1.PullPolicy) (corev1.PullPolicy, error) {
+	pullPolicy := defaultPullPolicy
+	if comp.Attributes.Exists(ImagePullPolicyAttribute) {
+		var attributePolicy string
+		if err := comp.Attributes.GetInto(ImagePullPolicyAttribute, &attributePolicy); err != nil {
+			return "", fmt.Errorf("invalid %s attribute of component %s: %w", ImagePullPolicyAttribute, comp.Name, err)
+		}
+		pullPolicy = corev1.PullPolicy(attributePolicy)
+	}
+	switch pullPolicy {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return pullPolicy, nil
+	case "":
+		if comp.Container != nil && strings.Contains(comp.Container.Image, "@") {
+			return corev1.PullIfNotPresent, nil
+		}
+		return corev1.PullAlways, nil
+	default:
+		return "", fmt.Errorf("invalid image pull policy %s of component %s, expected %s, %s or %s",
+			pullPolicy, comp.Name, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+	}
+}
+
+// getImagePullSecrets returns the given image pull secrets, followed by the secrets of the ImagePullSecretsAttribute
+// attribute of the devfile which are not already given
+func getImagePullSecrets(globalAttributes attributes.Attributes, secrets []corev1.LocalObjectReference) ([]corev1.LocalObjectReference, error) {
+	var secretNames []string
+	if globalAttributes.Exists(ImagePullSecretsAttribute) {
+		if err := globalAttributes.GetInto(ImagePullSecretsAttribute, &secretNames); err != nil {
+			return nil, fmt.Errorf("invalid %s attribute: %w", ImagePullSecretsAttribute, err)
+		}
+	}
+
+	var result []corev1.LocalObjectReference
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if !seen[secret.Name] {
+			seen[secret.Name] = true
+			result = append(result, secret)
+		}
+	}
+	for _, name := range secretNames {
+		if name == "" {
+			return nil, fmt.Errorf("invalid %s attribute: a secret name cannot be empty", ImagePullSecretsAttribute)
+		}
+		if !seen[name] {
+			seen[name] = true
+			result = append(result, corev1.LocalObjectReference{Name: name})
+		}
+	}
+	return result, nil
+}
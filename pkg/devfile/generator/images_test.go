@@ -0,0 +1,117 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestImagePullPolicy(t *testing.T) {
+	tests := []struct {
+		name              string
+		attributes        string
+		image             string
+		defaultPullPolicy corev1.PullPolicy
+		wantPullPolicy    corev1.PullPolicy
+		wantErr           string
+	}{
+		{
+			name:           "tagged image",
+			image:          "web:latest",
+			wantPullPolicy: corev1.PullAlways,
+		},
+		{
+			name:           "image pinned by digest",
+			image:          "web@sha256:4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c",
+			wantPullPolicy: corev1.PullIfNotPresent,
+		},
+		{
+			name:              "default pull policy",
+			image:             "web@sha256:4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3a4b5c",
+			defaultPullPolicy: corev1.PullNever,
+			wantPullPolicy:    corev1.PullNever,
+		},
+		{
+			name: "attribute overriding the default pull policy",
+			attributes: `    attributes:
+      image-pull-policy: IfNotPresent
+`,
+			image:             "web:latest",
+			defaultPullPolicy: corev1.PullAlways,
+			wantPullPolicy:    corev1.PullIfNotPresent,
+		},
+		{
+			name: "invalid pull policy",
+			attributes: `    attributes:
+      image-pull-policy: Sometimes
+`,
+			image:   "web:latest",
+			wantErr: "invalid image pull policy Sometimes of component web, expected Always, IfNotPresent or Never",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: images
+components:
+  - name: web
+` + tt.attributes + `    container:
+      image: ` + tt.image + `
+`)})
+			podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{ImagePullPolicy: tt.defaultPullPolicy})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetPodTemplateSpec() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+			}
+			if got := podTemplateSpec.Spec.Containers[0].ImagePullPolicy; got != tt.wantPullPolicy {
+				t.Errorf("GetPodTemplateSpec() image pull policy = %s, want %s", got, tt.wantPullPolicy)
+			}
+		})
+	}
+}
+
+func TestImagePullSecrets(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: images
+attributes:
+  image-pull-secrets: [registry, mirror]
+components:
+  - name: web
+    container:
+      image: web
+`)})
+	podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror"}, {Name: "cache"}},
+	})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+	}
+	want := []corev1.LocalObjectReference{{Name: "mirror"}, {Name: "cache"}, {Name: "registry"}}
+	if diff := cmp.Diff(want, podTemplateSpec.Spec.ImagePullSecrets); diff != "" {
+		t.Errorf("GetPodTemplateSpec() image pull secrets mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,301 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Attributes of the public endpoints configuring their Ingress
+const (
+	// IngressHostAttribute is the host routed to the endpoint. The public endpoints with the same host are routed by
+	// a single Ingress, on their paths.
+	IngressHostAttribute = "ingress-host"
+	// IngressPathTypeAttribute is the pathType of the endpoint path: Exact, Prefix or ImplementationSpecific.
+	// Defaults to ImplementationSpecific.
+	IngressPathTypeAttribute = "ingress-path-type"
+	// IngressClassAttribute is the ingressClassName of the Ingress routing the endpoint
+	IngressClassAttribute = "ingress-class"
+	// IngressTLSSecretAttribute is the name of the TLS Secret used for the host of a secure endpoint
+	IngressTLSSecretAttribute = "ingress-tls-secret"
+)
+
+// IngressesParams is a struct that contains the required data to create the Ingresses routing the public endpoints
+type IngressesParams struct {
+	TypeMeta metav1.TypeMeta
+	// ObjectMeta is the metadata of the Ingresses. Each Ingress is named <ObjectMeta.Name>-<endpoint name>, after the
+	// first endpoint it routes, by name.
+	ObjectMeta metav1.ObjectMeta
+	// EndpointServices is the name of the Service exposing each endpoint, indexed by endpoint name, as returned by
+	// GetEndpointServices. Endpoints without a Service are not routed.
+	EndpointServices map[string]string
+	// IngressDomain is the domain used to compute the host of the endpoints without IngressHostAttribute,
+	// as <ObjectMeta.Name>-<endpoint name>.<IngressDomain>. If empty, these endpoints are routed without host.
+	IngressDomain string
+	// IngressClassName is the ingressClassName of the Ingresses, unless set by IngressClassAttribute
+	IngressClassName string
+	// TLSSecretName is the name of the TLS Secret used for the hosts of secure endpoints, unless set by
+	// IngressTLSSecretAttribute. If empty, the default certificate of the ingress controller is used.
+	TLSSecretName string
+}
+
+// publicEndpoint is a public endpoint of a container component, with the name of the Service exposing it
+type publicEndpoint struct {
+	v1.Endpoint
+	component   string
+	serviceName string
+}
+
+// GetIngresses returns the networking v1 Ingresses routing the public endpoints of the container components.
+// A single Ingress is returned for each host, set by IngressHostAttribute or computed from the domain, with a path for
+// each endpoint. Without domain, the endpoints without IngressHostAttribute are routed by a single Ingress without host.
+// The hosts of secure endpoints are served over TLS.
+// The endpoint annotations are set on the Ingress routing them.
+func GetIngresses(devfileObj parser.DevfileObj, ingressesParams IngressesParams, options common.DevfileOptions) ([]*networkingv1.Ingress, error) {
+	endpoints, err := getPublicEndpoints(devfileObj, options, ingressesParams.EndpointServices)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts, err := groupEndpointsByHost(ingressesParams.ObjectMeta.Name, ingressesParams.IngressDomain, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	var ingresses []*networkingv1.Ingress
+	for _, h := range hosts {
+		ingress, err := getHostIngress(ingressesParams, h)
+		if err != nil {
+			return nil, err
+		}
+		ingresses = append(ingresses, ingress)
+	}
+	return ingresses, nil
+}
+
+// hostEndpoints are the public endpoints routed for a host
+type hostEndpoints struct {
+	// name is the name of the objects routing the host, after its first endpoint
+	name string
+	// host is set by IngressHostAttribute or computed from the domain. It is empty if the endpoints are routed for any host.
+	host      string
+	endpoints []publicEndpoint
+}
+
+// groupEndpointsByHost groups the endpoints routed for the same host, in the order of their first endpoint.
+// The host of an endpoint is set by IngressHostAttribute, or is <baseName>-<endpoint name>.<domain>. Without domain,
+// the endpoints without IngressHostAttribute are all routed for any host, and are grouped together.
+func groupEndpointsByHost(baseName, domain string, endpoints []publicEndpoint) ([]hostEndpoints, error) {
+	var hosts []hostEndpoints
+	// hostIndexes is the index of each host in hosts
+	hostIndexes := make(map[string]int)
+	for _, endpoint := range endpoints {
+		var host string
+		switch {
+		case endpoint.Attributes.Exists(IngressHostAttribute):
+			var err error
+			if host, err = getEndpointStringAttribute(endpoint.Endpoint, IngressHostAttribute); err != nil {
+				return nil, err
+			}
+		case domain != "":
+			host = fmt.Sprintf("%s.%s", getResourceName(baseName, endpoint.Name), domain)
+		}
+		i, ok := hostIndexes[host]
+		if !ok {
+			i = len(hosts)
+			hostIndexes[host] = i
+			hosts = append(hosts, hostEndpoints{name: getResourceName(baseName, endpoint.Name), host: host})
+		}
+		hosts[i].endpoints = append(hosts[i].endpoints, endpoint)
+	}
+	return hosts, nil
+}
+
+// getHostAnnotations returns the annotations of the endpoints of a host, which must not have different values
+func getHostAnnotations(host string, endpoints []publicEndpoint) (map[string]string, error) {
+	annotations := make(map[string]string)
+	// annotationEndpoints is the endpoint setting each annotation
+	annotationEndpoints := make(map[string]string)
+	for _, endpoint := range endpoints {
+		for key, value := range endpoint.Annotations {
+			if other, ok := annotations[key]; ok && other != value {
+				return nil, fmt.Errorf("endpoints %s and %s of host %q have different values for the annotation %s",
+					annotationEndpoints[key], endpoint.Name, host, key)
+			}
+			annotations[key] = value
+			annotationEndpoints[key] = endpoint.Name
+		}
+	}
+	return annotations, nil
+}
+
+// getHostIngress returns the Ingress routing the endpoints of a host
+func getHostIngress(ingressesParams IngressesParams, h hostEndpoints) (*networkingv1.Ingress, error) {
+	objectMeta := *ingressesParams.ObjectMeta.DeepCopy()
+	objectMeta.Name = h.name
+	host, endpoints := h.host, h.endpoints
+	annotations, err := getHostAnnotations(host, endpoints)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta.Annotations = mergeMaps(objectMeta.Annotations, annotations)
+
+	var paths []networkingv1.HTTPIngressPath
+	// routedPaths is the endpoint routing each path and pathType of the host
+	routedPaths := make(map[string]string)
+	var ingressClassName, tlsSecretName, ingressClassEndpoint, tlsSecretEndpoint string
+	secure := false
+	for _, endpoint := range endpoints {
+		path := endpoint.Path
+		if path == "" {
+			path = "/"
+		}
+		pathType, err := getEndpointPathType(endpoint.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		routedPath := string(pathType) + ":" + path
+		if other, ok := routedPaths[routedPath]; ok {
+			return nil, fmt.Errorf("endpoints %s and %s both route the path %s of host %q", other, endpoint.Name, path, host)
+		}
+		routedPaths[routedPath] = endpoint.Name
+		paths = append(paths, networkingv1.HTTPIngressPath{
+			Path:     path,
+			PathType: &pathType,
+			Backend: networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: endpoint.serviceName,
+					Port: networkingv1.ServiceBackendPort{
+						Number: int32(endpoint.TargetPort),
+					},
+				},
+			},
+		})
+
+		className := ingressesParams.IngressClassName
+		if endpoint.Attributes.Exists(IngressClassAttribute) {
+			if className, err = getEndpointStringAttribute(endpoint.Endpoint, IngressClassAttribute); err != nil {
+				return nil, err
+			}
+		}
+		if ingressClassEndpoint != "" && className != ingressClassName {
+			return nil, fmt.Errorf("endpoints %s and %s of host %q have different ingress classes %q and %q",
+				ingressClassEndpoint, endpoint.Name, host, ingressClassName, className)
+		}
+		ingressClassName, ingressClassEndpoint = className, endpoint.Name
+
+		if endpoint.Secure != nil && *endpoint.Secure {
+			secure = true
+			secretName := ingressesParams.TLSSecretName
+			if endpoint.Attributes.Exists(IngressTLSSecretAttribute) {
+				if secretName, err = getEndpointStringAttribute(endpoint.Endpoint, IngressTLSSecretAttribute); err != nil {
+					return nil, err
+				}
+			}
+			if tlsSecretEndpoint != "" && secretName != tlsSecretName {
+				return nil, fmt.Errorf("endpoints %s and %s of host %q have different TLS Secrets %q and %q",
+					tlsSecretEndpoint, endpoint.Name, host, tlsSecretName, secretName)
+			}
+			tlsSecretName, tlsSecretEndpoint = secretName, endpoint.Name
+		}
+	}
+
+	spec := networkingv1.IngressSpec{
+		Rules: []networkingv1.IngressRule{
+			{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{
+					HTTP: &networkingv1.HTTPIngressRuleValue{Paths: paths},
+				},
+			},
+		},
+	}
+	if ingressClassName != "" {
+		spec.IngressClassName = &ingressClassName
+	}
+	if secure {
+		tls := networkingv1.IngressTLS{SecretName: tlsSecretName}
+		if host != "" {
+			tls.Hosts = []string{host}
+		}
+		spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	return &networkingv1.Ingress{
+		TypeMeta:   ingressesParams.TypeMeta,
+		ObjectMeta: objectMeta,
+		Spec:       spec,
+	}, nil
+}
+
+// getEndpointPathType returns the pathType of the endpoint path, set by IngressPathTypeAttribute
+func getEndpointPathType(endpoint v1.Endpoint) (networkingv1.PathType, error) {
+	if !endpoint.Attributes.Exists(IngressPathTypeAttribute) {
+		return networkingv1.PathTypeImplementationSpecific, nil
+	}
+	pathType, err := getEndpointStringAttribute(endpoint, IngressPathTypeAttribute)
+	if err != nil {
+		return "", err
+	}
+	switch networkingv1.PathType(pathType) {
+	case networkingv1.PathTypeExact, networkingv1.PathTypePrefix, networkingv1.PathTypeImplementationSpecific:
+		return networkingv1.PathType(pathType), nil
+	default:
+		return "", fmt.Errorf("endpoint %s: unsupported path type %q", endpoint.Name, pathType)
+	}
+}
+
+// getEndpointStringAttribute returns the value of a string attribute of the endpoint
+func getEndpointStringAttribute(endpoint v1.Endpoint, key string) (string, error) {
+	var err error
+	value := endpoint.Attributes.GetString(key, &err)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s attribute of endpoint %s: %w", key, endpoint.Name, err)
+	}
+	return value, nil
+}
+
+// getPublicEndpoints returns the public endpoints of the container components exposed by a Service, sorted by name.
+// serviceNames maps the endpoints to the name of the Service exposing them.
+func getPublicEndpoints(devfileObj parser.DevfileObj, options common.DevfileOptions, serviceNames map[string]string) ([]publicEndpoint, error) {
+	options.ComponentOptions = common.ComponentOptions{ComponentType: v1.ContainerComponentType}
+	containerComponents, err := devfileObj.Data.GetComponents(options)
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []publicEndpoint
+	for _, comp := range containerComponents {
+		for _, endpoint := range comp.Container.Endpoints {
+			serviceName, ok := serviceNames[endpoint.Name]
+			if !ok {
+				continue
+			}
+			if endpoint.Exposure == v1.PublicEndpointExposure || endpoint.Exposure == "" {
+				endpoints = append(endpoints, publicEndpoint{Endpoint: endpoint, component: comp.Name, serviceName: serviceName})
+			}
+		}
+	}
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		return endpoints[i].Name < endpoints[j].Name
+	})
+	return endpoints, nil
+}
@@ -0,0 +1,246 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/google/go-cmp/cmp"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const ingressesDevfileHeader = `schemaVersion: 2.2.0
+metadata:
+  name: ingresses
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+`
+
+// ingressPath returns an Ingress path routed to the port of the app Service
+func ingressPath(path string, pathType networkingv1.PathType, port int32) networkingv1.HTTPIngressPath {
+	return networkingv1.HTTPIngressPath{
+		Path:     path,
+		PathType: &pathType,
+		Backend: networkingv1.IngressBackend{
+			Service: &networkingv1.IngressServiceBackend{
+				Name: "app",
+				Port: networkingv1.ServiceBackendPort{Number: port},
+			},
+		},
+	}
+}
+
+func TestGetIngresses(t *testing.T) {
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+	tests := []struct {
+		name          string
+		endpoints     string
+		params        IngressesParams
+		wantIngresses []*networkingv1.Ingress
+		wantErr       string
+	}{
+		{
+			name: "endpoints without host",
+			endpoints: `        - name: http
+          targetPort: 8080
+          path: /api
+        - name: debug
+          targetPort: 5858
+          exposure: internal
+`,
+			wantIngresses: []*networkingv1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-http"},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+								ingressPath("/api", implementationSpecific, 8080),
+							}},
+						}}},
+					},
+				},
+			},
+		},
+		{
+			name: "endpoints sharing a host",
+			endpoints: `        - name: http
+          targetPort: 8080
+          path: /
+          annotation:
+            nginx.ingress.kubernetes.io/proxy-body-size: 8m
+          attributes:
+            ingress-host: app.example.com
+            ingress-path-type: Prefix
+        - name: api
+          targetPort: 8081
+          path: /api
+          secure: true
+          attributes:
+            ingress-host: app.example.com
+            ingress-path-type: Prefix
+            ingress-tls-secret: app-tls
+        - name: admin
+          targetPort: 8443
+          secure: true
+          attributes:
+            ingress-class: internal
+`,
+			params: IngressesParams{
+				IngressDomain:    "apps.example.com",
+				IngressClassName: "public",
+			},
+			wantIngresses: []*networkingv1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-admin"},
+					Spec: networkingv1.IngressSpec{
+						IngressClassName: pointer.String("internal"),
+						Rules: []networkingv1.IngressRule{{
+							Host: "app-admin.apps.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+									ingressPath("/", implementationSpecific, 8443),
+								}},
+							},
+						}},
+						TLS: []networkingv1.IngressTLS{{Hosts: []string{"app-admin.apps.example.com"}}},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "app-api",
+						Annotations: map[string]string{"nginx.ingress.kubernetes.io/proxy-body-size": "8m"},
+					},
+					Spec: networkingv1.IngressSpec{
+						IngressClassName: pointer.String("public"),
+						Rules: []networkingv1.IngressRule{{
+							Host: "app.example.com",
+							IngressRuleValue: networkingv1.IngressRuleValue{
+								HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+									ingressPath("/api", networkingv1.PathTypePrefix, 8081),
+									ingressPath("/", networkingv1.PathTypePrefix, 8080),
+								}},
+							},
+						}},
+						TLS: []networkingv1.IngressTLS{{Hosts: []string{"app.example.com"}, SecretName: "app-tls"}},
+					},
+				},
+			},
+		},
+		{
+			name: "endpoints without host routed for any host",
+			endpoints: `        - name: http
+          targetPort: 8080
+          path: /api
+        - name: api
+          targetPort: 8081
+          path: /v2
+`,
+			wantIngresses: []*networkingv1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "app-api"},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{
+								ingressPath("/v2", implementationSpecific, 8081),
+								ingressPath("/api", implementationSpecific, 8080),
+							}},
+						}}},
+					},
+				},
+			},
+		},
+		{
+			name: "same path of endpoints without host",
+			endpoints: `        - name: http
+          targetPort: 8080
+        - name: api
+          targetPort: 8081
+`,
+			wantErr: `endpoints api and http both route the path / of host ""`,
+		},
+		{
+			name: "same path of a host",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            ingress-host: app.example.com
+        - name: api
+          targetPort: 8081
+          attributes:
+            ingress-host: app.example.com
+`,
+			wantErr: `endpoints api and http both route the path / of host "app.example.com"`,
+		},
+		{
+			name: "different ingress classes of a host",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            ingress-host: app.example.com
+        - name: api
+          targetPort: 8081
+          path: /api
+          attributes:
+            ingress-host: app.example.com
+            ingress-class: internal
+`,
+			params:  IngressesParams{IngressClassName: "public"},
+			wantErr: `endpoints api and http of host "app.example.com" have different ingress classes "internal" and "public"`,
+		},
+		{
+			name: "unsupported path type",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            ingress-path-type: Regex
+`,
+			wantErr: `endpoint http: unsupported path type "Regex"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(ingressesDevfileHeader + tt.endpoints)})
+			params := tt.params
+			params.ObjectMeta = metav1.ObjectMeta{Name: "app"}
+			params.EndpointServices = map[string]string{"http": "app", "api": "app", "admin": "app", "debug": "app"}
+			ingresses, err := GetIngresses(devfileObj, params, common.DevfileOptions{})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetIngresses() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetIngresses() unexpected error: %v", err)
+			}
+			for _, ingress := range ingresses {
+				if len(ingress.Annotations) == 0 {
+					ingress.Annotations = nil
+				}
+			}
+			if diff := cmp.Diff(tt.wantIngresses, ingresses); diff != "" {
+				t.Errorf("GetIngresses() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
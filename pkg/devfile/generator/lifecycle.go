@@ -0,0 +1,186 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	postStartEvent = "postStart"
+	preStopEvent   = "preStop"
+)
+
+// shellCommand is the command running the generated shell scripts, such as the scripts of the lifecycle hooks
+var shellCommand = []string{"/bin/sh", "-c"}
+
+// getLifecycles returns the lifecycle of the container components targeted by the postStart and preStop events,
+// indexed by component name. The exec commands of the postStart events are chained into the postStart hook
+// of their container, and the exec commands of the preStop events into the preStop hook.
+//
+// An error is returned for the events which cannot be expressed as lifecycle hooks: apply commands, composite commands
+// running commands in several containers, and commands running in containers only run by preStart and postStop events.
+func getLifecycles(devfileObj parser.DevfileObj) (map[string]*corev1.Lifecycle, error) {
+	events := devfileObj.Data.GetEvents()
+	lifecycles := make(map[string]*corev1.Lifecycle)
+	if len(events.PostStart) == 0 && len(events.PreStop) == 0 {
+		return lifecycles, nil
+	}
+
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	commandsMap := common.GetCommandsMap(commands)
+	containers, err := GetContainers(devfileObj, common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[string]bool)
+	for _, container := range containers {
+		running[container.Name] = true
+	}
+
+	postStartHooks, err := getEventHooks(commandsMap, running, postStartEvent, events.PostStart)
+	if err != nil {
+		return nil, err
+	}
+	for component, handler := range postStartHooks {
+		lifecycles[component] = &corev1.Lifecycle{PostStart: handler}
+	}
+	preStopHooks, err := getEventHooks(commandsMap, running, preStopEvent, events.PreStop)
+	if err != nil {
+		return nil, err
+	}
+	for component, handler := range preStopHooks {
+		if lifecycles[component] == nil {
+			lifecycles[component] = &corev1.Lifecycle{}
+		}
+		lifecycles[component].PreStop = handler
+	}
+	return lifecycles, nil
+}
+
+// getEventHooks returns the hook handlers running the commands of the event, indexed by component name.
+// The commands running in the same container are chained in the order of the event.
+func getEventHooks(commandsMap map[string]v1.Command, running map[string]bool, eventName string, commandIds []string) (map[string]*corev1.LifecycleHandler, error) {
+	scripts := make(map[string][]string)
+	for _, commandId := range commandIds {
+		component, script, err := getHookScript(commandsMap, eventName, commandId)
+		if err != nil {
+			return nil, err
+		}
+		if script == "" {
+			continue
+		}
+		if !running[component] {
+			return nil, fmt.Errorf("%s event: command %s runs in component %s, which is only run by preStart and postStop events", eventName, commandId, component)
+		}
+		scripts[component] = append(scripts[component], script)
+	}
+
+	handlers := make(map[string]*corev1.LifecycleHandler)
+	for component, componentScripts := range scripts {
+		handlers[component] = &corev1.LifecycleHandler{
+			Exec: &corev1.ExecAction{
+				Command: append(append([]string{}, shellCommand...), chainScripts(componentScripts)),
+			},
+		}
+	}
+	return handlers, nil
+}
+
+// getHookScript returns the component in which the command runs, and the shell script running the command.
+// The commands of a composite command are chained sequentially, even if the composite command is parallel.
+func getHookScript(commandsMap map[string]v1.Command, eventName string, commandId string) (string, string, error) {
+	command, ok := commandsMap[commandId]
+	if !ok {
+		return "", "", fmt.Errorf("%s event: command %s is not defined", eventName, commandId)
+	}
+	switch {
+	case command.Exec != nil:
+		return command.Exec.Component, getExecScript(command.Exec), nil
+	case command.Composite != nil:
+		var component string
+		var scripts []string
+		for _, subCommandId := range command.Composite.Commands {
+			subComponent, script, err := getHookScript(commandsMap, eventName, subCommandId)
+			if err != nil {
+				return "", "", err
+			}
+			if script == "" {
+				continue
+			}
+			if component != "" && subComponent != component {
+				return "", "", fmt.Errorf("%s event: composite command %s runs commands in the containers %s and %s, which cannot be chained in a lifecycle hook",
+					eventName, commandId, component, subComponent)
+			}
+			component = subComponent
+			scripts = append(scripts, script)
+		}
+		return component, chainScripts(scripts), nil
+	case command.Apply != nil:
+		return "", "", fmt.Errorf("%s event: apply command %s cannot run as a lifecycle hook", eventName, commandId)
+	default:
+		return "", "", fmt.Errorf("%s event: command %s cannot run as a lifecycle hook, only exec and composite commands are supported", eventName, commandId)
+	}
+}
+
+// getExecScript returns the shell script running the command line of the exec command in its working directory,
+// with its environment variables
+func getExecScript(exec *v1.ExecCommand) string {
+	var parts []string
+	if exec.WorkingDir != "" {
+		// the working directory is double quoted, so that the references to environment variables such as
+		// ${PROJECT_SOURCE} are expanded by the shell
+		parts = append(parts, "cd "+shellDoubleQuote(exec.WorkingDir))
+	}
+	for _, env := range exec.Env {
+		parts = append(parts, fmt.Sprintf("export %s=%s", env.Name, shellSingleQuote(env.Value)))
+	}
+	parts = append(parts, exec.CommandLine)
+	return strings.Join(parts, " && ")
+}
+
+// chainScripts chains the scripts sequentially, running each script in a subshell so that they don't share
+// their working directory and environment variables
+func chainScripts(scripts []string) string {
+	if len(scripts) == 1 {
+		return scripts[0]
+	}
+	subshells := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		subshells = append(subshells, "("+script+")")
+	}
+	return strings.Join(subshells, " && ")
+}
+
+// shellSingleQuote quotes s so that the shell reads it literally
+func shellSingleQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'"
+}
+
+// shellDoubleQuote quotes s so that the shell only expands the references to variables it contains
+func shellDoubleQuote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "`", "\\`")
+	return `"` + replacer.Replace(s) + `"`
+}
@@ -0,0 +1,178 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const lifecycleDevfileHeader = `schemaVersion: 2.2.0
+metadata:
+  name: lifecycle
+components:
+  - name: runtime
+    container:
+      image: node
+  - name: tools
+    container:
+      image: tools
+  - name: setup
+    container:
+      image: setup
+  - name: build
+    image:
+      imageName: app
+      dockerfile:
+        uri: Dockerfile
+`
+
+func TestGetPodTemplateSpecLifecycleHooks(t *testing.T) {
+	devfile := lifecycleDevfileHeader + `commands:
+  - id: install
+    exec:
+      component: runtime
+      commandLine: npm install
+      workingDir: ${PROJECT_SOURCE}/app
+      env:
+        - name: NODE_ENV
+          value: it's dev
+  - id: warm
+    exec:
+      component: runtime
+      commandLine: npm run warm; echo done
+  - id: init
+    composite:
+      commands: [install, warm]
+      parallel: true
+  - id: notify
+    exec:
+      component: tools
+      commandLine: ./notify.sh
+  - id: drain
+    exec:
+      component: runtime
+      commandLine: npm run drain
+  - id: prepare
+    apply:
+      component: setup
+events:
+  preStart: [prepare]
+  postStart: [init, notify]
+  preStop: [drain]
+`
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(devfile)})
+	podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{LifecycleHooks: true})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+	}
+	got := make(map[string]*corev1.Lifecycle)
+	for _, container := range podTemplateSpec.Spec.Containers {
+		got[container.Name] = container.Lifecycle
+	}
+	want := map[string]*corev1.Lifecycle{
+		"runtime": {
+			PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{
+				"/bin/sh", "-c", `(cd "${PROJECT_SOURCE}/app" && export NODE_ENV='it'"'"'s dev' && npm install) && (npm run warm; echo done)`,
+			}}},
+			PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "npm run drain"}}},
+		},
+		"tools": {
+			PostStart: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "./notify.sh"}}},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetPodTemplateSpec() lifecycles mismatch (-want +got):\n%s", diff)
+	}
+
+	// lifecycle hooks are opt-in
+	podTemplateSpec, err = GetPodTemplateSpec(devfileObj, PodTemplateParams{})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+	}
+	for _, container := range podTemplateSpec.Spec.Containers {
+		if container.Lifecycle != nil {
+			t.Errorf("GetPodTemplateSpec() unexpected lifecycle for container %s without LifecycleHooks", container.Name)
+		}
+	}
+}
+
+func TestGetPodTemplateSpecLifecycleHooksErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		commands string
+		wantErr  string
+	}{
+		{
+			name: "apply command",
+			commands: `commands:
+  - id: build-image
+    apply:
+      component: build
+events:
+  postStart: [build-image]
+`,
+			wantErr: "postStart event: apply command build-image cannot run as a lifecycle hook",
+		},
+		{
+			name: "composite command in several containers",
+			commands: `commands:
+  - id: a
+    exec:
+      component: runtime
+      commandLine: a
+  - id: b
+    exec:
+      component: tools
+      commandLine: b
+  - id: both
+    composite:
+      commands: [a, b]
+events:
+  preStop: [both]
+`,
+			wantErr: "preStop event: composite command both runs commands in the containers runtime and tools, which cannot be chained in a lifecycle hook",
+		},
+		{
+			name: "container only run by events",
+			commands: `commands:
+  - id: prepare
+    apply:
+      component: setup
+  - id: hello
+    exec:
+      component: setup
+      commandLine: echo hello
+events:
+  preStart: [prepare]
+  postStart: [hello]
+`,
+			wantErr: "postStart event: command hello runs in component setup, which is only run by preStart and postStop events",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(lifecycleDevfileHeader + tt.commands)})
+			_, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{LifecycleHooks: true})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("GetPodTemplateSpec() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
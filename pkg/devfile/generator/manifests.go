@@ -0,0 +1,862 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/devfile/library/v2/pkg/util"
+	routev1 "github.com/openshift/api/route/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	psaapi "k8s.io/pod-security-admission/api"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+	"sigs.k8s.io/yaml"
+)
+
+// Labels set on the objects generated by GenerateManifests
+const (
+	// NameLabel is set to the name of the workload the object belongs to
+	NameLabel = "app.kubernetes.io/name"
+	// InstanceLabel is set to the base name of the generated objects, i.e. ManifestsOptions.Name
+	InstanceLabel = "app.kubernetes.io/instance"
+	// ManagedByLabel is set to ManagedByLabelValue
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	ManagedByLabelValue = "devfile"
+
+	// DefaultVolumeSize is the size of the PVCs created for volume components without any size
+	DefaultVolumeSize = "1Gi"
+
+	serviceKind             = "Service"
+	serviceAPIVersion       = "v1"
+	pvcKind                 = "PersistentVolumeClaim"
+	pvcAPIVersion           = "v1"
+	ingressKind             = "Ingress"
+	networkingV1APIVersion  = "networking.k8s.io/v1"
+	networkPolicyKind       = "NetworkPolicy"
+	statefulSetKind         = "StatefulSet"
+	jobKind                 = "Job"
+	cronJobKind             = "CronJob"
+	batchV1APIVersion       = "batch/v1"
+	hpaKind                 = "HorizontalPodAutoscaler"
+	hpaAPIVersion           = "autoscaling/v2"
+	pdbKind                 = "PodDisruptionBudget"
+	pdbAPIVersion           = "policy/v1"
+	routeKind               = "Route"
+	routeAPIVersion         = "route.openshift.io/v1"
+	yamlDocumentSeparator   = "---\n"
+	kubernetesNameMaxLength = 63
+)
+
+// ManifestsOptions is a struct that contains the options to generate manifests from a devfile
+type ManifestsOptions struct {
+	// Name is the base name of all the generated objects. Defaults to the devfile metadata name.
+	Name string
+	// Namespace is set on all the generated objects, and on the Kubernetes component resources not defining any namespace.
+	Namespace string
+	// Labels are added to all the generated objects, in addition to the standard labels.
+	Labels map[string]string
+	// Annotations are added to all the generated objects.
+	Annotations map[string]string
+	// OwnerReferences are set on all the generated objects.
+	// No owner reference is set by default, as the UID of the generated Deployment is only known once it is created.
+	// Callers can either use GetOwnerReference once the Deployment is created, or make all objects owned by an existing object.
+	OwnerReferences []metav1.OwnerReference
+	// Replicas is the number of replicas of the Deployments and StatefulSets
+	Replicas *int32
+	// WorkloadKind is the kind of the workloads running the pods without WorkloadKindAttribute. Defaults to Deployment.
+	WorkloadKind WorkloadKind
+	// UseRoutes generates OpenShift Routes instead of Ingresses for public endpoints
+	UseRoutes bool
+	// RouteHostTemplate is the template of the host of the Routes, unless set by RouteHostAttribute, such as
+	// {endpoint}-{devfileName}.{domain}, as described by ExpandRouteHostTemplate. {domain} is IngressDomain.
+	// If empty, the hosts of the Routes are generated by the router.
+	RouteHostTemplate string
+	// GetSecret returns the Secrets referenced by the RouteTLSSecretAttribute and RouteDestinationCASecretAttribute attributes
+	GetSecret SecretGetter
+	// InlineRouteTLSKeys allows copying the private keys of the Secrets referenced by RouteTLSSecretAttribute into the
+	// Routes, as described by EndpointRouteParams.InlineTLSKey.
+	// WARNING: the keys are then stored in clear in the Routes and in the generated manifests.
+	InlineRouteTLSKeys bool
+	// Gateway, if set, generates Gateway API routes attached to this Gateway instead of Ingresses for public endpoints.
+	// IngressDomain is used to compute their hostnames. It cannot be set with UseRoutes.
+	Gateway *GatewayParent
+	// IngressDomain is the domain used to compute the host of the public endpoints without IngressHostAttribute,
+	// as <name>-<endpoint>.<domain>. If empty, the Ingresses of these endpoints do not set any host.
+	IngressDomain string
+	// IngressClassName is the ingressClassName of the Ingresses, unless set by IngressClassAttribute
+	IngressClassName string
+	// TLSSecretName is the name of the TLS Secret used by the Ingresses of secure endpoints, unless set by IngressTLSSecretAttribute.
+	// If empty, the default certificate of the ingress controller is used.
+	TLSSecretName string
+	// PodSecurityAdmissionPolicy is the policy to be respected by the generated pods
+	PodSecurityAdmissionPolicy psaapi.Policy
+	// PodSecurityAdmissionDryRun reports the violations of PodSecurityAdmissionPolicy in the PolicyReport of the
+	// pod groups without patching the pods
+	PodSecurityAdmissionDryRun bool
+	// LifecycleHooks turns the exec commands of the postStart and preStop events into lifecycle hooks of the containers
+	LifecycleHooks bool
+	// ProjectsVolume, if set, adds the volume holding the projects to the pods, mounted in the containers with mountSources
+	ProjectsVolume *ProjectsVolume
+	// Probes adds probes to the containers, generated from the endpoints of their components as configured by ProbeAttribute
+	Probes bool
+	// ImagePullPolicy is the pull policy of the images of the container components without ImagePullPolicyAttribute.
+	// If empty, the images pinned by digest are pulled if not present, and the other images are always pulled.
+	ImagePullPolicy corev1.PullPolicy
+	// ImagePullSecrets are the secrets used to pull the images, along with the ImagePullSecretsAttribute attribute of the devfile
+	ImagePullSecrets []corev1.LocalObjectReference
+	// ServiceAccountName is the existing ServiceAccount running the pods. It overrides the ServiceAccountAttribute attribute of the devfile.
+	ServiceAccountName string
+	// NetworkPolicies, if set, generates a NetworkPolicy for each workload, allowing these peers to reach the endpoints
+	// as returned by GetNetworkPolicy. If UseRoutes is set, the public peers default to the OpenShift ingress controllers,
+	// and if Gateway is set, to the pods of the namespaces of the parent Gateways.
+	NetworkPolicies *NetworkPolicyPeers
+	// Options filters the devfile components used to generate the manifests
+	Options common.DevfileOptions
+}
+
+// Manifests is the set of objects generated from a devfile
+type Manifests struct {
+	ServiceAccounts          []*corev1.ServiceAccount
+	Roles                    []*rbacv1.Role
+	RoleBindings             []*rbacv1.RoleBinding
+	Deployments              []*appsv1.Deployment
+	StatefulSets             []*appsv1.StatefulSet
+	Jobs                     []*batchv1.Job
+	CronJobs                 []*batchv1.CronJob
+	HorizontalPodAutoscalers []*autoscalingv2.HorizontalPodAutoscaler
+	PodDisruptionBudgets     []*policyv1.PodDisruptionBudget
+	Services                 []*corev1.Service
+	Ingresses                []*networkingv1.Ingress
+	Routes                   []*routev1.Route
+	HTTPRoutes               []*gatewayv1beta1.HTTPRoute
+	GRPCRoutes               []*gatewayv1alpha2.GRPCRoute
+	NetworkPolicies          []*networkingv1.NetworkPolicy
+	PersistentVolumeClaims   []*corev1.PersistentVolumeClaim
+	// KubernetesResources are the resources inlined in the Kubernetes and OpenShift components deployed by default
+	KubernetesResources []*unstructured.Unstructured
+	// PodGroups are the pods run by the workloads, telling which commands run in which pod.
+	// The workload of a pod group is named after it, as described by GenerateManifests.
+	PodGroups []PodGroup
+}
+
+// Objects returns all the generated objects, in the order they should be applied to a cluster
+func (m *Manifests) Objects() []runtime.Object {
+	var objects []runtime.Object
+	for _, serviceAccount := range m.ServiceAccounts {
+		objects = append(objects, serviceAccount)
+	}
+	for _, role := range m.Roles {
+		objects = append(objects, role)
+	}
+	for _, roleBinding := range m.RoleBindings {
+		objects = append(objects, roleBinding)
+	}
+	for _, pvc := range m.PersistentVolumeClaims {
+		objects = append(objects, pvc)
+	}
+	for _, networkPolicy := range m.NetworkPolicies {
+		objects = append(objects, networkPolicy)
+	}
+	for _, deployment := range m.Deployments {
+		objects = append(objects, deployment)
+	}
+	for _, statefulSet := range m.StatefulSets {
+		objects = append(objects, statefulSet)
+	}
+	for _, job := range m.Jobs {
+		objects = append(objects, job)
+	}
+	for _, cronJob := range m.CronJobs {
+		objects = append(objects, cronJob)
+	}
+	for _, hpa := range m.HorizontalPodAutoscalers {
+		objects = append(objects, hpa)
+	}
+	for _, pdb := range m.PodDisruptionBudgets {
+		objects = append(objects, pdb)
+	}
+	for _, service := range m.Services {
+		objects = append(objects, service)
+	}
+	for _, ingress := range m.Ingresses {
+		objects = append(objects, ingress)
+	}
+	for _, route := range m.Routes {
+		objects = append(objects, route)
+	}
+	for _, route := range m.HTTPRoutes {
+		objects = append(objects, route)
+	}
+	for _, route := range m.GRPCRoutes {
+		objects = append(objects, route)
+	}
+	for _, res := range m.KubernetesResources {
+		objects = append(objects, res)
+	}
+	return objects
+}
+
+// ToYAML serializes all the generated objects into a multi-document YAML stream
+func (m *Manifests) ToYAML() ([]byte, error) {
+	return ObjectsToYAML(m.Objects())
+}
+
+// ObjectsToYAML serializes the given objects into a multi-document YAML stream
+func ObjectsToYAML(objects []runtime.Object) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, obj := range objects {
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		if i > 0 {
+			buf.WriteString(yamlDocumentSeparator)
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateManifests generates the objects needed to deploy the devfile to a cluster:
+// - a workload running the container components without dedicatedPod, with the init containers for preStart events,
+// and a workload for each container component with dedicatedPod, as returned by GetPodGroups. Each workload is a
+// Deployment, a StatefulSet, a Job or a CronJob, as returned by GetWorkloadKind with opts.WorkloadKind as default kind.
+// The non-ephemeral volumes mounted only by the pod of a StatefulSet are its volume claim templates instead of PVCs.
+// - for each workload, the Services exposing the endpoints of its container components, unless their port only has a "none" exposure,
+// one per Service type selected by the ServiceTypeAttribute endpoint attribute, as returned by GetEndpointServices
+// - an Ingress for each host of the public endpoints, as returned by GetIngresses,
+// or a Route for each public endpoint if opts.UseRoutes is set, or the Gateway API routes returned by GetGatewayRoutes
+// if opts.Gateway is set, targeting the Service exposing the endpoint
+// - a PersistentVolumeClaim for each non-ephemeral volume component not claimed by a StatefulSet
+// - if the ClusterAPIAccessAttribute attribute is set, a Role and a RoleBinding granting its rules to the ServiceAccount
+// running the pods, as returned by GetServiceAccountResources, and the ServiceAccount itself unless opts.ServiceAccountName
+// or the ServiceAccountAttribute attribute names an existing one
+// - if opts.NetworkPolicies is set, a NetworkPolicy for each workload, as returned by GetNetworkPolicy
+// - a HorizontalPodAutoscaler and a PodDisruptionBudget for each Deployment and StatefulSet configured by the AutoscalingAttribute and
+// DisruptionBudgetAttribute attributes, as returned by GetHorizontalPodAutoscaler and GetPodDisruptionBudget
+// - the resources inlined in the Kubernetes and OpenShift components deployed by default
+//
+// Generated objects follow this convention:
+// - the workload, the ClusterIP Service, the NetworkPolicy, the HorizontalPodAutoscaler and the PodDisruptionBudget of the shared pod
+// are named <name>, where <name> is opts.Name or the devfile metadata name
+// - the workload, the ClusterIP Service, the NetworkPolicy, the HorizontalPodAutoscaler and the PodDisruptionBudget of a container
+// component with dedicatedPod are named <name>-<component name>
+// - the NodePort, LoadBalancer and headless Services of a workload are suffixed with -nodeport, -lb and -headless
+// - Routes are named <name>-<endpoint name>, and Ingresses are named after the first endpoint they route, by name
+// - PVCs are named <name>-<volume component name>
+// - the generated ServiceAccount, the Role and the RoleBinding are named <name>
+// - all the objects are labeled with InstanceLabel=<name> and ManagedByLabel=ManagedByLabelValue, in addition to opts.Labels
+// - pods are selected using NameLabel=<workload name> and InstanceLabel=<name>
+//
+// Kubernetes and OpenShift components are deployed by default if deployByDefault is true,
+// or if it is not set and the component is not referenced by any apply command.
+func GenerateManifests(devfileObj parser.DevfileObj, opts ManifestsOptions) (*Manifests, error) {
+	name := opts.Name
+	if name == "" {
+		name = devfileObj.GetMetadataName()
+	}
+	if name == "" {
+		return nil, errors.New("a name is required to generate manifests, either from the options or from the devfile metadata")
+	}
+	if opts.UseRoutes && opts.Gateway != nil {
+		return nil, errors.New("routes cannot be generated both for OpenShift and for a Gateway, set either UseRoutes or Gateway")
+	}
+
+	g := manifestsGenerator{
+		devfileObj: devfileObj,
+		opts:       opts,
+		name:       name,
+	}
+	return g.generate()
+}
+
+// manifestsGenerator holds the state shared by the functions generating the manifests
+type manifestsGenerator struct {
+	devfileObj parser.DevfileObj
+	opts       ManifestsOptions
+	name       string
+}
+
+func (g *manifestsGenerator) generate() (*Manifests, error) {
+	manifests := &Manifests{}
+
+	pvcs, volumeInfos, err := g.getPVCs()
+	if err != nil {
+		return nil, err
+	}
+	manifests.PersistentVolumeClaims = pvcs
+
+	serviceAccountName := g.opts.ServiceAccountName
+	serviceAccountResources, err := GetServiceAccountResources(g.devfileObj, ServiceAccountParams{
+		ObjectMeta:         g.objectMeta(g.name, nil),
+		ServiceAccountName: g.opts.ServiceAccountName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if serviceAccountResources != nil {
+		serviceAccountName = serviceAccountResources.ServiceAccountName
+		if serviceAccountResources.ServiceAccount != nil {
+			manifests.ServiceAccounts = append(manifests.ServiceAccounts, serviceAccountResources.ServiceAccount)
+		}
+		manifests.Roles = append(manifests.Roles, serviceAccountResources.Role)
+		manifests.RoleBindings = append(manifests.RoleBindings, serviceAccountResources.RoleBinding)
+	}
+
+	manifests.PodGroups, err = GetPodGroups(g.devfileObj, PodTemplateParams{
+		Options:                    g.opts.Options,
+		PodSecurityAdmissionPolicy: g.opts.PodSecurityAdmissionPolicy,
+		PodSecurityAdmissionDryRun: g.opts.PodSecurityAdmissionDryRun,
+		LifecycleHooks:             g.opts.LifecycleHooks,
+		ProjectsVolume:             g.opts.ProjectsVolume,
+		Probes:                     g.opts.Probes,
+		ImagePullPolicy:            g.opts.ImagePullPolicy,
+		ImagePullSecrets:           g.opts.ImagePullSecrets,
+		ServiceAccountName:         serviceAccountName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// serviceNames maps the endpoints to the name of the Service exposing them
+	serviceNames := make(map[string]string)
+	// claimedPVCs are the PVCs replaced by the volume claim templates of the StatefulSets
+	claimedPVCs := make(map[string]bool)
+	for _, group := range manifests.PodGroups {
+		workloadName := g.name
+		if group.Component != "" {
+			workloadName = getResourceName(g.name, group.Component)
+		}
+		selectorLabels := g.selectorLabels(workloadName)
+
+		// only the containers running in the pod are exposed, not its init containers
+		running := make(map[string]bool)
+		for _, container := range group.PodTemplateSpec.Spec.Containers {
+			running[container.Name] = true
+		}
+		services, endpointServices, err := getEndpointServices(g.devfileObj, EndpointServicesParams{
+			TypeMeta:       GetTypeMeta(serviceKind, serviceAPIVersion),
+			ObjectMeta:     g.objectMeta(workloadName, nil),
+			SelectorLabels: selectorLabels,
+		}, g.opts.Options, func(componentName string) bool {
+			return running[componentName]
+		})
+		if err != nil {
+			return nil, err
+		}
+		manifests.Services = append(manifests.Services, services...)
+		for endpoint, serviceName := range endpointServices {
+			serviceNames[endpoint] = serviceName
+		}
+
+		scaleTargetRef, err := g.addWorkload(manifests, workloadName, group, volumeInfos, services, claimedPVCs)
+		if err != nil {
+			return nil, err
+		}
+		if scaleTargetRef != nil {
+			hpa, err := GetHorizontalPodAutoscaler(g.devfileObj, HorizontalPodAutoscalerParams{
+				TypeMeta:        GetTypeMeta(hpaKind, hpaAPIVersion),
+				ObjectMeta:      g.objectMeta(workloadName, nil),
+				ScaleTargetRef:  *scaleTargetRef,
+				PodTemplateSpec: group.PodTemplateSpec,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if hpa != nil {
+				manifests.HorizontalPodAutoscalers = append(manifests.HorizontalPodAutoscalers, hpa)
+			}
+			pdb, err := GetPodDisruptionBudget(g.devfileObj, PodDisruptionBudgetParams{
+				TypeMeta:          GetTypeMeta(pdbKind, pdbAPIVersion),
+				ObjectMeta:        g.objectMeta(workloadName, nil),
+				PodSelectorLabels: selectorLabels,
+				PodTemplateSpec:   group.PodTemplateSpec,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if pdb != nil {
+				manifests.PodDisruptionBudgets = append(manifests.PodDisruptionBudgets, pdb)
+			}
+		}
+
+		if g.opts.NetworkPolicies != nil {
+			networkPolicy, err := getNetworkPolicy(g.devfileObj, g.networkPolicyParams(workloadName, selectorLabels),
+				g.opts.Options, func(componentName string) bool {
+					return running[componentName]
+				})
+			if err != nil {
+				return nil, err
+			}
+			manifests.NetworkPolicies = append(manifests.NetworkPolicies, networkPolicy)
+		}
+	}
+	if len(claimedPVCs) > 0 {
+		var pvcs []*corev1.PersistentVolumeClaim
+		for _, pvc := range manifests.PersistentVolumeClaims {
+			if !claimedPVCs[pvc.Name] {
+				pvcs = append(pvcs, pvc)
+			}
+		}
+		manifests.PersistentVolumeClaims = pvcs
+	}
+
+	switch {
+	case g.opts.UseRoutes:
+		endpoints, err := getPublicEndpoints(g.devfileObj, g.opts.Options, serviceNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, endpoint := range endpoints {
+			route, err := g.getRoute(endpoint)
+			if err != nil {
+				return nil, err
+			}
+			manifests.Routes = append(manifests.Routes, route)
+		}
+	case g.opts.Gateway != nil:
+		routes, err := GetGatewayRoutes(g.devfileObj, GatewayRoutesParams{
+			ObjectMeta:       g.objectMeta(g.name, nil),
+			EndpointServices: serviceNames,
+			Domain:           g.opts.IngressDomain,
+			Parent:           *g.opts.Gateway,
+		}, g.opts.Options)
+		if err != nil {
+			return nil, err
+		}
+		manifests.HTTPRoutes, manifests.GRPCRoutes = routes.HTTPRoutes, routes.GRPCRoutes
+	default:
+		manifests.Ingresses, err = GetIngresses(g.devfileObj, IngressesParams{
+			TypeMeta:         GetTypeMeta(ingressKind, networkingV1APIVersion),
+			ObjectMeta:       g.objectMeta(g.name, nil),
+			EndpointServices: serviceNames,
+			IngressDomain:    g.opts.IngressDomain,
+			IngressClassName: g.opts.IngressClassName,
+			TLSSecretName:    g.opts.TLSSecretName,
+		}, g.opts.Options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	manifests.KubernetesResources, err = g.getKubernetesResources()
+	if err != nil {
+		return nil, err
+	}
+
+	return manifests, nil
+}
+
+// labels returns the labels set on all the generated objects
+func (g *manifestsGenerator) labels() map[string]string {
+	labels := map[string]string{
+		InstanceLabel:  g.name,
+		ManagedByLabel: ManagedByLabelValue,
+	}
+	return mergeMaps(labels, g.opts.Labels)
+}
+
+// selectorLabels returns the labels used to select the pods of the given workload
+func (g *manifestsGenerator) selectorLabels(workloadName string) map[string]string {
+	return map[string]string{
+		NameLabel:     workloadName,
+		InstanceLabel: g.name,
+	}
+}
+
+// objectMeta returns the metadata of a generated object
+func (g *manifestsGenerator) objectMeta(name string, extraLabels map[string]string) metav1.ObjectMeta {
+	meta := GetObjectMeta(name, g.opts.Namespace, mergeMaps(g.labels(), extraLabels), mergeMaps(nil, g.opts.Annotations))
+	meta.OwnerReferences = g.opts.OwnerReferences
+	return meta
+}
+
+// groupFilter returns a filter accepting the container components running in the pod group
+func groupFilter(group PodGroup) func(componentName string) bool {
+	components := make(map[string]bool, len(group.Components))
+	for _, component := range group.Components {
+		components[component] = true
+	}
+	return func(componentName string) bool {
+		return components[componentName]
+	}
+}
+
+// addWorkload adds the workload running the pod of the group to the manifests, of the kind returned by GetWorkloadKind.
+// It returns the reference of the workload to be scaled by a HorizontalPodAutoscaler, or nil for Jobs and CronJobs.
+// The non-ephemeral volumes mounted only by the pod of a StatefulSet are turned into volume claim templates,
+// and their PVCs are added to claimedPVCs.
+func (g *manifestsGenerator) addWorkload(manifests *Manifests, workloadName string, group PodGroup, volumeInfos map[string]VolumeInfo,
+	services []*corev1.Service, claimedPVCs map[string]bool) (*autoscalingv2.CrossVersionObjectReference, error) {
+	kind, err := GetWorkloadKind(g.devfileObj, group.PodTemplateSpec, g.opts.WorkloadKind)
+	if err != nil {
+		return nil, err
+	}
+	include := groupFilter(group)
+	groupVolumeInfos, err := g.getGroupVolumeInfos(include, volumeInfos)
+	if err != nil {
+		return nil, err
+	}
+	var claimTemplates []corev1.PersistentVolumeClaim
+	if kind == StatefulSetWorkloadKind {
+		claimed, err := g.getClaimableVolumes(groupVolumeInfos, manifests.PersistentVolumeClaims)
+		if err != nil {
+			return nil, err
+		}
+		for volumeName := range claimed {
+			claimedPVCs[groupVolumeInfos[volumeName].PVCName] = true
+			delete(groupVolumeInfos, volumeName)
+		}
+		claimTemplates, err = getVolumeClaimTemplates(g.devfileObj, group.PodTemplateSpec.Spec.Containers, "", func(volumeName string) bool {
+			return claimed[volumeName]
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	selectorLabels := g.selectorLabels(workloadName)
+	podTemplateSpec := group.PodTemplateSpec
+	podTemplateSpec.ObjectMeta.Labels = mergeMaps(g.labels(), selectorLabels)
+	// the pod groups are built without labels, the topology spread constraints spread the pods of the workload
+	setTopologySpreadSelector(&podTemplateSpec.Spec, selectorLabels)
+	volumes, err := g.getVolumes(podTemplateSpec.Spec.Containers, groupVolumeInfos)
+	if err != nil {
+		return nil, err
+	}
+	podTemplateSpec.Spec.Volumes = append(podTemplateSpec.Spec.Volumes, volumes...)
+	objectMeta := g.objectMeta(workloadName, selectorLabels)
+
+	switch kind {
+	case StatefulSetWorkloadKind:
+		// the pods get their network identity from the headless Service, if any
+		serviceName := workloadName
+		for _, service := range services {
+			if service.Spec.ClusterIP == corev1.ClusterIPNone {
+				serviceName = service.Name
+			}
+		}
+		statefulSet, err := getStatefulSet(g.devfileObj, StatefulSetParams{
+			TypeMeta:             GetTypeMeta(statefulSetKind, deploymentAPIVersion),
+			ObjectMeta:           objectMeta,
+			PodTemplateSpec:      podTemplateSpec,
+			PodSelectorLabels:    selectorLabels,
+			Replicas:             g.opts.Replicas,
+			ServiceName:          serviceName,
+			VolumeClaimTemplates: claimTemplates,
+		}, include)
+		if err != nil {
+			return nil, err
+		}
+		manifests.StatefulSets = append(manifests.StatefulSets, statefulSet)
+		return &autoscalingv2.CrossVersionObjectReference{APIVersion: deploymentAPIVersion, Kind: statefulSetKind, Name: workloadName}, nil
+	case JobWorkloadKind:
+		job, err := getJob(g.devfileObj, JobParams{
+			TypeMeta:        GetTypeMeta(jobKind, batchV1APIVersion),
+			ObjectMeta:      objectMeta,
+			PodTemplateSpec: podTemplateSpec,
+		}, include)
+		if err != nil {
+			return nil, err
+		}
+		manifests.Jobs = append(manifests.Jobs, job)
+		return nil, nil
+	case CronJobWorkloadKind:
+		schedule, err := GetCronJobSchedule(g.devfileObj, podTemplateSpec)
+		if err != nil {
+			return nil, err
+		}
+		cronJob, err := getCronJob(g.devfileObj, CronJobParams{
+			TypeMeta:   GetTypeMeta(cronJobKind, batchV1APIVersion),
+			ObjectMeta: objectMeta,
+			Schedule:   schedule,
+			Job: JobParams{
+				ObjectMeta:      metav1.ObjectMeta{Labels: mergeMaps(g.labels(), selectorLabels)},
+				PodTemplateSpec: podTemplateSpec,
+			},
+		}, include)
+		if err != nil {
+			return nil, err
+		}
+		manifests.CronJobs = append(manifests.CronJobs, cronJob)
+		return nil, nil
+	default:
+		deployment, err := getDeployment(g.devfileObj, DeploymentParams{
+			TypeMeta:          GetTypeMeta(deploymentKind, deploymentAPIVersion),
+			ObjectMeta:        objectMeta,
+			PodTemplateSpec:   podTemplateSpec,
+			PodSelectorLabels: selectorLabels,
+			Replicas:          g.opts.Replicas,
+		}, include)
+		if err != nil {
+			return nil, err
+		}
+		manifests.Deployments = append(manifests.Deployments, deployment)
+		return &autoscalingv2.CrossVersionObjectReference{APIVersion: deploymentAPIVersion, Kind: deploymentKind, Name: workloadName}, nil
+	}
+}
+
+// getGroupVolumeInfos returns the volume infos of the volumes mounted by the container components accepted by include
+func (g *manifestsGenerator) getGroupVolumeInfos(include func(componentName string) bool, volumeInfos map[string]VolumeInfo) (map[string]VolumeInfo, error) {
+	containerComponents, err := g.devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	groupVolumeInfos := make(map[string]VolumeInfo)
+	for _, comp := range containerComponents {
+		if !include(comp.Name) {
+			continue
+		}
+		for _, volumeMount := range comp.Container.VolumeMounts {
+			if volumeInfo, ok := volumeInfos[volumeMount.Name]; ok {
+				groupVolumeInfos[volumeMount.Name] = volumeInfo
+			}
+		}
+	}
+	return groupVolumeInfos, nil
+}
+
+// getClaimableVolumes returns the volumes of a pod which can be turned into volume claim templates:
+// the volumes with a PVC, as they are not ephemeral, and which are not mounted by other pods
+func (g *manifestsGenerator) getClaimableVolumes(groupVolumeInfos map[string]VolumeInfo, pvcs []*corev1.PersistentVolumeClaim) (map[string]bool, error) {
+	volumePods, err := getVolumePods(g.devfileObj)
+	if err != nil {
+		return nil, err
+	}
+	pvcNames := make(map[string]bool, len(pvcs))
+	for _, pvc := range pvcs {
+		pvcNames[pvc.Name] = true
+	}
+	claimable := make(map[string]bool)
+	for volumeName, volumeInfo := range groupVolumeInfos {
+		if pvcNames[volumeInfo.PVCName] && len(volumePods[volumeName]) <= 1 {
+			claimable[volumeName] = true
+		}
+	}
+	return claimable, nil
+}
+
+// getVolumes returns the pod volumes for the volume components, and adds the volume mounts to the containers.
+// Volumes are returned in a deterministic order, sorted by volume component name.
+func (g *manifestsGenerator) getVolumes(containers []corev1.Container, volumeInfos map[string]VolumeInfo) ([]corev1.Volume, error) {
+	volumeNames := make([]string, 0, len(volumeInfos))
+	for volumeName := range volumeInfos {
+		volumeNames = append(volumeNames, volumeName)
+	}
+	sort.Strings(volumeNames)
+
+	var volumes []corev1.Volume
+	for _, volumeName := range volumeNames {
+		vols, err := GetVolumesAndVolumeMounts(g.devfileObj, VolumeParams{
+			Containers:             containers,
+			VolumeNameToVolumeInfo: map[string]VolumeInfo{volumeName: volumeInfos[volumeName]},
+		}, g.opts.Options)
+		if err != nil {
+			return nil, err
+		}
+		volumes = append(volumes, vols...)
+	}
+	return volumes, nil
+}
+
+// getPVCs returns a PVC for each non-ephemeral volume component,
+// and the volume info of every volume component used by the container components
+func (g *manifestsGenerator) getPVCs() ([]*corev1.PersistentVolumeClaim, map[string]VolumeInfo, error) {
+	return GetPVCsFromVolumeComponents(g.devfileObj, VolumeComponentsParams{
+		TypeMeta:   GetTypeMeta(pvcKind, pvcAPIVersion),
+		ObjectMeta: g.objectMeta(g.name, nil),
+	})
+}
+
+// getRoute returns the Route of a public endpoint, configured by the endpoint attributes
+func (g *manifestsGenerator) getRoute(endpoint publicEndpoint) (*routev1.Route, error) {
+	routeSpecParams, err := GetEndpointRouteSpecParams(endpoint.Endpoint, endpoint.component, EndpointRouteParams{
+		ServiceName:  endpoint.serviceName,
+		HostTemplate: g.opts.RouteHostTemplate,
+		HostValues: RouteHostValues{
+			Name:        g.name,
+			DevfileName: g.devfileObj.GetMetadataName(),
+			Namespace:   g.opts.Namespace,
+			Domain:      g.opts.IngressDomain,
+		},
+		GetSecret:    g.opts.GetSecret,
+		InlineTLSKey: g.opts.InlineRouteTLSKeys,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetRoute(endpoint.Endpoint, RouteParams{
+		TypeMeta:        GetTypeMeta(routeKind, routeAPIVersion),
+		ObjectMeta:      g.objectMeta(getResourceName(g.name, endpoint.Name), nil),
+		RouteSpecParams: routeSpecParams,
+	}), nil
+}
+
+// networkPolicyParams returns the params of the NetworkPolicy of the pods of the given workload
+func (g *manifestsGenerator) networkPolicyParams(workloadName string, selectorLabels map[string]string) NetworkPolicyParams {
+	peers := *g.opts.NetworkPolicies
+	if peers.Public == nil {
+		switch {
+		case g.opts.UseRoutes:
+			peers.Public = []networkingv1.NetworkPolicyPeer{getOpenShiftIngressPeer()}
+		case g.opts.Gateway != nil:
+			peers.Public = getGatewayPeers(*g.opts.Gateway)
+		}
+	}
+	return NetworkPolicyParams{
+		TypeMeta:          GetTypeMeta(networkPolicyKind, networkingV1APIVersion),
+		ObjectMeta:        g.objectMeta(workloadName, nil),
+		PodSelectorLabels: selectorLabels,
+		Peers:             peers,
+	}
+}
+
+// getKubernetesResources returns the resources inlined in the Kubernetes and OpenShift components deployed by default
+func (g *manifestsGenerator) getKubernetesResources() ([]*unstructured.Unstructured, error) {
+	components, err := getDeployedByDefaultK8sLikeComponents(g.devfileObj)
+	if err != nil {
+		return nil, err
+	}
+	var resources []*unstructured.Unstructured
+	for _, comp := range components {
+		var inlined string
+		if comp.Kubernetes != nil {
+			inlined = comp.Kubernetes.Inlined
+		} else {
+			inlined = comp.Openshift.Inlined
+		}
+		if inlined == "" {
+			return nil, fmt.Errorf("component %s is not inlined, set ConvertKubernetesContentInUri in the parser arguments to generate its resources", comp.Name)
+		}
+		compResources, err := parseUnstructuredResources(inlined)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse resources of component %s: %w", comp.Name, err)
+		}
+		for _, res := range compResources {
+			if g.opts.Namespace != "" && res.GetNamespace() == "" {
+				res.SetNamespace(g.opts.Namespace)
+			}
+		}
+		resources = append(resources, compResources...)
+	}
+	return resources, nil
+}
+
+// getDeployedByDefaultK8sLikeComponents returns the Kubernetes and OpenShift components that are deployed by default,
+// i.e. the ones with deployByDefault set to true, or not set and not referenced by any apply command
+func getDeployedByDefaultK8sLikeComponents(devfileObj parser.DevfileObj) ([]v1.Component, error) {
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	appliedComponents := make(map[string]bool)
+	for _, command := range commands {
+		if command.Apply != nil {
+			appliedComponents[command.Apply.Component] = true
+		}
+	}
+
+	var result []v1.Component
+	for _, componentType := range []v1.ComponentType{v1.KubernetesComponentType, v1.OpenshiftComponentType} {
+		components, err := devfileObj.Data.GetComponents(common.DevfileOptions{
+			ComponentOptions: common.ComponentOptions{ComponentType: componentType},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, comp := range components {
+			var deployByDefault *bool
+			if comp.Kubernetes != nil {
+				deployByDefault = comp.Kubernetes.DeployByDefault
+			} else {
+				deployByDefault = comp.Openshift.DeployByDefault
+			}
+			if (deployByDefault == nil && !appliedComponents[comp.Name]) || (deployByDefault != nil && *deployByDefault) {
+				result = append(result, comp)
+			}
+		}
+	}
+	return result, nil
+}
+
+// getResourceName joins the given parts into a resource name, truncated to the maximum length of a label value.
+// A truncated name is suffixed with a hash of the full name, so that names differing only after the maximum length
+// do not collide.
+func getResourceName(parts ...string) string {
+	name := strings.Join(parts, "-")
+	if len(name) <= kubernetesNameMaxLength {
+		return strings.TrimRight(name, "-.")
+	}
+	hasher := fnv.New32a()
+	hasher.Write([]byte(name))
+	hash := utilrand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+	name = util.TruncateString(name, kubernetesNameMaxLength-len(hash)-1)
+	return strings.TrimRight(name, "-.") + "-" + hash
+}
+
+// parseUnstructuredResources parses a multi-document YAML content into unstructured resources, ignoring empty documents
+func parseUnstructuredResources(content string) ([]*unstructured.Unstructured, error) {
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(strings.NewReader(content)))
+	var resources []*unstructured.Unstructured
+	for {
+		doc, err := reader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		jsonDoc, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		if string(jsonDoc) == "null" {
+			continue
+		}
+		res := &unstructured.Unstructured{}
+		if err = res.UnmarshalJSON(jsonDoc); err != nil {
+			return nil, err
+		}
+		resources = append(resources, res)
+	}
+	return resources, nil
+}
@@ -0,0 +1,177 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+func TestGenerateManifests(t *testing.T) {
+	gatewayNamespace := gatewayv1beta1.Namespace("gateways")
+	tests := []struct {
+		name       string
+		devfile    string
+		opts       ManifestsOptions
+		wantGolden string
+		wantErr    bool
+	}{
+		{
+			name:    "with ingresses",
+			devfile: "manifests/devfile.yaml",
+			opts: ManifestsOptions{
+				Namespace:     "my-ns",
+				Labels:        map[string]string{"team": "web"},
+				Replicas:      pointer.Int32(2),
+				IngressDomain: "apps.example.com",
+				TLSSecretName: "my-tls",
+			},
+			wantGolden: "manifests/ingresses.yaml",
+		},
+		{
+			name:    "with routes and owner references",
+			devfile: "manifests/devfile.yaml",
+			opts: ManifestsOptions{
+				Name:      "my-app",
+				UseRoutes: true,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "1234"},
+				},
+			},
+			wantGolden: "manifests/routes.yaml",
+		},
+		{
+			name:    "with gateway routes",
+			devfile: "manifests/devfile.yaml",
+			opts: ManifestsOptions{
+				IngressDomain: "apps.example.com",
+				Gateway: &GatewayParent{
+					ParentRefs:        []gatewayv1beta1.ParentReference{{Name: "gateway", Namespace: &gatewayNamespace}},
+					HTTPListenerName:  "http",
+					HTTPSListenerName: "https",
+				},
+			},
+			wantGolden: "manifests/gateway.yaml",
+		},
+		{
+			name:    "with network policies",
+			devfile: "manifests/devfile-network.yaml",
+			opts: ManifestsOptions{
+				Namespace:       "my-ns",
+				IngressDomain:   "apps.example.com",
+				NetworkPolicies: &NetworkPolicyPeers{},
+				Gateway: &GatewayParent{
+					ParentRefs: []gatewayv1beta1.ParentReference{{Name: "gateway", Namespace: &gatewayNamespace}},
+				},
+			},
+			wantGolden: "manifests/network.yaml",
+		},
+		{
+			name:    "with routes and gateway",
+			devfile: "manifests/devfile.yaml",
+			opts: ManifestsOptions{
+				UseRoutes: true,
+				Gateway:   &GatewayParent{},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "with dedicated pods",
+			devfile:    "manifests/devfile-dedicated.yaml",
+			wantGolden: "manifests/dedicated.yaml",
+		},
+		{
+			name:       "with workload kinds",
+			devfile:    "manifests/devfile-workloads.yaml",
+			wantGolden: "manifests/workloads.yaml",
+		},
+		{
+			name:    "with cluster API access",
+			devfile: "manifests/devfile-access.yaml",
+			opts: ManifestsOptions{
+				Namespace:        "operators",
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "mirror-credentials"}},
+			},
+			wantGolden: "manifests/access.yaml",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Path: filepath.Join("testdata", tt.devfile)})
+			manifests, err := GenerateManifests(devfileObj, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateManifests() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got, err := manifests.ToYAML()
+			if err != nil {
+				t.Fatalf("ToYAML() unexpected error: %v", err)
+			}
+			assertGolden(t, tt.wantGolden, got)
+		})
+	}
+}
+
+func TestGetResourceName(t *testing.T) {
+	longName := strings.Repeat("a", 60)
+	tests := []struct {
+		name  string
+		parts []string
+		want  string
+	}{
+		{
+			name:  "short name",
+			parts: []string{"nodejs", "http"},
+			want:  "nodejs-http",
+		},
+		{
+			name:  "trailing separator",
+			parts: []string{"nodejs", ""},
+			want:  "nodejs",
+		},
+		{
+			name:  "truncated name",
+			parts: []string{longName, "http-first"},
+			want:  longName[:52] + "-578fd8b7bc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getResourceName(tt.parts...)
+			if got != tt.want {
+				t.Errorf("getResourceName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	first := getResourceName(longName, "http-first")
+	second := getResourceName(longName, "http-second")
+	if first == second {
+		t.Errorf("getResourceName() returned the same name %q for names differing after the maximum length", first)
+	}
+	if len(first) > kubernetesNameMaxLength || len(second) > kubernetesNameMaxLength {
+		t.Errorf("getResourceName() returned names longer than %d characters: %q, %q", kubernetesNameMaxLength, first, second)
+	}
+}
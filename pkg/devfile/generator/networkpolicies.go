@@ -0,0 +1,209 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// DefaultIngressControllerNamespace is the namespace of the ingress controller allowed to reach the public endpoints
+	// by default
+	DefaultIngressControllerNamespace = "ingress-nginx"
+	// namespaceNameLabel is the label set by Kubernetes on each namespace to its name
+	namespaceNameLabel = "kubernetes.io/metadata.name"
+	// openShiftIngressPolicyGroupLabel is the label of the namespaces of the OpenShift ingress controllers
+	openShiftIngressPolicyGroupLabel = "policy-group.network.openshift.io/ingress"
+)
+
+// NetworkPolicyPeers are the peers allowed to reach the endpoints by the NetworkPolicies
+type NetworkPolicyPeers struct {
+	// Internal are the peers allowed to reach the internal and public endpoints.
+	// Defaults to all the pods of the namespace of the NetworkPolicy.
+	Internal []networkingv1.NetworkPolicyPeer
+	// Public are the peers allowed to reach the public endpoints, in addition to the Internal peers.
+	// Defaults to the pods of the DefaultIngressControllerNamespace namespace.
+	Public []networkingv1.NetworkPolicyPeer
+}
+
+// NetworkPolicyParams is a struct that contains the required data to create a NetworkPolicy object
+type NetworkPolicyParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// PodSelectorLabels select the pods of the NetworkPolicy, as passed to GetService and GetDeployment
+	PodSelectorLabels map[string]string
+	// Peers are the peers allowed to reach the endpoints
+	Peers NetworkPolicyPeers
+}
+
+// GetNetworkPolicy returns a NetworkPolicy enforcing the exposure of the endpoints of the container components:
+// - the ports of endpoints with a "none" exposure are not allowed, they are only reachable from the same pod
+// - the ports of endpoints with an "internal" exposure are allowed from the Internal peers
+// - the ports of endpoints with a "public" exposure are allowed from the Internal and Public peers
+// - the ports of endpoints exposed by a NodePort or LoadBalancer Service, as set by ServiceTypeAttribute, are allowed
+// from all peers, as the traffic of these Services comes from outside the cluster
+//
+// Any other incoming traffic of the pods is denied.
+func GetNetworkPolicy(devfileObj parser.DevfileObj, policyParams NetworkPolicyParams, options common.DevfileOptions) (*networkingv1.NetworkPolicy, error) {
+	return getNetworkPolicy(devfileObj, policyParams, options, nil)
+}
+
+// getNetworkPolicy returns the NetworkPolicy of the pods running the container components accepted by include.
+// A nil include accepts the container components not applied by preStart and postStop events.
+func getNetworkPolicy(devfileObj parser.DevfileObj, policyParams NetworkPolicyParams, options common.DevfileOptions,
+	include func(componentName string) bool) (*networkingv1.NetworkPolicy, error) {
+	if include == nil {
+		containers, err := GetContainers(devfileObj, options)
+		if err != nil {
+			return nil, err
+		}
+		running := make(map[string]bool)
+		for _, container := range containers {
+			running[container.Name] = true
+		}
+		include = func(componentName string) bool {
+			return running[componentName]
+		}
+	}
+
+	options.ComponentOptions = common.ComponentOptions{ComponentType: v1.ContainerComponentType}
+	containerComponents, err := devfileObj.Data.GetComponents(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var internalPorts, publicPorts, externalPorts []networkingv1.NetworkPolicyPort
+	for _, comp := range containerComponents {
+		if !include(comp.Name) {
+			continue
+		}
+		for _, endpoint := range comp.Container.Endpoints {
+			protocol, _ := getEndpointProtocols(endpoint.Protocol)
+			port := intstr.FromInt(endpoint.TargetPort)
+			policyPort := networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &port}
+			if endpoint.Exposure == v1.NoneEndpointExposure {
+				continue
+			}
+			serviceType, err := getEndpointServiceType(comp, endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if serviceType == string(corev1.ServiceTypeNodePort) || serviceType == string(corev1.ServiceTypeLoadBalancer) {
+				externalPorts = appendNetworkPolicyPort(externalPorts, policyPort)
+				continue
+			}
+			switch endpoint.Exposure {
+			case v1.InternalEndpointExposure:
+				internalPorts = appendNetworkPolicyPort(internalPorts, policyPort)
+			case v1.PublicEndpointExposure, "":
+				publicPorts = appendNetworkPolicyPort(publicPorts, policyPort)
+			}
+		}
+	}
+
+	internalPeers := policyParams.Peers.Internal
+	if internalPeers == nil {
+		internalPeers = []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}}
+	}
+	publicPeers := policyParams.Peers.Public
+	if publicPeers == nil {
+		publicPeers = []networkingv1.NetworkPolicyPeer{getNamespacePeer(DefaultIngressControllerNamespace)}
+	}
+
+	// with the Ingress policy type, the incoming traffic not allowed by any rule is denied
+	var rules []networkingv1.NetworkPolicyIngressRule
+	if len(internalPorts) > 0 {
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: internalPorts, From: internalPeers})
+	}
+	if len(publicPorts) > 0 {
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{
+			Ports: publicPorts,
+			From:  append(append([]networkingv1.NetworkPolicyPeer{}, internalPeers...), publicPeers...),
+		})
+	}
+	if len(externalPorts) > 0 {
+		// a rule without peers allows all the sources
+		rules = append(rules, networkingv1.NetworkPolicyIngressRule{Ports: externalPorts})
+	}
+
+	return &networkingv1.NetworkPolicy{
+		TypeMeta:   policyParams.TypeMeta,
+		ObjectMeta: policyParams.ObjectMeta,
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: policyParams.PodSelectorLabels},
+			Ingress:     rules,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+		},
+	}, nil
+}
+
+// appendNetworkPolicyPort appends a port to the ports of a rule, unless it is already allowed
+func appendNetworkPolicyPort(ports []networkingv1.NetworkPolicyPort, port networkingv1.NetworkPolicyPort) []networkingv1.NetworkPolicyPort {
+	for _, p := range ports {
+		if *p.Port == *port.Port && *p.Protocol == *port.Protocol {
+			return ports
+		}
+	}
+	return append(ports, port)
+}
+
+// getNamespacePeer returns a peer selecting all the pods of the given namespace
+func getNamespacePeer(namespace string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{namespaceNameLabel: namespace},
+		},
+	}
+}
+
+// getOpenShiftIngressPeer returns a peer selecting the pods of the namespaces of the OpenShift ingress controllers
+func getOpenShiftIngressPeer() networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{openShiftIngressPolicyGroupLabel: ""},
+		},
+	}
+}
+
+// getGatewayPeers returns the peers selecting the pods of the namespaces of the parent Gateways. A Gateway without
+// namespace is in the namespace of its routes, which is the namespace of the NetworkPolicy.
+func getGatewayPeers(parent GatewayParent) []networkingv1.NetworkPolicyPeer {
+	// without parent, no route is attached and no public peer is allowed
+	peers := []networkingv1.NetworkPolicyPeer{}
+	seen := make(map[string]bool)
+	for _, parentRef := range parent.ParentRefs {
+		namespace := ""
+		if parentRef.Namespace != nil {
+			namespace = string(*parentRef.Namespace)
+		}
+		if seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		if namespace == "" {
+			peers = append(peers, networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{}})
+		} else {
+			peers = append(peers, getNamespacePeer(namespace))
+		}
+	}
+	return peers
+}
@@ -0,0 +1,189 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	gatewayv1beta1 "sigs.k8s.io/gateway-api/apis/v1beta1"
+)
+
+// networkPolicyPort returns a NetworkPolicy port
+func networkPolicyPort(protocol corev1.Protocol, port int) networkingv1.NetworkPolicyPort {
+	policyPort := intstr.FromInt(port)
+	return networkingv1.NetworkPolicyPort{Protocol: &protocol, Port: &policyPort}
+}
+
+func TestGetNetworkPolicy(t *testing.T) {
+	selectorLabels := map[string]string{"app": "policies"}
+	monitoringPeer := networkingv1.NetworkPolicyPeer{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "monitoring"}}}
+	gatewayPeer := getNamespacePeer("gateways")
+	tests := []struct {
+		name      string
+		endpoints string
+		peers     NetworkPolicyPeers
+		wantRules []networkingv1.NetworkPolicyIngressRule
+	}{
+		{
+			name: "default peers",
+			endpoints: `        - name: http
+          targetPort: 8080
+        - name: metrics
+          targetPort: 9090
+          exposure: internal
+        - name: dns
+          targetPort: 5353
+          protocol: udp
+          exposure: internal
+        - name: debug
+          targetPort: 5858
+          exposure: none
+        - name: events
+          targetPort: 8080
+          protocol: ws
+`,
+			wantRules: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, 9090), networkPolicyPort(corev1.ProtocolUDP, 5353)},
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, 8080)},
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}, getNamespacePeer(DefaultIngressControllerNamespace)},
+				},
+			},
+		},
+		{
+			name: "caller peers",
+			endpoints: `        - name: http
+          targetPort: 8080
+`,
+			peers: NetworkPolicyPeers{
+				Internal: []networkingv1.NetworkPolicyPeer{monitoringPeer},
+				Public:   []networkingv1.NetworkPolicyPeer{gatewayPeer},
+			},
+			wantRules: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, 8080)},
+					From:  []networkingv1.NetworkPolicyPeer{monitoringPeer, gatewayPeer},
+				},
+			},
+		},
+		{
+			name: "NodePort and LoadBalancer endpoints",
+			endpoints: `        - name: http
+          targetPort: 8080
+          exposure: internal
+        - name: admin
+          targetPort: 8443
+          attributes:
+            service-type: NodePort
+        - name: dns
+          targetPort: 5353
+          protocol: udp
+          exposure: internal
+          attributes:
+            service-type: LoadBalancer
+`,
+			wantRules: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, 8080)},
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{networkPolicyPort(corev1.ProtocolTCP, 8443), networkPolicyPort(corev1.ProtocolUDP, 5353)},
+				},
+			},
+		},
+		{
+			name: "no exposed endpoint",
+			endpoints: `        - name: debug
+          targetPort: 5858
+          exposure: none
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: policies
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+` + tt.endpoints)})
+			networkPolicy, err := GetNetworkPolicy(devfileObj, NetworkPolicyParams{
+				ObjectMeta:        metav1.ObjectMeta{Name: "app"},
+				PodSelectorLabels: selectorLabels,
+				Peers:             tt.peers,
+			}, common.DevfileOptions{})
+			if err != nil {
+				t.Fatalf("GetNetworkPolicy() unexpected error: %v", err)
+			}
+			wantSpec := networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: selectorLabels},
+				Ingress:     tt.wantRules,
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			}
+			if diff := cmp.Diff(wantSpec, networkPolicy.Spec); diff != "" {
+				t.Errorf("GetNetworkPolicy() spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetGatewayPeers(t *testing.T) {
+	gatewayNamespace := gatewayv1beta1.Namespace("gateways")
+	tests := []struct {
+		name      string
+		parent    GatewayParent
+		wantPeers []networkingv1.NetworkPolicyPeer
+	}{
+		{
+			name: "gateways in other namespaces",
+			parent: GatewayParent{ParentRefs: []gatewayv1beta1.ParentReference{
+				{Name: "public", Namespace: &gatewayNamespace},
+				{Name: "internal", Namespace: &gatewayNamespace},
+			}},
+			wantPeers: []networkingv1.NetworkPolicyPeer{getNamespacePeer("gateways")},
+		},
+		{
+			name:      "gateway in the namespace of the routes",
+			parent:    GatewayParent{ParentRefs: []gatewayv1beta1.ParentReference{{Name: "gateway"}}},
+			wantPeers: []networkingv1.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+		},
+		{
+			name:      "no gateway",
+			wantPeers: []networkingv1.NetworkPolicyPeer{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantPeers, getGatewayPeers(tt.parent)); diff != "" {
+				t.Errorf("getGatewayPeers() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	devfilepkg "github.com/devfile/api/v2/pkg/devfile"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Attributes placing the pods on the nodes of the cluster. As AutoscalingAttribute, they are set either on the devfile,
+// for all the pods, or on a container component, for its pod.
+//
+//	attributes:
+//	  node-selector:
+//	    node-role.kubernetes.io/worker: ""
+//	  tolerations:
+//	    - key: dedicated
+//	      operator: Equal
+//	      value: builds
+//	      effect: NoSchedule
+//	  topology-spread-constraints:
+//	    - maxSkew: 1
+//	      topologyKey: topology.kubernetes.io/zone
+//	      whenUnsatisfiable: ScheduleAnyway
+const (
+	// NodeSelectorAttribute is the map of the node labels required to run the pod
+	NodeSelectorAttribute = "node-selector"
+	// TolerationsAttribute is the list of the core/v1 tolerations of the pod
+	TolerationsAttribute = "tolerations"
+	// TopologySpreadConstraintsAttribute is the list of the core/v1 topology spread constraints of the pod.
+	// The constraints without labelSelector select the pods of the same workload generated by GenerateManifests,
+	// or the pods with the labels of PodTemplateParams.ObjectMeta.
+	TopologySpreadConstraintsAttribute = "topology-spread-constraints"
+)
+
+// ArchitectureLabel is the node label holding the CPU architecture of the node
+const ArchitectureLabel = "kubernetes.io/arch"
+
+// getArchitectureAffinity returns the affinity requiring the nodes to have one of the given architectures,
+// or nil if no architecture is given
+func getArchitectureAffinity(architectures []devfilepkg.Architecture) *corev1.Affinity {
+	if len(architectures) == 0 {
+		return nil
+	}
+	values := make([]string, 0, len(architectures))
+	for _, architecture := range architectures {
+		values = append(values, string(architecture))
+	}
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      ArchitectureLabel,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   values,
+					}},
+				}},
+			},
+		},
+	}
+}
+
+// setPodPlacement places the pod on the nodes with the architectures of the devfile metadata, as configured by the
+// NodeSelectorAttribute, TolerationsAttribute and TopologySpreadConstraintsAttribute attributes of the container
+// components running in the pod or of the devfile
+func setPodPlacement(globalAttributes attributes.Attributes, podComponents []v1.Component, architectures []devfilepkg.Architecture, podTemplateSpec *corev1.PodTemplateSpec) error {
+	podTemplateSpec.Spec.Affinity = getArchitectureAffinity(architectures)
+
+	var nodeSelector map[string]string
+	if _, err := decodePodAttribute(globalAttributes, podComponents, NodeSelectorAttribute, &nodeSelector); err != nil {
+		return err
+	}
+	for key, value := range nodeSelector {
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return fmt.Errorf("invalid %s attribute: invalid label %s: %s", NodeSelectorAttribute, key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return fmt.Errorf("invalid %s attribute: invalid value %q of label %s: %s", NodeSelectorAttribute, value, key, strings.Join(errs, ", "))
+		}
+	}
+
+	var tolerations []corev1.Toleration
+	if _, err := decodePodAttribute(globalAttributes, podComponents, TolerationsAttribute, &tolerations); err != nil {
+		return err
+	}
+	for i, toleration := range tolerations {
+		if err := validateToleration(toleration); err != nil {
+			return fmt.Errorf("invalid %s attribute: toleration %d %w", TolerationsAttribute, i+1, err)
+		}
+	}
+
+	var constraints []corev1.TopologySpreadConstraint
+	if _, err := decodePodAttribute(globalAttributes, podComponents, TopologySpreadConstraintsAttribute, &constraints); err != nil {
+		return err
+	}
+	for i := range constraints {
+		if err := validateTopologySpreadConstraint(constraints[i]); err != nil {
+			return fmt.Errorf("invalid %s attribute: constraint %d %w", TopologySpreadConstraintsAttribute, i+1, err)
+		}
+	}
+
+	podTemplateSpec.Spec.NodeSelector = nodeSelector
+	podTemplateSpec.Spec.Tolerations = tolerations
+	podTemplateSpec.Spec.TopologySpreadConstraints = constraints
+	setTopologySpreadSelector(&podTemplateSpec.Spec, podTemplateSpec.Labels)
+	return nil
+}
+
+// setTopologySpreadSelector sets the label selector of the topology spread constraints of the pod without labelSelector,
+// so that they spread the pods with the given labels. The constraints are left as is if no label is given.
+func setTopologySpreadSelector(podSpec *corev1.PodSpec, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	for i := range podSpec.TopologySpreadConstraints {
+		if podSpec.TopologySpreadConstraints[i].LabelSelector == nil {
+			podSpec.TopologySpreadConstraints[i].LabelSelector = &metav1.LabelSelector{MatchLabels: mergeMaps(nil, labels)}
+		}
+	}
+}
+
+// validateToleration returns an error if the toleration is not valid
+func validateToleration(toleration corev1.Toleration) error {
+	switch toleration.Operator {
+	case corev1.TolerationOpExists:
+		if toleration.Value != "" {
+			return fmt.Errorf("has a value, but its operator is %s", corev1.TolerationOpExists)
+		}
+	case "", corev1.TolerationOpEqual:
+		if toleration.Key == "" {
+			return fmt.Errorf("has no key, which requires the %s operator", corev1.TolerationOpExists)
+		}
+	default:
+		return fmt.Errorf("has the invalid operator %s, expected %s or %s", toleration.Operator, corev1.TolerationOpEqual, corev1.TolerationOpExists)
+	}
+	switch toleration.Effect {
+	case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+	default:
+		return fmt.Errorf("has the invalid effect %s, expected %s, %s or %s",
+			toleration.Effect, corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute)
+	}
+	if toleration.TolerationSeconds != nil && toleration.Effect != corev1.TaintEffectNoExecute {
+		return fmt.Errorf("sets tolerationSeconds, which requires the %s effect", corev1.TaintEffectNoExecute)
+	}
+	return nil
+}
+
+// validateTopologySpreadConstraint returns an error if the topology spread constraint is not valid
+func validateTopologySpreadConstraint(constraint corev1.TopologySpreadConstraint) error {
+	if constraint.MaxSkew < 1 {
+		return errors.New("must have a maxSkew of at least 1")
+	}
+	if constraint.TopologyKey == "" {
+		return errors.New("must have a topologyKey")
+	}
+	switch constraint.WhenUnsatisfiable {
+	case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+	default:
+		return fmt.Errorf("has the invalid whenUnsatisfiable %q, expected %s or %s",
+			constraint.WhenUnsatisfiable, corev1.DoNotSchedule, corev1.ScheduleAnyway)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodPlacement(t *testing.T) {
+	podLabels := map[string]string{NameLabel: "app"}
+	tests := []struct {
+		name          string
+		metadata      string
+		attributes    string
+		component     string
+		wantPlacement corev1.PodSpec
+		wantErr       string
+	}{
+		{
+			name: "no placement",
+		},
+		{
+			name: "architectures",
+			metadata: `  architectures: [amd64, arm64]
+`,
+			wantPlacement: corev1.PodSpec{
+				Affinity: &corev1.Affinity{
+					NodeAffinity: &corev1.NodeAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+							NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+								MatchExpressions: []corev1.NodeSelectorRequirement{{
+									Key:      "kubernetes.io/arch",
+									Operator: corev1.NodeSelectorOpIn,
+									Values:   []string{"amd64", "arm64"},
+								}},
+							}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "placement attributes",
+			attributes: `  node-selector:
+    disktype: ssd
+  tolerations:
+    - key: dedicated
+      operator: Equal
+      value: builds
+      effect: NoSchedule
+  topology-spread-constraints:
+    - maxSkew: 1
+      topologyKey: topology.kubernetes.io/zone
+      whenUnsatisfiable: ScheduleAnyway
+`,
+			wantPlacement: corev1.PodSpec{
+				NodeSelector: map[string]string{"disktype": "ssd"},
+				Tolerations: []corev1.Toleration{{
+					Key:      "dedicated",
+					Operator: corev1.TolerationOpEqual,
+					Value:    "builds",
+					Effect:   corev1.TaintEffectNoSchedule,
+				}},
+				TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+					MaxSkew:           1,
+					TopologyKey:       "topology.kubernetes.io/zone",
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: podLabels},
+				}},
+			},
+		},
+		{
+			name: "component attribute overriding the devfile attribute",
+			attributes: `  node-selector:
+    disktype: ssd
+`,
+			component: `    attributes:
+      node-selector:
+        disktype: nvme
+`,
+			wantPlacement: corev1.PodSpec{
+				NodeSelector: map[string]string{"disktype": "nvme"},
+			},
+		},
+		{
+			name: "invalid node selector label",
+			attributes: `  node-selector:
+    "disk type": ssd
+`,
+			wantErr: "invalid node-selector attribute: invalid label disk type: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')",
+		},
+		{
+			name: "toleration with a value and the Exists operator",
+			attributes: `  tolerations:
+    - key: dedicated
+      operator: Exists
+      value: builds
+`,
+			wantErr: "invalid tolerations attribute: toleration 1 has a value, but its operator is Exists",
+		},
+		{
+			name: "toleration seconds without the NoExecute effect",
+			attributes: `  tolerations:
+    - key: dedicated
+      effect: NoSchedule
+      tolerationSeconds: 60
+`,
+			wantErr: "invalid tolerations attribute: toleration 1 sets tolerationSeconds, which requires the NoExecute effect",
+		},
+		{
+			name: "topology spread constraint without whenUnsatisfiable",
+			attributes: `  topology-spread-constraints:
+    - maxSkew: 1
+      topologyKey: kubernetes.io/hostname
+`,
+			wantErr: `invalid topology-spread-constraints attribute: constraint 1 has the invalid whenUnsatisfiable "", expected DoNotSchedule or ScheduleAnyway`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `schemaVersion: 2.2.0
+metadata:
+  name: app
+` + tt.metadata
+			if tt.attributes != "" {
+				content += "attributes:\n" + tt.attributes
+			}
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(content + `components:
+  - name: web
+` + tt.component + `    container:
+      image: web
+`)})
+			podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetPodTemplateSpec() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+			}
+			gotPlacement := corev1.PodSpec{
+				Affinity:                  podTemplateSpec.Spec.Affinity,
+				NodeSelector:              podTemplateSpec.Spec.NodeSelector,
+				Tolerations:               podTemplateSpec.Spec.Tolerations,
+				TopologySpreadConstraints: podTemplateSpec.Spec.TopologySpreadConstraints,
+			}
+			if diff := cmp.Diff(tt.wantPlacement, gotPlacement); diff != "" {
+				t.Errorf("GetPodTemplateSpec() placement mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateManifestsTopologySpreadConstraints(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: app
+attributes:
+  topology-spread-constraints:
+    - maxSkew: 1
+      topologyKey: topology.kubernetes.io/zone
+      whenUnsatisfiable: ScheduleAnyway
+components:
+  - name: web
+    container:
+      image: web
+  - name: worker
+    container:
+      image: worker
+      dedicatedPod: true
+`)})
+	manifests, err := GenerateManifests(devfileObj, ManifestsOptions{})
+	if err != nil {
+		t.Fatalf("GenerateManifests() unexpected error: %v", err)
+	}
+	want := map[string]map[string]string{
+		"app":        {NameLabel: "app", InstanceLabel: "app"},
+		"app-worker": {NameLabel: "app-worker", InstanceLabel: "app"},
+	}
+	got := map[string]map[string]string{}
+	for _, deployment := range manifests.Deployments {
+		for _, constraint := range deployment.Spec.Template.Spec.TopologySpreadConstraints {
+			if constraint.LabelSelector == nil {
+				t.Fatalf("GenerateManifests() topology spread constraint of Deployment %s without labelSelector", deployment.Name)
+			}
+			got[deployment.Name] = constraint.LabelSelector.MatchLabels
+		}
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GenerateManifests() topology spread constraints selectors mismatch (-want +got):\n%s", diff)
+	}
+}
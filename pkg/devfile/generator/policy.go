@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/pod-security-admission/api"
 	psaapi "k8s.io/pod-security-admission/api"
 	psapolicy "k8s.io/pod-security-admission/policy"
@@ -28,87 +29,178 @@ import (
 // ContainerVisitor is called with each container
 type ContainerVisitor func(container *corev1.Container)
 
-// visitContainers invokes the visitor function for every container in the given pod template spec
-func visitContainers(podTemplateSpec *corev1.PodTemplateSpec, visitor ContainerVisitor) {
+// containerPathVisitor is called with each container and its path in the pod template
+type containerPathVisitor func(path *field.Path, container *corev1.Container)
+
+// visitContainersWithPath invokes the visitor function for every container in the given pod template spec,
+// with the path of the container in the pod template
+func visitContainersWithPath(podTemplateSpec *corev1.PodTemplateSpec, visitor containerPathVisitor) {
+	spec := field.NewPath("spec")
 	for i := range podTemplateSpec.Spec.InitContainers {
-		visitor(&podTemplateSpec.Spec.InitContainers[i])
+		visitor(spec.Child("initContainers").Index(i), &podTemplateSpec.Spec.InitContainers[i])
 	}
 	for i := range podTemplateSpec.Spec.Containers {
-		visitor(&podTemplateSpec.Spec.Containers[i])
+		visitor(spec.Child("containers").Index(i), &podTemplateSpec.Spec.Containers[i])
 	}
 	for i := range podTemplateSpec.Spec.EphemeralContainers {
-		visitor((*corev1.Container)(&podTemplateSpec.Spec.EphemeralContainers[i].EphemeralContainerCommon))
+		visitor(spec.Child("ephemeralContainers").Index(i), (*corev1.Container)(&podTemplateSpec.Spec.EphemeralContainers[i].EphemeralContainerCommon))
 	}
 }
 
-func patchForPolicy(podTemplateSpec *corev1.PodTemplateSpec, policy psaapi.Policy) (*corev1.PodTemplateSpec, error) {
+// PolicyMode is the mode in which a level of a Pod Security Admission policy applies
+type PolicyMode string
+
+// The modes of the levels of a Pod Security Admission policy
+const (
+	EnforcePolicyMode PolicyMode = "enforce"
+	WarnPolicyMode    PolicyMode = "warn"
+	AuditPolicyMode   PolicyMode = "audit"
+)
+
+// PolicyViolation is a Pod Security Admission check not respected by a pod
+type PolicyViolation struct {
+	// Mode and Level are the mode and the level of the policy violated by the pod
+	Mode  PolicyMode
+	Level psaapi.Level
+	// Reason and Detail describe the violation, as reported by the check, such as "runAsNonRoot != true"
+	Reason string
+	Detail string
+	// PatchedFields are the paths of the fields of the pod template patched to respect the check, such as
+	// spec.containers[0].securityContext.allowPrivilegeEscalation. The violations of the final pod, after the pod and
+	// container overrides, are reported without being patched.
+	PatchedFields []string
+}
+
+// PolicyReport reports the Pod Security Admission checks violated by a pod: the violations of the enforce level patched
+// before the pod and container overrides are applied, followed by the violations of the enforce, warn and audit levels
+// by the final pod, after the overrides. Unless in dry-run mode, the violations of the enforce level by the final pod
+// are introduced by the overrides, which are not patched.
+type PolicyReport struct {
+	Violations []PolicyViolation
+}
+
+// patchForPolicy patches the pod template to respect the enforce level of the policy, and returns the patched violations.
+// If dryRun is set, the pod is not patched.
+// An error is returned if the pod cannot be patched to respect the enforce level.
+func patchForPolicy(podTemplateSpec *corev1.PodTemplateSpec, policy psaapi.Policy, dryRun bool) (*corev1.PodTemplateSpec, []PolicyViolation, error) {
+	if dryRun {
+		return podTemplateSpec, nil, nil
+	}
 	evaluator, err := psapolicy.NewEvaluator(psapolicy.DefaultChecks())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	results := evaluator.EvaluatePod(policy.Enforce, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec)
-	for _, result := range results {
+	var violations []PolicyViolation
+	for _, result := range evaluator.EvaluatePod(policy.Enforce, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec) {
 		if result.Allowed {
 			continue
 		}
+		violation := PolicyViolation{
+			Mode:   EnforcePolicyMode,
+			Level:  policy.Enforce.Level,
+			Reason: result.ForbiddenReason,
+			Detail: result.ForbiddenDetail,
+		}
 		switch result.ForbiddenReason {
 		case "allowPrivilegeEscalation != false":
-			podTemplateSpec = patchAllowPrivilegeEscalation(podTemplateSpec)
+			violation.PatchedFields = patchAllowPrivilegeEscalation(podTemplateSpec)
 		case "unrestricted capabilities":
-			podTemplateSpec = patchUnrestrictedCapabilities(podTemplateSpec)
+			violation.PatchedFields = patchUnrestrictedCapabilities(podTemplateSpec)
 		case "runAsNonRoot != true":
-			podTemplateSpec = patchRunAsNonRoot(podTemplateSpec)
+			violation.PatchedFields = patchRunAsNonRoot(podTemplateSpec)
 		case "seccompProfile":
-			podTemplateSpec = patchSeccompProfile(podTemplateSpec, policy.Enforce.Level)
-			// Other policies are not implemented as they cannot be encountered with pods created by the library
+			violation.PatchedFields = patchSeccompProfile(podTemplateSpec, policy.Enforce.Level)
+			// Other checks are not patched, as they cannot be violated by the pods created by the library without overrides
 		}
+		violations = append(violations, violation)
 	}
 
-	newResults := evaluator.EvaluatePod(policy.Enforce, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec)
-	for _, result := range newResults {
+	for _, result := range evaluator.EvaluatePod(policy.Enforce, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec) {
 		if !result.Allowed {
-			// This is an assertion for future developers, and should never happen in production
-			// This could happen during development if some unsecure fields are added from `getPodTemplateSpec` or `GetContainers`/`GetInitContainers`
-			return nil, fmt.Errorf("error patching pod for Pod Security Admission. The folowing policy is still not respected: %s (%s)", result.ForbiddenReason, result.ForbiddenDetail)
+			return nil, nil, fmt.Errorf("the pod cannot be patched to respect the %s level of the Pod Security Admission policy: %s (%s)",
+				policy.Enforce.Level, result.ForbiddenReason, result.ForbiddenDetail)
 		}
 	}
+	return podTemplateSpec, violations, nil
+}
 
-	return podTemplateSpec, nil
+// getPolicyReport returns the report of the patched violations, followed by the violations of the enforce, warn and
+// audit levels of the policy by the final pod template, once the pod and container overrides are applied
+func getPolicyReport(podTemplateSpec *corev1.PodTemplateSpec, policy psaapi.Policy, patched []PolicyViolation) (*PolicyReport, error) {
+	evaluator, err := psapolicy.NewEvaluator(psapolicy.DefaultChecks())
+	if err != nil {
+		return nil, err
+	}
+	report := &PolicyReport{Violations: patched}
+	for _, level := range []struct {
+		mode         PolicyMode
+		levelVersion psaapi.LevelVersion
+	}{
+		{EnforcePolicyMode, policy.Enforce},
+		{WarnPolicyMode, policy.Warn},
+		{AuditPolicyMode, policy.Audit},
+	} {
+		for _, result := range evaluator.EvaluatePod(level.levelVersion, &podTemplateSpec.ObjectMeta, &podTemplateSpec.Spec) {
+			if result.Allowed {
+				continue
+			}
+			report.Violations = append(report.Violations, PolicyViolation{
+				Mode:   level.mode,
+				Level:  level.levelVersion.Level,
+				Reason: result.ForbiddenReason,
+				Detail: result.ForbiddenDetail,
+			})
+		}
+	}
+	return report, nil
 }
 
-func patchAllowPrivilegeEscalation(podTemplateSpec *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
-	visitContainers(podTemplateSpec, func(container *corev1.Container) {
+func patchAllowPrivilegeEscalation(podTemplateSpec *corev1.PodTemplateSpec) []string {
+	var patched []string
+	visitContainersWithPath(podTemplateSpec, func(path *field.Path, container *corev1.Container) {
 		if container.SecurityContext == nil {
 			container.SecurityContext = &corev1.SecurityContext{}
 		}
+		if allow := container.SecurityContext.AllowPrivilegeEscalation; allow != nil && !*allow {
+			return
+		}
 		container.SecurityContext.AllowPrivilegeEscalation = pointer.Bool(false)
+		patched = append(patched, path.Child("securityContext", "allowPrivilegeEscalation").String())
 	})
-	return podTemplateSpec
+	return patched
 }
 
-func patchUnrestrictedCapabilities(podTemplateSpec *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
-	visitContainers(podTemplateSpec, func(container *corev1.Container) {
+func patchUnrestrictedCapabilities(podTemplateSpec *corev1.PodTemplateSpec) []string {
+	var patched []string
+	visitContainersWithPath(podTemplateSpec, func(path *field.Path, container *corev1.Container) {
 		if container.SecurityContext == nil {
 			container.SecurityContext = &corev1.SecurityContext{}
 		}
 		if container.SecurityContext.Capabilities == nil {
 			container.SecurityContext.Capabilities = &corev1.Capabilities{}
 		}
+		for _, capability := range container.SecurityContext.Capabilities.Drop {
+			if capability == "ALL" {
+				return
+			}
+		}
 		container.SecurityContext.Capabilities.Drop = append(container.SecurityContext.Capabilities.Drop, "ALL")
+		patched = append(patched, path.Child("securityContext", "capabilities", "drop").String())
 	})
-	return podTemplateSpec
+	return patched
 }
 
-func patchRunAsNonRoot(podTemplateSpec *corev1.PodTemplateSpec) *corev1.PodTemplateSpec {
+func patchRunAsNonRoot(podTemplateSpec *corev1.PodTemplateSpec) []string {
 	if podTemplateSpec.Spec.SecurityContext == nil {
 		podTemplateSpec.Spec.SecurityContext = &corev1.PodSecurityContext{}
 	}
 	podTemplateSpec.Spec.SecurityContext.RunAsNonRoot = pointer.Bool(true)
 	// No need to set the value as true for containers, as setting at the Pod level is sufficient
-	return podTemplateSpec
+	return []string{field.NewPath("spec", "securityContext", "runAsNonRoot").String()}
 }
 
-func patchSeccompProfile(podTemplateSpec *corev1.PodTemplateSpec, level psaapi.Level) *corev1.PodTemplateSpec {
+func patchSeccompProfile(podTemplateSpec *corev1.PodTemplateSpec, level psaapi.Level) []string {
+	var patched []string
 	if level == api.LevelRestricted {
 		if podTemplateSpec.Spec.SecurityContext == nil {
 			podTemplateSpec.Spec.SecurityContext = &corev1.PodSecurityContext{}
@@ -117,15 +209,18 @@ func patchSeccompProfile(podTemplateSpec *corev1.PodTemplateSpec, level psaapi.L
 			podTemplateSpec.Spec.SecurityContext.SeccompProfile = &corev1.SeccompProfile{}
 		}
 		podTemplateSpec.Spec.SecurityContext.SeccompProfile.Type = "RuntimeDefault"
+		patched = append(patched, field.NewPath("spec", "securityContext", "seccompProfile", "type").String())
 	} else if level == api.LevelBaseline {
-		visitContainers(podTemplateSpec, func(container *corev1.Container) {
+		visitContainersWithPath(podTemplateSpec, func(path *field.Path, container *corev1.Container) {
 			if container.SecurityContext != nil && container.SecurityContext.SeccompProfile != nil && container.SecurityContext.SeccompProfile.Type == "Unconfined" {
 				container.SecurityContext.SeccompProfile = nil
+				patched = append(patched, path.Child("securityContext", "seccompProfile").String())
 			}
 		})
 		if podTemplateSpec.Spec.SecurityContext != nil && podTemplateSpec.Spec.SecurityContext.SeccompProfile != nil && podTemplateSpec.Spec.SecurityContext.SeccompProfile.Type == "Unconfined" {
 			podTemplateSpec.Spec.SecurityContext.SeccompProfile = nil
+			patched = append(patched, field.NewPath("spec", "securityContext", "seccompProfile").String())
 		}
 	}
-	return podTemplateSpec
+	return patched
 }
@@ -0,0 +1,216 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	psaapi "k8s.io/pod-security-admission/api"
+)
+
+func TestGetPodTemplateSpecWithPolicyReport(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: policies
+components:
+  - name: init
+    container:
+      image: init
+  - name: web
+    container:
+      image: web
+events:
+  preStart: [init-command]
+commands:
+  - id: init-command
+    apply:
+      component: init
+`)})
+	restricted := psaapi.LevelVersion{Level: psaapi.LevelRestricted, Version: psaapi.LatestVersion()}
+	baseline := psaapi.LevelVersion{Level: psaapi.LevelBaseline, Version: psaapi.LatestVersion()}
+	tests := []struct {
+		name        string
+		policy      psaapi.Policy
+		dryRun      bool
+		wantReport  *PolicyReport
+		wantPatched bool
+	}{
+		{
+			name:       "no policy",
+			wantReport: &PolicyReport{},
+		},
+		{
+			name:   "restricted enforce level",
+			policy: psaapi.Policy{Enforce: restricted},
+			wantReport: &PolicyReport{Violations: []PolicyViolation{
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "allowPrivilegeEscalation != false",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.allowPrivilegeEscalation=false`,
+					PatchedFields: []string{
+						"spec.initContainers[0].securityContext.allowPrivilegeEscalation",
+						"spec.containers[0].securityContext.allowPrivilegeEscalation",
+					},
+				},
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "unrestricted capabilities",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.capabilities.drop=["ALL"]`,
+					PatchedFields: []string{
+						"spec.initContainers[0].securityContext.capabilities.drop",
+						"spec.containers[0].securityContext.capabilities.drop",
+					},
+				},
+				{
+					Mode:          EnforcePolicyMode,
+					Level:         psaapi.LevelRestricted,
+					Reason:        "runAsNonRoot != true",
+					Detail:        `pod or containers "init-init-command-1", "web" must set securityContext.runAsNonRoot=true`,
+					PatchedFields: []string{"spec.securityContext.runAsNonRoot"},
+				},
+				{
+					Mode:          EnforcePolicyMode,
+					Level:         psaapi.LevelRestricted,
+					Reason:        "seccompProfile",
+					Detail:        `pod or containers "init-init-command-1", "web" must set securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"`,
+					PatchedFields: []string{"spec.securityContext.seccompProfile.type"},
+				},
+			}},
+			wantPatched: true,
+		},
+		{
+			name:   "dry run",
+			policy: psaapi.Policy{Enforce: restricted},
+			dryRun: true,
+			wantReport: &PolicyReport{Violations: []PolicyViolation{
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "allowPrivilegeEscalation != false",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.allowPrivilegeEscalation=false`,
+				},
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "unrestricted capabilities",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.capabilities.drop=["ALL"]`,
+				},
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "runAsNonRoot != true",
+					Detail: `pod or containers "init-init-command-1", "web" must set securityContext.runAsNonRoot=true`,
+				},
+				{
+					Mode:   EnforcePolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "seccompProfile",
+					Detail: `pod or containers "init-init-command-1", "web" must set securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"`,
+				},
+			}},
+		},
+		{
+			name:   "warn and audit levels",
+			policy: psaapi.Policy{Enforce: baseline, Warn: restricted, Audit: baseline},
+			wantReport: &PolicyReport{Violations: []PolicyViolation{
+				{
+					Mode:   WarnPolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "allowPrivilegeEscalation != false",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.allowPrivilegeEscalation=false`,
+				},
+				{
+					Mode:   WarnPolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "unrestricted capabilities",
+					Detail: `containers "init-init-command-1", "web" must set securityContext.capabilities.drop=["ALL"]`,
+				},
+				{
+					Mode:   WarnPolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "runAsNonRoot != true",
+					Detail: `pod or containers "init-init-command-1", "web" must set securityContext.runAsNonRoot=true`,
+				},
+				{
+					Mode:   WarnPolicyMode,
+					Level:  psaapi.LevelRestricted,
+					Reason: "seccompProfile",
+					Detail: `pod or containers "init-init-command-1", "web" must set securityContext.seccompProfile.type to "RuntimeDefault" or "Localhost"`,
+				},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podTemplateSpec, report, err := GetPodTemplateSpecWithPolicyReport(devfileObj, PodTemplateParams{
+				PodSecurityAdmissionPolicy: tt.policy,
+				PodSecurityAdmissionDryRun: tt.dryRun,
+			})
+			if err != nil {
+				t.Fatalf("GetPodTemplateSpecWithPolicyReport() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantReport, report); diff != "" {
+				t.Errorf("GetPodTemplateSpecWithPolicyReport() report mismatch (-want +got):\n%s", diff)
+			}
+			if patched := podTemplateSpec.Spec.SecurityContext != nil; patched != tt.wantPatched {
+				t.Errorf("GetPodTemplateSpecWithPolicyReport() patched the pod: %v, want %v", patched, tt.wantPatched)
+			}
+		})
+	}
+}
+
+func TestGetPodTemplateSpecWithPolicyReportAfterOverrides(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: policies
+components:
+  - name: web
+    attributes:
+      container-overrides:
+        securityContext:
+          privileged: true
+    container:
+      image: web
+`)})
+	baseline := psaapi.LevelVersion{Level: psaapi.LevelBaseline, Version: psaapi.LatestVersion()}
+	want := &PolicyReport{Violations: []PolicyViolation{
+		{
+			Mode:   EnforcePolicyMode,
+			Level:  psaapi.LevelBaseline,
+			Reason: "privileged",
+			Detail: `container "web" must not set securityContext.privileged=true`,
+		},
+		{
+			Mode:   AuditPolicyMode,
+			Level:  psaapi.LevelBaseline,
+			Reason: "privileged",
+			Detail: `container "web" must not set securityContext.privileged=true`,
+		},
+	}}
+	_, report, err := GetPodTemplateSpecWithPolicyReport(devfileObj, PodTemplateParams{
+		PodSecurityAdmissionPolicy: psaapi.Policy{Enforce: baseline, Audit: baseline},
+	})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpecWithPolicyReport() unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("GetPodTemplateSpecWithPolicyReport() report mismatch (-want +got):\n%s", diff)
+	}
+}
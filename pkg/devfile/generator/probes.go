@@ -0,0 +1,248 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ProbeAttribute is the endpoint attribute configuring the probes of its container. Its value is either the probe type,
+// or a ProbeConfig object:
+//
+//	attributes:
+//	  probe:
+//	    type: http
+//	    path: /healthz
+//	    kinds: [readiness, liveness, startup]
+//	    periodSeconds: 5
+const ProbeAttribute = "probe"
+
+// ProbeType is the type of the probes generated for an endpoint
+type ProbeType string
+
+const (
+	// HTTPProbe checks the endpoint with an HTTP GET request, using the HTTPS scheme for https and wss endpoints
+	HTTPProbe ProbeType = "http"
+	// TCPProbe checks that the endpoint port is open
+	TCPProbe ProbeType = "tcp"
+	// ExecProbe runs an exec command of the devfile in the container
+	ExecProbe ProbeType = "exec"
+	// NoProbe disables the probes for the endpoint
+	NoProbe ProbeType = "none"
+)
+
+// ProbeKind is a kind of probe of a container
+type ProbeKind string
+
+const (
+	// ReadinessProbe is set as the readinessProbe of the container
+	ReadinessProbe ProbeKind = "readiness"
+	// LivenessProbe is set as the livenessProbe of the container
+	LivenessProbe ProbeKind = "liveness"
+	// StartupProbe is set as the startupProbe of the container
+	StartupProbe ProbeKind = "startup"
+)
+
+// ProbeConfig is the configuration of the probes of an endpoint, set by ProbeAttribute
+type ProbeConfig struct {
+	// Type is the type of the probes. Defaults to HTTPProbe for http and https endpoints, and to TCPProbe for
+	// tcp, ws and wss endpoints. Other endpoints have no probe by default.
+	Type ProbeType `json:"type,omitempty"`
+	// Path is the path of HTTP probes. Defaults to the path of the endpoint, or /.
+	Path string `json:"path,omitempty"`
+	// Command is the id of the exec command run by exec probes
+	Command string `json:"command,omitempty"`
+	// Kinds are the kinds of probes generated. Defaults to readiness and liveness probes.
+	Kinds []ProbeKind `json:"kinds,omitempty"`
+
+	// Timings of the probes, the Kubernetes defaults apply to the unset ones
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty"`
+}
+
+// addProbes sets the probes of the containers from the endpoints of their components.
+// The probes of a container are generated for the endpoint with ProbeAttribute, or else for its first endpoint
+// with a protocol defining a default probe type. Only one endpoint of a container can set ProbeAttribute.
+func addProbes(devfileObj parser.DevfileObj, containers []corev1.Container) error {
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return err
+	}
+	commands, err := devfileObj.Data.GetCommands(common.DevfileOptions{})
+	if err != nil {
+		return err
+	}
+	commandsMap := common.GetCommandsMap(commands)
+
+	components := make(map[string]v1.Component)
+	for _, comp := range containerComponents {
+		components[comp.Name] = comp
+	}
+	for i := range containers {
+		comp, ok := components[containers[i].Name]
+		if !ok {
+			continue
+		}
+		endpoint, config, err := getProbeConfig(comp)
+		if err != nil {
+			return err
+		}
+		if endpoint == nil {
+			continue
+		}
+		handler, err := getProbeHandler(comp, *endpoint, config, commandsMap)
+		if err != nil {
+			return err
+		}
+
+		kinds := config.Kinds
+		if len(kinds) == 0 {
+			kinds = []ProbeKind{ReadinessProbe, LivenessProbe}
+		}
+		for _, kind := range kinds {
+			probe := &corev1.Probe{
+				ProbeHandler:        *handler.DeepCopy(),
+				InitialDelaySeconds: config.InitialDelaySeconds,
+				PeriodSeconds:       config.PeriodSeconds,
+				TimeoutSeconds:      config.TimeoutSeconds,
+				SuccessThreshold:    config.SuccessThreshold,
+				FailureThreshold:    config.FailureThreshold,
+			}
+			switch kind {
+			case ReadinessProbe:
+				containers[i].ReadinessProbe = probe
+			case LivenessProbe:
+				containers[i].LivenessProbe = probe
+			case StartupProbe:
+				containers[i].StartupProbe = probe
+			default:
+				return fmt.Errorf("endpoint %s of component %s: unknown probe kind %q", endpoint.Name, comp.Name, kind)
+			}
+		}
+	}
+	return nil
+}
+
+// getProbeConfig returns the endpoint the probes of the container component are generated for, with its probe
+// configuration, or a nil endpoint if the container has no probe
+func getProbeConfig(comp v1.Component) (*v1.Endpoint, ProbeConfig, error) {
+	var probeEndpoint *v1.Endpoint
+	var probeConfig ProbeConfig
+	for i := range comp.Container.Endpoints {
+		endpoint := &comp.Container.Endpoints[i]
+		if !endpoint.Attributes.Exists(ProbeAttribute) {
+			continue
+		}
+		if probeEndpoint != nil {
+			return nil, ProbeConfig{}, fmt.Errorf("endpoints %s and %s of component %s both configure probes, only one endpoint of a container can",
+				probeEndpoint.Name, endpoint.Name, comp.Name)
+		}
+		var config ProbeConfig
+		if err := endpoint.Attributes.GetInto(ProbeAttribute, &config); err != nil {
+			var probeType ProbeType
+			if err = endpoint.Attributes.GetInto(ProbeAttribute, &probeType); err != nil {
+				return nil, ProbeConfig{}, fmt.Errorf("invalid %s attribute of endpoint %s of component %s, a probe type or an object is expected",
+					ProbeAttribute, endpoint.Name, comp.Name)
+			}
+			config.Type = probeType
+		}
+		probeEndpoint, probeConfig = endpoint, config
+	}
+
+	if probeEndpoint == nil {
+		for i := range comp.Container.Endpoints {
+			if getDefaultProbeType(comp.Container.Endpoints[i]) != NoProbe {
+				probeEndpoint = &comp.Container.Endpoints[i]
+				break
+			}
+		}
+	}
+	if probeEndpoint == nil {
+		return nil, ProbeConfig{}, nil
+	}
+	if probeConfig.Type == "" {
+		probeConfig.Type = getDefaultProbeType(*probeEndpoint)
+	}
+	if probeConfig.Type == NoProbe {
+		return nil, ProbeConfig{}, nil
+	}
+	return probeEndpoint, probeConfig, nil
+}
+
+// getDefaultProbeType returns the type of the probes of the endpoint, derived from its protocol
+func getDefaultProbeType(endpoint v1.Endpoint) ProbeType {
+	switch endpoint.Protocol {
+	case v1.HTTPEndpointProtocol, v1.HTTPSEndpointProtocol, "":
+		return HTTPProbe
+	case v1.TCPEndpointProtocol, v1.WSEndpointProtocol, v1.WSSEndpointProtocol:
+		return TCPProbe
+	default:
+		return NoProbe
+	}
+}
+
+// getProbeHandler returns the handler of the probes of the endpoint
+func getProbeHandler(comp v1.Component, endpoint v1.Endpoint, config ProbeConfig, commandsMap map[string]v1.Command) (*corev1.ProbeHandler, error) {
+	switch config.Type {
+	case HTTPProbe:
+		path := config.Path
+		if path == "" {
+			path = endpoint.Path
+		}
+		if path == "" {
+			path = "/"
+		}
+		scheme := corev1.URISchemeHTTP
+		if endpoint.Protocol == v1.HTTPSEndpointProtocol || endpoint.Protocol == v1.WSSEndpointProtocol {
+			scheme = corev1.URISchemeHTTPS
+		}
+		return &corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path:   path,
+				Port:   intstr.FromInt(endpoint.TargetPort),
+				Scheme: scheme,
+			},
+		}, nil
+	case TCPProbe:
+		return &corev1.ProbeHandler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(endpoint.TargetPort)},
+		}, nil
+	case ExecProbe:
+		command, ok := commandsMap[config.Command]
+		if !ok || command.Exec == nil {
+			return nil, fmt.Errorf("endpoint %s of component %s: the probe command %q is not an exec command of the devfile", endpoint.Name, comp.Name, config.Command)
+		}
+		if command.Exec.Component != comp.Name {
+			return nil, fmt.Errorf("endpoint %s of component %s: the probe command %s runs in component %s", endpoint.Name, comp.Name, config.Command, command.Exec.Component)
+		}
+		return &corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{
+				Command: append(append([]string{}, shellCommand...), getExecScript(command.Exec)),
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("endpoint %s of component %s: unknown probe type %q", endpoint.Name, comp.Name, config.Type)
+	}
+}
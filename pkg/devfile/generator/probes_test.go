@@ -0,0 +1,192 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGetPodTemplateSpecProbes(t *testing.T) {
+	devfile := `schemaVersion: 2.2.0
+metadata:
+  name: probes
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+        - name: debug
+          targetPort: 5858
+          protocol: udp
+        - name: https
+          targetPort: 8443
+          protocol: https
+          path: /app
+  - name: api
+    container:
+      image: api
+      endpoints:
+        - name: http
+          targetPort: 8080
+        - name: health
+          targetPort: 8081
+          attributes:
+            probe:
+              path: /healthz
+              kinds: [readiness, startup]
+              periodSeconds: 5
+              failureThreshold: 30
+  - name: db
+    attributes:
+      container-overrides:
+        livenessProbe:
+          initialDelaySeconds: 30
+    container:
+      image: db
+      endpoints:
+        - name: postgres
+          targetPort: 5432
+          protocol: tcp
+  - name: worker
+    container:
+      image: worker
+      endpoints:
+        - name: metrics
+          targetPort: 9090
+          attributes:
+            probe:
+              type: exec
+              command: check
+  - name: sidecar
+    container:
+      image: sidecar
+      endpoints:
+        - name: http
+          targetPort: 3000
+          attributes:
+            probe: none
+commands:
+  - id: check
+    exec:
+      component: worker
+      commandLine: ./check.sh
+`
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(devfile)})
+	podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{Probes: true})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+	}
+
+	type probes struct {
+		Readiness, Liveness, Startup *corev1.Probe
+	}
+	got := make(map[string]probes)
+	for _, container := range podTemplateSpec.Spec.Containers {
+		got[container.Name] = probes{container.ReadinessProbe, container.LivenessProbe, container.StartupProbe}
+	}
+
+	httpsProbe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{Path: "/app", Port: intstr.FromInt(8443), Scheme: corev1.URISchemeHTTPS},
+	}}
+	healthProbe := &corev1.Probe{
+		ProbeHandler: corev1.ProbeHandler{
+			HTTPGet: &corev1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8081), Scheme: corev1.URISchemeHTTP},
+		},
+		PeriodSeconds:    5,
+		FailureThreshold: 30,
+	}
+	tcpProbe := corev1.ProbeHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(5432)}}
+	execProbe := &corev1.Probe{ProbeHandler: corev1.ProbeHandler{
+		Exec: &corev1.ExecAction{Command: []string{"/bin/sh", "-c", "./check.sh"}},
+	}}
+	want := map[string]probes{
+		"web": {Readiness: httpsProbe, Liveness: httpsProbe},
+		"api": {Readiness: healthProbe, Startup: healthProbe},
+		"db": {
+			Readiness: &corev1.Probe{ProbeHandler: tcpProbe},
+			Liveness:  &corev1.Probe{ProbeHandler: tcpProbe, InitialDelaySeconds: 30},
+		},
+		"worker":  {Readiness: execProbe, Liveness: execProbe},
+		"sidecar": {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetPodTemplateSpec() probes mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetPodTemplateSpecProbesErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints string
+		wantErr   string
+	}{
+		{
+			name: "several endpoints with probes",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            probe: http
+        - name: admin
+          targetPort: 8081
+          attributes:
+            probe: tcp
+`,
+			wantErr: "endpoints http and admin of component web both configure probes, only one endpoint of a container can",
+		},
+		{
+			name: "unknown exec command",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            probe:
+              type: exec
+              command: check
+`,
+			wantErr: `endpoint http of component web: the probe command "check" is not an exec command of the devfile`,
+		},
+		{
+			name: "unknown probe type",
+			endpoints: `        - name: http
+          targetPort: 8080
+          attributes:
+            probe: grpc
+`,
+			wantErr: `endpoint http of component web: unknown probe type "grpc"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: probes
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+` + tt.endpoints)})
+			_, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{Probes: true})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("GetPodTemplateSpec() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultProjectsVolumeName is the default name of the volume holding the projects in the pod
+	DefaultProjectsVolumeName = "projects"
+	// DefaultProjectCloneImage is the default image of the init container cloning the projects.
+	// The image must provide a shell, git, wget and unzip.
+	DefaultProjectCloneImage = "docker.io/alpine/git:latest"
+	// ProjectCloneContainerName is the name of the init container cloning the projects
+	ProjectCloneContainerName = "project-clone"
+)
+
+// ProjectsVolume is the volume holding the projects of the devfile, mounted at the sourceMapping of the containers
+// with mountSources
+type ProjectsVolume struct {
+	// Name is the name of the volume in the pod. Defaults to DefaultProjectsVolumeName.
+	Name string
+	// PVCName is the name of an existing PVC backing the volume. The volume is an emptyDir volume if empty.
+	PVCName string
+	// CloneProjects adds an init container cloning the git projects, and extracting the zip projects, into the volume.
+	// Projects already present in the volume are left untouched.
+	CloneProjects bool
+	// CloneImage is the image of the init container cloning the projects. Defaults to DefaultProjectCloneImage.
+	CloneImage string
+}
+
+// addProjectsVolume adds the projects volume to the pod, if any of its containers mounts the sources, and mounts it
+// in these containers at the path of their PROJECTS_ROOT environment variable, i.e. their sourceMapping.
+// If projectsVolume.CloneProjects is set, the init container cloning the projects runs before the other init containers.
+func addProjectsVolume(devfileObj parser.DevfileObj, projectsVolume ProjectsVolume, params *podTemplateSpecParams) error {
+	volumeName := projectsVolume.Name
+	if volumeName == "" {
+		volumeName = DefaultProjectsVolumeName
+	}
+
+	mounted := false
+	for _, containers := range [][]corev1.Container{params.Containers, params.InitContainers} {
+		for i := range containers {
+			if projectsRoot, ok := getEnvValue(containers[i], EnvProjectsRoot); ok {
+				containers[i].VolumeMounts = append(containers[i].VolumeMounts, corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: projectsRoot,
+				})
+				mounted = true
+			}
+		}
+	}
+	if !mounted {
+		return nil
+	}
+
+	volume := corev1.Volume{Name: volumeName}
+	if projectsVolume.PVCName != "" {
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: projectsVolume.PVCName}
+	} else {
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	}
+	params.Volumes = append(params.Volumes, volume)
+
+	if !projectsVolume.CloneProjects {
+		return nil
+	}
+	cloneContainer, err := getProjectCloneContainer(devfileObj, projectsVolume, volumeName)
+	if err != nil {
+		return err
+	}
+	if cloneContainer != nil {
+		params.InitContainers = append([]corev1.Container{*cloneContainer}, params.InitContainers...)
+	}
+	return nil
+}
+
+// getProjectCloneContainer returns the init container cloning the projects of the devfile into the projects volume,
+// or nil if the devfile has no project
+func getProjectCloneContainer(devfileObj parser.DevfileObj, projectsVolume ProjectsVolume, volumeName string) (*corev1.Container, error) {
+	projects, err := devfileObj.Data.GetProjects(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) == 0 {
+		return nil, nil
+	}
+
+	var scripts []string
+	for _, project := range projects {
+		projectPath, err := getProjectPath(DevfileSourceVolumeMount, project)
+		if err != nil {
+			return nil, err
+		}
+		script, err := getProjectCloneScript(project, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		scripts = append(scripts, fmt.Sprintf("if [ ! -e %s ]; then %s; fi", shellSingleQuote(projectPath), script))
+	}
+
+	image := projectsVolume.CloneImage
+	if image == "" {
+		image = DefaultProjectCloneImage
+	}
+	container := getContainer(containerParams{
+		Name:    ProjectCloneContainerName,
+		Image:   image,
+		Command: append(append([]string{}, shellCommand...), strings.Join(append([]string{"set -e"}, scripts...), "\n")),
+		EnvVars: []corev1.EnvVar{{Name: EnvProjectsRoot, Value: DevfileSourceVolumeMount}},
+	})
+	container.VolumeMounts = []corev1.VolumeMount{{Name: volumeName, MountPath: DevfileSourceVolumeMount}}
+	return container, nil
+}
+
+// getProjectCloneScript returns the shell script cloning the project at the given path
+func getProjectCloneScript(project v1.Project, projectPath string) (string, error) {
+	path := shellSingleQuote(projectPath)
+	switch {
+	case project.Git != nil:
+		git := project.Git
+		if len(git.Remotes) == 0 {
+			return "", fmt.Errorf("project %s has no git remote", project.Name)
+		}
+		remoteNames := make([]string, 0, len(git.Remotes))
+		for name := range git.Remotes {
+			remoteNames = append(remoteNames, name)
+		}
+		sort.Strings(remoteNames)
+
+		var revision string
+		remote := remoteNames[0]
+		if git.CheckoutFrom != nil {
+			revision = git.CheckoutFrom.Revision
+			if git.CheckoutFrom.Remote != "" {
+				remote = git.CheckoutFrom.Remote
+			}
+		}
+		if _, ok := git.Remotes[remote]; !ok {
+			return "", fmt.Errorf("project %s checks out from remote %s, which is not defined", project.Name, remote)
+		}
+		if len(git.Remotes) > 1 && (git.CheckoutFrom == nil || git.CheckoutFrom.Remote == "") {
+			return "", fmt.Errorf("project %s has several git remotes, checkoutFrom.remote is required", project.Name)
+		}
+
+		commands := []string{fmt.Sprintf("git clone --origin %s %s %s", shellSingleQuote(remote), shellSingleQuote(git.Remotes[remote]), path)}
+		for _, name := range remoteNames {
+			if name != remote {
+				commands = append(commands, fmt.Sprintf("git -C %s remote add %s %s", path, shellSingleQuote(name), shellSingleQuote(git.Remotes[name])))
+			}
+		}
+		if revision != "" {
+			commands = append(commands, fmt.Sprintf("git -C %s checkout %s", path, shellSingleQuote(revision)))
+		}
+		return strings.Join(commands, " && "), nil
+	case project.Zip != nil:
+		if project.Zip.Location == "" {
+			return "", fmt.Errorf("project %s has no zip location", project.Name)
+		}
+		archive := shellSingleQuote(fmt.Sprintf("/tmp/%s.zip", project.Name))
+		return fmt.Sprintf("wget -q -O %s %s && mkdir -p %s && unzip -q %s -d %s && rm %s",
+			archive, shellSingleQuote(project.Zip.Location), path, archive, path, archive), nil
+	default:
+		return "", fmt.Errorf("project %s cannot be cloned, only git and zip projects are supported", project.Name)
+	}
+}
+
+// getEnvValue returns the value of the environment variable of the container
+func getEnvValue(container corev1.Container, name string) (string, bool) {
+	for _, env := range container.Env {
+		if env.Name == name {
+			return env.Value, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,147 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const projectsDevfile = `schemaVersion: 2.2.0
+metadata:
+  name: projects
+projects:
+  - name: app
+    clonePath: src/app
+    git:
+      checkoutFrom:
+        remote: upstream
+        revision: v1.0
+      remotes:
+        origin: https://github.com/me/app.git
+        upstream: https://github.com/org/app.git
+  - name: docs
+    zip:
+      location: https://example.com/docs.zip
+components:
+  - name: runtime
+    container:
+      image: node
+      sourceMapping: /src
+  - name: tools
+    container:
+      image: tools
+      mountSources: false
+  - name: setup
+    container:
+      image: setup
+commands:
+  - id: prepare
+    apply:
+      component: setup
+events:
+  preStart: [prepare]
+`
+
+func TestGetPodTemplateSpecProjectsVolume(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(projectsDevfile)})
+
+	tests := []struct {
+		name               string
+		projectsVolume     *ProjectsVolume
+		wantVolumes        []corev1.Volume
+		wantMounts         map[string][]corev1.VolumeMount
+		wantInitContainers []string
+	}{
+		{
+			name:               "without projects volume",
+			wantMounts:         map[string][]corev1.VolumeMount{},
+			wantInitContainers: []string{"setup-prepare-1"},
+		},
+		{
+			name:           "emptyDir volume",
+			projectsVolume: &ProjectsVolume{},
+			wantVolumes: []corev1.Volume{
+				{Name: "projects", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+			},
+			wantMounts: map[string][]corev1.VolumeMount{
+				"runtime":         {{Name: "projects", MountPath: "/src"}},
+				"setup-prepare-1": {{Name: "projects", MountPath: "/projects"}},
+			},
+			wantInitContainers: []string{"setup-prepare-1"},
+		},
+		{
+			name:           "PVC volume with clone",
+			projectsVolume: &ProjectsVolume{Name: "sources", PVCName: "my-sources", CloneProjects: true},
+			wantVolumes: []corev1.Volume{
+				{Name: "sources", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "my-sources"}}},
+			},
+			wantMounts: map[string][]corev1.VolumeMount{
+				"runtime":         {{Name: "sources", MountPath: "/src"}},
+				"project-clone":   {{Name: "sources", MountPath: "/projects"}},
+				"setup-prepare-1": {{Name: "sources", MountPath: "/projects"}},
+			},
+			wantInitContainers: []string{"project-clone", "setup-prepare-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podTemplateSpec, err := GetPodTemplateSpec(devfileObj, PodTemplateParams{ProjectsVolume: tt.projectsVolume})
+			if err != nil {
+				t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantVolumes, podTemplateSpec.Spec.Volumes); diff != "" {
+				t.Errorf("GetPodTemplateSpec() volumes mismatch (-want +got):\n%s", diff)
+			}
+			gotMounts := make(map[string][]corev1.VolumeMount)
+			var gotInitContainers []string
+			for _, container := range append(podTemplateSpec.Spec.InitContainers, podTemplateSpec.Spec.Containers...) {
+				if len(container.VolumeMounts) > 0 {
+					gotMounts[container.Name] = container.VolumeMounts
+				}
+			}
+			for _, container := range podTemplateSpec.Spec.InitContainers {
+				gotInitContainers = append(gotInitContainers, container.Name)
+			}
+			if diff := cmp.Diff(tt.wantMounts, gotMounts); diff != "" {
+				t.Errorf("GetPodTemplateSpec() volume mounts mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantInitContainers, gotInitContainers); diff != "" {
+				t.Errorf("GetPodTemplateSpec() init containers mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetProjectCloneContainer(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(projectsDevfile)})
+	container, err := getProjectCloneContainer(devfileObj, ProjectsVolume{CloneImage: "git"}, "projects")
+	if err != nil {
+		t.Fatalf("getProjectCloneContainer() unexpected error: %v", err)
+	}
+	want := []string{"/bin/sh", "-c", `set -e
+if [ ! -e '/projects/src/app' ]; then git clone --origin 'upstream' 'https://github.com/org/app.git' '/projects/src/app' && git -C '/projects/src/app' remote add 'origin' 'https://github.com/me/app.git' && git -C '/projects/src/app' checkout 'v1.0'; fi
+if [ ! -e '/projects/docs' ]; then wget -q -O '/tmp/docs.zip' 'https://example.com/docs.zip' && mkdir -p '/projects/docs' && unzip -q '/tmp/docs.zip' -d '/projects/docs' && rm '/tmp/docs.zip'; fi`}
+	if diff := cmp.Diff(want, container.Command); diff != "" {
+		t.Errorf("getProjectCloneContainer() command mismatch (-want +got):\n%s", diff)
+	}
+	if container.Image != "git" {
+		t.Errorf("getProjectCloneContainer() image = %s, want git", container.Image)
+	}
+}
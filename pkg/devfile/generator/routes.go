@@ -0,0 +1,210 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"regexp"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Attributes of the public endpoints configuring their Route
+const (
+	// RouteHostAttribute is the host of the Route, or a host template as described by ExpandRouteHostTemplate
+	RouteHostAttribute = "route-host"
+	// RouteTLSTerminationAttribute is the TLS termination of the Route: edge, reencrypt or passthrough.
+	// The Route is served over TLS if set, and defaults to edge for secure endpoints.
+	RouteTLSTerminationAttribute = "route-tls-termination"
+	// RouteInsecurePolicyAttribute is the policy for insecure traffic of a Route served over TLS: None, Allow or Redirect.
+	// Defaults to Redirect.
+	RouteInsecurePolicyAttribute = "route-insecure-policy"
+	// RouteTLSSecretAttribute is the name of the TLS Secret holding the certificate, the key and optionally the CA
+	// certificate served by an edge or reencrypt Route, under the tls.crt, tls.key and ca.crt keys.
+	// As a Route holds its certificate and key inline, the key is copied into the Route, which must be allowed
+	// with EndpointRouteParams.InlineTLSKey.
+	RouteTLSSecretAttribute = "route-tls-secret"
+	// RouteDestinationCASecretAttribute is the name of the Secret holding the CA certificate validating the certificate
+	// of the endpoint for a reencrypt Route, under the ca.crt key
+	RouteDestinationCASecretAttribute = "route-destination-ca-secret"
+	// RouteWildcardPolicyAttribute is the wildcard policy of the Route: None or Subdomain
+	RouteWildcardPolicyAttribute = "route-wildcard-policy"
+)
+
+// caCertKey is the key of the CA certificate in a Secret
+const caCertKey = "ca.crt"
+
+// hostTemplatePlaceholder matches the placeholders of a route host template
+var hostTemplatePlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// SecretGetter returns the Secret with the given name, in the namespace of the generated objects
+type SecretGetter func(name string) (*corev1.Secret, error)
+
+// RouteHostValues are the values of the placeholders of route host templates which do not depend on the endpoint
+type RouteHostValues struct {
+	// Name is the value of {name}, the base name of the generated objects
+	Name string
+	// DevfileName is the value of {devfileName}, the devfile metadata name
+	DevfileName string
+	// Namespace is the value of {namespace}
+	Namespace string
+	// Domain is the value of {domain}
+	Domain string
+}
+
+// EndpointRouteParams is a struct that contains the data needed to compute the route spec params of an endpoint
+type EndpointRouteParams struct {
+	// ServiceName is the name of the Service exposing the endpoint
+	ServiceName string
+	// HostTemplate is the host template of the Route, unless set by RouteHostAttribute. The router generates
+	// the host if both are empty.
+	HostTemplate string
+	// HostValues are the values of the placeholders of the host template
+	HostValues RouteHostValues
+	// GetSecret returns the Secrets referenced by RouteTLSSecretAttribute and RouteDestinationCASecretAttribute
+	GetSecret SecretGetter
+	// InlineTLSKey allows copying the private key of the Secret referenced by RouteTLSSecretAttribute into the Route.
+	// WARNING: the key is then stored in clear in the Route spec and in the generated manifests, where it can be read
+	// by anyone allowed to read Routes or the manifests. Only set it if they are protected as the Secret is.
+	InlineTLSKey bool
+}
+
+// GetEndpointRouteSpecParams returns the spec params of the Route of an endpoint of the given container component,
+// configured by the endpoint attributes, and checks that they define a valid Route.
+// The route is served over TLS with edge termination if the endpoint is secure, unless RouteTLSTerminationAttribute is set.
+func GetEndpointRouteSpecParams(endpoint v1.Endpoint, componentName string, routeParams EndpointRouteParams) (RouteSpecParams, error) {
+	specParams := RouteSpecParams{
+		ServiceName: routeParams.ServiceName,
+		PortNumber:  intstr.FromInt(endpoint.TargetPort),
+		Path:        endpoint.Path,
+		Secure:      endpoint.Secure != nil && *endpoint.Secure,
+	}
+
+	stringAttributes := make(map[string]string)
+	for _, key := range []string{RouteHostAttribute, RouteTLSTerminationAttribute, RouteInsecurePolicyAttribute,
+		RouteTLSSecretAttribute, RouteDestinationCASecretAttribute, RouteWildcardPolicyAttribute} {
+		if !endpoint.Attributes.Exists(key) {
+			continue
+		}
+		value, err := getEndpointStringAttribute(endpoint, key)
+		if err != nil {
+			return RouteSpecParams{}, err
+		}
+		stringAttributes[key] = value
+	}
+
+	hostTemplate := routeParams.HostTemplate
+	if host, ok := stringAttributes[RouteHostAttribute]; ok {
+		hostTemplate = host
+	}
+	if hostTemplate != "" {
+		host, err := ExpandRouteHostTemplate(hostTemplate, endpoint.Name, componentName, routeParams.HostValues)
+		if err != nil {
+			return RouteSpecParams{}, fmt.Errorf("invalid route host of endpoint %s: %w", endpoint.Name, err)
+		}
+		specParams.Host = host
+	}
+	specParams.TLSTermination = routev1.TLSTerminationType(stringAttributes[RouteTLSTerminationAttribute])
+	specParams.InsecureEdgeTerminationPolicy = routev1.InsecureEdgeTerminationPolicyType(stringAttributes[RouteInsecurePolicyAttribute])
+	specParams.WildcardPolicy = routev1.WildcardPolicyType(stringAttributes[RouteWildcardPolicyAttribute])
+
+	if secretName, ok := stringAttributes[RouteTLSSecretAttribute]; ok {
+		if !routeParams.InlineTLSKey {
+			return RouteSpecParams{}, fmt.Errorf("invalid %s attribute of endpoint %s: the private key of Secret %s would be copied into the Route, which is not allowed",
+				RouteTLSSecretAttribute, endpoint.Name, secretName)
+		}
+		data, err := getSecretData(routeParams.GetSecret, secretName, corev1.TLSCertKey, corev1.TLSPrivateKeyKey, caCertKey)
+		if err != nil {
+			return RouteSpecParams{}, fmt.Errorf("invalid %s attribute of endpoint %s: %w", RouteTLSSecretAttribute, endpoint.Name, err)
+		}
+		specParams.Certificate, specParams.Key, specParams.CACertificate = data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey], data[caCertKey]
+		if specParams.Certificate == "" || specParams.Key == "" {
+			return RouteSpecParams{}, fmt.Errorf("invalid %s attribute of endpoint %s: Secret %s has no %s or no %s",
+				RouteTLSSecretAttribute, endpoint.Name, secretName, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+		}
+	}
+	if secretName, ok := stringAttributes[RouteDestinationCASecretAttribute]; ok {
+		data, err := getSecretData(routeParams.GetSecret, secretName, caCertKey)
+		if err != nil {
+			return RouteSpecParams{}, fmt.Errorf("invalid %s attribute of endpoint %s: %w", RouteDestinationCASecretAttribute, endpoint.Name, err)
+		}
+		if specParams.DestinationCACertificate = data[caCertKey]; specParams.DestinationCACertificate == "" {
+			return RouteSpecParams{}, fmt.Errorf("invalid %s attribute of endpoint %s: Secret %s has no %s",
+				RouteDestinationCASecretAttribute, endpoint.Name, secretName, caCertKey)
+		}
+	}
+
+	if err := validateRouteSpecParams(specParams); err != nil {
+		return RouteSpecParams{}, fmt.Errorf("invalid route of endpoint %s: %w", endpoint.Name, err)
+	}
+	return specParams, nil
+}
+
+// ExpandRouteHostTemplate returns the host of a route of an endpoint of the given container component, from a template
+// such as {endpoint}-{devfileName}.{domain}. The placeholders are {endpoint}, {component}, {name}, {devfileName},
+// {namespace} and {domain}, and must have a value.
+func ExpandRouteHostTemplate(template string, endpointName string, componentName string, values RouteHostValues) (string, error) {
+	placeholderValues := map[string]string{
+		"endpoint":    endpointName,
+		"component":   componentName,
+		"name":        values.Name,
+		"devfileName": values.DevfileName,
+		"namespace":   values.Namespace,
+		"domain":      values.Domain,
+	}
+	var err error
+	host := hostTemplatePlaceholder.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		value, ok := placeholderValues[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("unknown placeholder %s in host template %q", placeholder, template)
+			}
+			return placeholder
+		}
+		if value == "" && err == nil {
+			err = fmt.Errorf("placeholder %s of host template %q has no value", placeholder, template)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// getSecretData returns the values of the given keys of a Secret, which are empty for missing keys
+func getSecretData(getSecret SecretGetter, secretName string, keys ...string) (map[string]string, error) {
+	if getSecret == nil {
+		return nil, fmt.Errorf("cannot get Secret %s, no Secret getter is set", secretName)
+	}
+	secret, err := getSecret(secretName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s: %w", secretName, err)
+	}
+	data := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, ok := secret.Data[key]; ok {
+			data[key] = string(value)
+		} else {
+			data[key] = secret.StringData[key]
+		}
+	}
+	return data, nil
+}
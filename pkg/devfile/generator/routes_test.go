@@ -0,0 +1,324 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"testing"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/google/go-cmp/cmp"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func TestGetEndpointRouteSpecParams(t *testing.T) {
+	secrets := map[string]*corev1.Secret{
+		"app-tls": {Data: map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key"), caCertKey: []byte("ca")}},
+		"app-ca":  {Data: map[string][]byte{caCertKey: []byte("destination-ca")}},
+		"no-key":  {Data: map[string][]byte{corev1.TLSCertKey: []byte("cert")}},
+	}
+	getSecret := func(name string) (*corev1.Secret, error) {
+		if secret, ok := secrets[name]; ok {
+			return secret, nil
+		}
+		return nil, fmt.Errorf("secret %s not found", name)
+	}
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		hostTemplate string
+		inlineTLSKey bool
+		wantSpec     *routev1.RouteSpec
+		wantErr      string
+	}{
+		{
+			name: "secure endpoint with host template",
+			endpoint: `        - name: http
+          targetPort: 8080
+          secure: true
+`,
+			hostTemplate: "{endpoint}-{devfileName}.{domain}",
+			wantSpec: &routev1.RouteSpec{
+				Host: "http-routes.apps.example.com",
+				To:   routev1.RouteTargetReference{Kind: "Service", Name: "app"},
+				Port: &routev1.RoutePort{TargetPort: intstr.FromInt(8080)},
+				Path: "/",
+				TLS: &routev1.TLSConfig{
+					Termination:                   routev1.TLSTerminationEdge,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+				},
+			},
+		},
+		{
+			name: "reencrypt endpoint with certificates",
+			endpoint: `        - name: https
+          targetPort: 8443
+          path: /api
+          attributes:
+            route-host: "{name}-{component}.{namespace}.example.com"
+            route-tls-termination: reencrypt
+            route-insecure-policy: None
+            route-tls-secret: app-tls
+            route-destination-ca-secret: app-ca
+`,
+			hostTemplate: "ignored.{domain}",
+			inlineTLSKey: true,
+			wantSpec: &routev1.RouteSpec{
+				Host: "app-web.my-ns.example.com",
+				To:   routev1.RouteTargetReference{Kind: "Service", Name: "app"},
+				Port: &routev1.RoutePort{TargetPort: intstr.FromInt(8443)},
+				Path: "/api",
+				TLS: &routev1.TLSConfig{
+					Termination:                   routev1.TLSTerminationReencrypt,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyNone,
+					Certificate:                   "cert",
+					Key:                           "key",
+					CACertificate:                 "ca",
+					DestinationCACertificate:      "destination-ca",
+				},
+			},
+		},
+		{
+			name: "passthrough endpoint with wildcard policy",
+			endpoint: `        - name: tls
+          targetPort: 8443
+          attributes:
+            route-host: tls.example.com
+            route-tls-termination: passthrough
+            route-wildcard-policy: Subdomain
+`,
+			wantSpec: &routev1.RouteSpec{
+				Host:           "tls.example.com",
+				To:             routev1.RouteTargetReference{Kind: "Service", Name: "app"},
+				Port:           &routev1.RoutePort{TargetPort: intstr.FromInt(8443)},
+				WildcardPolicy: routev1.WildcardPolicySubdomain,
+				TLS: &routev1.TLSConfig{
+					Termination:                   routev1.TLSTerminationPassthrough,
+					InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+				},
+			},
+		},
+		{
+			name: "passthrough endpoint with a path",
+			endpoint: `        - name: tls
+          targetPort: 8443
+          path: /api
+          attributes:
+            route-tls-termination: passthrough
+`,
+			wantErr: "invalid route of endpoint tls: a passthrough route cannot route the path /api",
+		},
+		{
+			name: "passthrough endpoint with a certificate",
+			endpoint: `        - name: tls
+          targetPort: 8443
+          attributes:
+            route-tls-termination: passthrough
+            route-tls-secret: app-tls
+`,
+			inlineTLSKey: true,
+			wantErr:      "invalid route of endpoint tls: a passthrough route cannot serve a certificate, the endpoint serves its own certificate",
+		},
+		{
+			name: "destination CA of an edge route",
+			endpoint: `        - name: https
+          targetPort: 8443
+          secure: true
+          attributes:
+            route-destination-ca-secret: app-ca
+`,
+			wantErr: "invalid route of endpoint https: a destination CA certificate can only be set for a reencrypt route",
+		},
+		{
+			name: "wildcard policy without host",
+			endpoint: `        - name: http
+          targetPort: 8080
+          attributes:
+            route-wildcard-policy: Subdomain
+`,
+			wantErr: "invalid route of endpoint http: a route with a Subdomain wildcard policy requires a host",
+		},
+		{
+			name: "TLS Secret without key",
+			endpoint: `        - name: https
+          targetPort: 8443
+          secure: true
+          attributes:
+            route-tls-secret: no-key
+`,
+			inlineTLSKey: true,
+			wantErr:      "invalid route-tls-secret attribute of endpoint https: Secret no-key has no tls.crt or no tls.key",
+		},
+		{
+			name: "TLS Secret without inlining its key",
+			endpoint: `        - name: https
+          targetPort: 8443
+          secure: true
+          attributes:
+            route-tls-secret: app-tls
+`,
+			wantErr: "invalid route-tls-secret attribute of endpoint https: the private key of Secret app-tls would be copied into the Route, which is not allowed",
+		},
+		{
+			name: "unknown host template placeholder",
+			endpoint: `        - name: http
+          targetPort: 8080
+`,
+			hostTemplate: "{endpoint}.{cluster}",
+			wantErr:      `invalid route host of endpoint http: unknown placeholder {cluster} in host template "{endpoint}.{cluster}"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: routes
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+` + tt.endpoint)})
+			containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+			if err != nil {
+				t.Fatalf("GetDevfileContainerComponents() unexpected error: %v", err)
+			}
+			specParams, err := GetEndpointRouteSpecParams(containerComponents[0].Container.Endpoints[0], "web", EndpointRouteParams{
+				ServiceName:  "app",
+				HostTemplate: tt.hostTemplate,
+				HostValues: RouteHostValues{
+					Name:        "app",
+					DevfileName: "routes",
+					Namespace:   "my-ns",
+					Domain:      "apps.example.com",
+				},
+				GetSecret:    getSecret,
+				InlineTLSKey: tt.inlineTLSKey,
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetEndpointRouteSpecParams() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetEndpointRouteSpecParams() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantSpec, getRouteSpec(specParams)); diff != "" {
+				t.Errorf("GetEndpointRouteSpecParams() route spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetRouteWithValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		parameter RouteSpecParams
+		wantErr   string
+	}{
+		{
+			name: "secure route",
+			parameter: RouteSpecParams{
+				ServiceName: "service1",
+				PortNumber:  intstr.FromInt(8443),
+				Secure:      true,
+			},
+		},
+		{
+			name: "passthrough route with a path",
+			parameter: RouteSpecParams{
+				ServiceName:    "service1",
+				PortNumber:     intstr.FromInt(8443),
+				TLSTermination: routev1.TLSTerminationPassthrough,
+				Path:           "/test",
+			},
+			wantErr: "invalid route of endpoint https: a passthrough route cannot route the path /test",
+		},
+		{
+			name: "passthrough route with a certificate",
+			parameter: RouteSpecParams{
+				ServiceName:    "service1",
+				PortNumber:     intstr.FromInt(8443),
+				TLSTermination: routev1.TLSTerminationPassthrough,
+				Certificate:    "cert",
+				Key:            "key",
+			},
+			wantErr: "invalid route of endpoint https: a passthrough route cannot serve a certificate, the endpoint serves its own certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route, err := GetRouteWithValidation(v1.Endpoint{Name: "https"}, RouteParams{RouteSpecParams: tt.parameter})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetRouteWithValidation() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetRouteWithValidation() unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(getRouteSpec(tt.parameter), &route.Spec); diff != "" {
+				t.Errorf("GetRouteWithValidation() route spec mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestExpandRouteHostTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		values   RouteHostValues
+		wantHost string
+		wantErr  string
+	}{
+		{
+			name:     "all placeholders",
+			template: "{endpoint}-{component}-{name}-{devfileName}.{namespace}.{domain}",
+			values:   RouteHostValues{Name: "app", DevfileName: "nodejs", Namespace: "my-ns", Domain: "example.com"},
+			wantHost: "http-web-app-nodejs.my-ns.example.com",
+		},
+		{
+			name:     "placeholder without value",
+			template: "{endpoint}.{domain}",
+			wantErr:  `placeholder {domain} of host template "{endpoint}.{domain}" has no value`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, err := ExpandRouteHostTemplate(tt.template, "http", "web", tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("ExpandRouteHostTemplate() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpandRouteHostTemplate() unexpected error: %v", err)
+			}
+			if host != tt.wantHost {
+				t.Errorf("ExpandRouteHostTemplate() = %s, want %s", host, tt.wantHost)
+			}
+		})
+	}
+}
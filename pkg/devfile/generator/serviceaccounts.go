@@ -0,0 +1,162 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/devfile/api/v2/pkg/attributes"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Attributes configuring the identity of the pods in the cluster. They are set on the devfile, for all the pods.
+//
+//	attributes:
+//	  service-account: builder
+//	  cluster-api-access:
+//	    - apiGroups: [""]
+//	      resources: [configmaps]
+//	      verbs: [get, list, watch]
+const (
+	// ServiceAccountAttribute is the name of the ServiceAccount running the pods
+	ServiceAccountAttribute = "service-account"
+	// ClusterAPIAccessAttribute is the list of the rbac.authorization.k8s.io/v1 policy rules granted to the pods
+	// on the cluster API, in their namespace
+	ClusterAPIAccessAttribute = "cluster-api-access"
+)
+
+const (
+	serviceAccountKind = "ServiceAccount"
+	roleKind           = "Role"
+	roleBindingKind    = "RoleBinding"
+)
+
+// ServiceAccountParams is a struct that contains the required data to create the ServiceAccount of the pods
+// and its access to the cluster API
+type ServiceAccountParams struct {
+	// ObjectMeta is the metadata of the ServiceAccount, the Role and the RoleBinding
+	ObjectMeta metav1.ObjectMeta
+	// ServiceAccountName is the name of an existing ServiceAccount granted the access to the cluster API.
+	// It overrides the ServiceAccountAttribute attribute of the devfile.
+	ServiceAccountName string
+}
+
+// ServiceAccountResources are the objects granting the pods access to the cluster API
+type ServiceAccountResources struct {
+	// ServiceAccountName is the name of the ServiceAccount which must run the pods
+	ServiceAccountName string
+	// ServiceAccount is the ServiceAccount running the pods, or nil if an existing ServiceAccount is used
+	ServiceAccount *corev1.ServiceAccount
+	Role           *rbacv1.Role
+	RoleBinding    *rbacv1.RoleBinding
+}
+
+// GetServiceAccountResources returns the Role granting the rules of the ClusterAPIAccessAttribute attribute of the devfile,
+// and the RoleBinding binding it to the ServiceAccount running the pods, or nil if the attribute is not set.
+// The pods run with serviceAccountParams.ServiceAccountName, or else the ServiceAccountAttribute attribute of the devfile;
+// if neither is set, a ServiceAccount named after serviceAccountParams.ObjectMeta is also returned.
+func GetServiceAccountResources(devfileObj parser.DevfileObj, serviceAccountParams ServiceAccountParams) (*ServiceAccountResources, error) {
+	// attributes is not supported in versions less than 2.1.0
+	if devfileObj.Data.GetSchemaVersion() <= string(data.APISchemaVersion200) {
+		return nil, nil
+	}
+	globalAttributes, err := devfileObj.Data.GetAttributes()
+	if err != nil {
+		return nil, err
+	}
+	if !globalAttributes.Exists(ClusterAPIAccessAttribute) {
+		return nil, nil
+	}
+	var rules []rbacv1.PolicyRule
+	if err = globalAttributes.GetInto(ClusterAPIAccessAttribute, &rules); err != nil {
+		return nil, fmt.Errorf("invalid %s attribute: %w", ClusterAPIAccessAttribute, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("invalid %s attribute: at least one rule is required", ClusterAPIAccessAttribute)
+	}
+	for i, rule := range rules {
+		if len(rule.NonResourceURLs) > 0 {
+			return nil, fmt.Errorf("invalid %s attribute: rule %d grants access to non-resource URLs, which cannot be granted in a namespace", ClusterAPIAccessAttribute, i+1)
+		}
+		if len(rule.Verbs) == 0 || len(rule.Resources) == 0 {
+			return nil, fmt.Errorf("invalid %s attribute: rule %d must have verbs and resources", ClusterAPIAccessAttribute, i+1)
+		}
+	}
+
+	if serviceAccountParams.ObjectMeta.Name == "" {
+		return nil, errors.New("a name is required to grant access to the cluster API")
+	}
+	serviceAccountName, err := getServiceAccountName(globalAttributes, serviceAccountParams.ServiceAccountName)
+	if err != nil {
+		return nil, err
+	}
+	resources := &ServiceAccountResources{ServiceAccountName: serviceAccountName}
+	if serviceAccountName == "" {
+		resources.ServiceAccountName = serviceAccountParams.ObjectMeta.Name
+		resources.ServiceAccount = &corev1.ServiceAccount{
+			TypeMeta:   GetTypeMeta(serviceAccountKind, corev1.SchemeGroupVersion.String()),
+			ObjectMeta: serviceAccountParams.ObjectMeta,
+		}
+	}
+	resources.Role = &rbacv1.Role{
+		TypeMeta:   GetTypeMeta(roleKind, rbacv1.SchemeGroupVersion.String()),
+		ObjectMeta: serviceAccountParams.ObjectMeta,
+		Rules:      rules,
+	}
+	resources.RoleBinding = &rbacv1.RoleBinding{
+		TypeMeta:   GetTypeMeta(roleBindingKind, rbacv1.SchemeGroupVersion.String()),
+		ObjectMeta: serviceAccountParams.ObjectMeta,
+		Subjects: []rbacv1.Subject{{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      resources.ServiceAccountName,
+			Namespace: serviceAccountParams.ObjectMeta.Namespace,
+		}},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     roleKind,
+			Name:     serviceAccountParams.ObjectMeta.Name,
+		},
+	}
+	return resources, nil
+}
+
+// getServiceAccountName returns serviceAccountName, or else the ServiceAccountAttribute attribute of the devfile
+func getServiceAccountName(globalAttributes attributes.Attributes, serviceAccountName string) (string, error) {
+	if serviceAccountName != "" || !globalAttributes.Exists(ServiceAccountAttribute) {
+		return serviceAccountName, nil
+	}
+	if err := globalAttributes.GetInto(ServiceAccountAttribute, &serviceAccountName); err != nil {
+		return "", fmt.Errorf("invalid %s attribute: %w", ServiceAccountAttribute, err)
+	}
+	return serviceAccountName, nil
+}
+
+// setPodIdentity sets the image pull secrets and the ServiceAccount of a pod, merged with the
+// ImagePullSecretsAttribute and ServiceAccountAttribute attributes of the devfile
+func setPodIdentity(globalAttributes attributes.Attributes, podSpec *corev1.PodSpec, imagePullSecrets []corev1.LocalObjectReference, serviceAccountName string) error {
+	secrets, err := getImagePullSecrets(globalAttributes, imagePullSecrets)
+	if err != nil {
+		return err
+	}
+	podSpec.ImagePullSecrets = secrets
+	podSpec.ServiceAccountName, err = getServiceAccountName(globalAttributes, serviceAccountName)
+	return err
+}
@@ -0,0 +1,136 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGetServiceAccountResources(t *testing.T) {
+	tests := []struct {
+		name                   string
+		attributes             string
+		serviceAccountName     string
+		wantNil                bool
+		wantServiceAccount     bool
+		wantServiceAccountName string
+		wantErr                string
+	}{
+		{
+			name:    "no cluster API access",
+			wantNil: true,
+		},
+		{
+			name: "generated service account",
+			attributes: `  cluster-api-access:
+    - apiGroups: [""]
+      resources: [pods]
+      verbs: [list]
+`,
+			wantServiceAccount:     true,
+			wantServiceAccountName: "app",
+		},
+		{
+			name: "service account attribute",
+			attributes: `  service-account: builder
+  cluster-api-access:
+    - apiGroups: [""]
+      resources: [pods]
+      verbs: [list]
+`,
+			wantServiceAccountName: "builder",
+		},
+		{
+			name: "service account overriding the attribute",
+			attributes: `  service-account: builder
+  cluster-api-access:
+    - apiGroups: [""]
+      resources: [pods]
+      verbs: [list]
+`,
+			serviceAccountName:     "deployer",
+			wantServiceAccountName: "deployer",
+		},
+		{
+			name: "rule without verbs",
+			attributes: `  cluster-api-access:
+    - apiGroups: [""]
+      resources: [pods]
+`,
+			wantErr: "invalid cluster-api-access attribute: rule 1 must have verbs and resources",
+		},
+		{
+			name: "non-resource URLs",
+			attributes: `  cluster-api-access:
+    - nonResourceURLs: [/healthz]
+      verbs: [get]
+`,
+			wantErr: "invalid cluster-api-access attribute: rule 1 grants access to non-resource URLs, which cannot be granted in a namespace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := `schemaVersion: 2.2.0
+metadata:
+  name: app
+`
+			if tt.attributes != "" {
+				content += "attributes:\n" + tt.attributes
+			}
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(content + `components:
+  - name: web
+    container:
+      image: web
+`)})
+			resources, err := GetServiceAccountResources(devfileObj, ServiceAccountParams{
+				ObjectMeta:         metav1.ObjectMeta{Name: "app", Namespace: "ns"},
+				ServiceAccountName: tt.serviceAccountName,
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetServiceAccountResources() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetServiceAccountResources() unexpected error: %v", err)
+			}
+			if tt.wantNil {
+				if resources != nil {
+					t.Errorf("GetServiceAccountResources() = %v, want nil", resources)
+				}
+				return
+			}
+			if resources.ServiceAccountName != tt.wantServiceAccountName {
+				t.Errorf("GetServiceAccountResources() service account name = %s, want %s", resources.ServiceAccountName, tt.wantServiceAccountName)
+			}
+			if (resources.ServiceAccount != nil) != tt.wantServiceAccount {
+				t.Errorf("GetServiceAccountResources() service account = %v, want generated: %v", resources.ServiceAccount, tt.wantServiceAccount)
+			}
+			wantSubject := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: tt.wantServiceAccountName, Namespace: "ns"}
+			if len(resources.RoleBinding.Subjects) != 1 || resources.RoleBinding.Subjects[0] != wantSubject {
+				t.Errorf("GetServiceAccountResources() role binding subjects = %v, want %v", resources.RoleBinding.Subjects, wantSubject)
+			}
+			if resources.RoleBinding.RoleRef.Name != resources.Role.Name {
+				t.Errorf("GetServiceAccountResources() role binding references role %s, want %s", resources.RoleBinding.RoleRef.Name, resources.Role.Name)
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Attributes of the endpoints configuring their Service
+const (
+	// ServiceTypeAttribute is the type of the Service exposing the endpoint: ClusterIP, NodePort, LoadBalancer,
+	// or Headless for a ClusterIP Service without cluster IP. Defaults to ClusterIP.
+	ServiceTypeAttribute = "service-type"
+	// NodePortAttribute is the node port of the endpoint, for a NodePort or LoadBalancer Service
+	NodePortAttribute = "node-port"
+	// AppProtocolAttribute is the application protocol of the Service port, such as kubernetes.io/h2c or grpc.
+	// Defaults to the application protocol derived from the endpoint protocol.
+	AppProtocolAttribute = "app-protocol"
+
+	// HeadlessServiceType is the value of ServiceTypeAttribute for a headless Service
+	HeadlessServiceType = "Headless"
+)
+
+// serviceNameSuffixes are the suffixes of the names of the Services which are not of the default ClusterIP type
+var serviceNameSuffixes = map[string]string{
+	string(corev1.ServiceTypeNodePort):     "nodeport",
+	string(corev1.ServiceTypeLoadBalancer): "lb",
+	HeadlessServiceType:                    "headless",
+}
+
+// EndpointServicesParams is a struct that contains the required data to create the Services exposing the endpoints
+type EndpointServicesParams struct {
+	TypeMeta metav1.TypeMeta
+	// ObjectMeta is the metadata of the Services. The ClusterIP Service is named ObjectMeta.Name, and the other Services
+	// are named <ObjectMeta.Name>-nodeport, <ObjectMeta.Name>-lb and <ObjectMeta.Name>-headless.
+	ObjectMeta     metav1.ObjectMeta
+	SelectorLabels map[string]string
+}
+
+// GetEndpointServices returns the Services exposing the endpoints of the container components, unless their port only
+// has a "none" exposure, and the name of the Service exposing each endpoint, indexed by endpoint name.
+//
+// A Service is returned for each Service type selected by the ServiceTypeAttribute attribute of the endpoints.
+// Service ports are named after the endpoints, and their protocol and application protocol are derived from the
+// endpoint protocol, or set by the AppProtocolAttribute attribute. Endpoints sharing a port and a protocol are exposed once.
+func GetEndpointServices(devfileObj parser.DevfileObj, servicesParams EndpointServicesParams, options common.DevfileOptions) ([]*corev1.Service, map[string]string, error) {
+	return getEndpointServices(devfileObj, servicesParams, options, nil)
+}
+
+// getEndpointServices returns the Services exposing the endpoints of the container components accepted by include,
+// including the ones applied by events. A nil include accepts the container components not applied by preStart and
+// postStop events, annotating the Services as the components without dedicatedPod.
+func getEndpointServices(devfileObj parser.DevfileObj, servicesParams EndpointServicesParams, options common.DevfileOptions,
+	include func(componentName string) bool) ([]*corev1.Service, map[string]string, error) {
+	exposed := include
+	if exposed == nil {
+		containers, err := GetContainers(devfileObj, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		running := make(map[string]bool)
+		for _, container := range containers {
+			running[container.Name] = true
+		}
+		exposed = func(componentName string) bool {
+			return running[componentName]
+		}
+	}
+
+	portExposureMap, err := getPortExposure(devfileObj, options, exposed)
+	if err != nil {
+		return nil, nil, err
+	}
+	options.ComponentOptions = common.ComponentOptions{ComponentType: v1.ContainerComponentType}
+	containerComponents, err := devfileObj.Data.GetComponents(options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type portKey struct {
+		port     int32
+		protocol corev1.Protocol
+	}
+	var serviceTypes []string
+	servicePorts := make(map[string][]corev1.ServicePort)
+	exposedPorts := make(map[string]map[portKey]string)
+	endpointServices := make(map[string]string)
+	for _, comp := range containerComponents {
+		if !exposed(comp.Name) {
+			continue
+		}
+		for _, endpoint := range comp.Container.Endpoints {
+			if portExposureMap[endpoint.TargetPort] == v1.NoneEndpointExposure {
+				continue
+			}
+			serviceType, err := getEndpointServiceType(comp, endpoint)
+			if err != nil {
+				return nil, nil, err
+			}
+			servicePort, err := getEndpointServicePort(comp, endpoint, serviceType)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			serviceName := servicesParams.ObjectMeta.Name
+			if suffix := serviceNameSuffixes[serviceType]; suffix != "" {
+				serviceName = getResourceName(serviceName, suffix)
+			}
+			endpointServices[endpoint.Name] = serviceName
+
+			if _, ok := servicePorts[serviceType]; !ok {
+				serviceTypes = append(serviceTypes, serviceType)
+				exposedPorts[serviceType] = make(map[portKey]string)
+			}
+			key := portKey{port: servicePort.Port, protocol: servicePort.Protocol}
+			if _, ok := exposedPorts[serviceType][key]; ok {
+				continue
+			}
+			exposedPorts[serviceType][key] = endpoint.Name
+			servicePorts[serviceType] = append(servicePorts[serviceType], servicePort)
+		}
+	}
+
+	containerAnnotations, err := getContainerAnnotations(devfileObj, options, include)
+	if err != nil {
+		return nil, nil, err
+	}
+	var services []*corev1.Service
+	for _, serviceType := range serviceTypes {
+		objectMeta := *servicesParams.ObjectMeta.DeepCopy()
+		if suffix := serviceNameSuffixes[serviceType]; suffix != "" {
+			objectMeta.Name = getResourceName(objectMeta.Name, suffix)
+		}
+		objectMeta.Annotations = mergeMaps(objectMeta.Annotations, containerAnnotations.Service)
+
+		spec := corev1.ServiceSpec{
+			Ports:    servicePorts[serviceType],
+			Selector: servicesParams.SelectorLabels,
+		}
+		if serviceType == HeadlessServiceType {
+			spec.ClusterIP = corev1.ClusterIPNone
+		} else if serviceType != string(corev1.ServiceTypeClusterIP) {
+			spec.Type = corev1.ServiceType(serviceType)
+		}
+		services = append(services, &corev1.Service{
+			TypeMeta:   servicesParams.TypeMeta,
+			ObjectMeta: objectMeta,
+			Spec:       spec,
+		})
+	}
+	return services, endpointServices, nil
+}
+
+// getEndpointServiceType returns the type of the Service exposing the endpoint
+func getEndpointServiceType(comp v1.Component, endpoint v1.Endpoint) (string, error) {
+	if !endpoint.Attributes.Exists(ServiceTypeAttribute) {
+		return string(corev1.ServiceTypeClusterIP), nil
+	}
+	var err error
+	serviceType := endpoint.Attributes.GetString(ServiceTypeAttribute, &err)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s attribute of endpoint %s of component %s: %w", ServiceTypeAttribute, endpoint.Name, comp.Name, err)
+	}
+	switch serviceType {
+	case string(corev1.ServiceTypeClusterIP), string(corev1.ServiceTypeNodePort), string(corev1.ServiceTypeLoadBalancer), HeadlessServiceType:
+		return serviceType, nil
+	default:
+		return "", fmt.Errorf("endpoint %s of component %s: unsupported Service type %q", endpoint.Name, comp.Name, serviceType)
+	}
+}
+
+// getEndpointServicePort returns the Service port exposing the endpoint
+func getEndpointServicePort(comp v1.Component, endpoint v1.Endpoint, serviceType string) (corev1.ServicePort, error) {
+	protocol, appProtocol := getEndpointProtocols(endpoint.Protocol)
+	if endpoint.Attributes.Exists(AppProtocolAttribute) {
+		var err error
+		appProtocol = endpoint.Attributes.GetString(AppProtocolAttribute, &err)
+		if err != nil {
+			return corev1.ServicePort{}, fmt.Errorf("invalid %s attribute of endpoint %s of component %s: %w", AppProtocolAttribute, endpoint.Name, comp.Name, err)
+		}
+	}
+	servicePort := corev1.ServicePort{
+		Name:       getPortName(endpoint),
+		Port:       int32(endpoint.TargetPort),
+		TargetPort: intstr.FromInt(endpoint.TargetPort),
+		Protocol:   protocol,
+	}
+	if appProtocol != "" {
+		servicePort.AppProtocol = &appProtocol
+	}
+	if endpoint.Attributes.Exists(NodePortAttribute) {
+		if serviceType != string(corev1.ServiceTypeNodePort) && serviceType != string(corev1.ServiceTypeLoadBalancer) {
+			return corev1.ServicePort{}, fmt.Errorf("endpoint %s of component %s: a node port requires a NodePort or LoadBalancer Service", endpoint.Name, comp.Name)
+		}
+		var err error
+		nodePort := endpoint.Attributes.GetNumber(NodePortAttribute, &err)
+		if err != nil {
+			return corev1.ServicePort{}, fmt.Errorf("invalid %s attribute of endpoint %s of component %s: %w", NodePortAttribute, endpoint.Name, comp.Name, err)
+		}
+		servicePort.NodePort = int32(nodePort)
+	}
+	return servicePort, nil
+}
+
+// getEndpointProtocols returns the protocol and the application protocol of a port exposing an endpoint with the given protocol
+func getEndpointProtocols(protocol v1.EndpointProtocol) (corev1.Protocol, string) {
+	switch protocol {
+	case v1.HTTPEndpointProtocol, "":
+		return corev1.ProtocolTCP, "http"
+	case v1.HTTPSEndpointProtocol:
+		return corev1.ProtocolTCP, "https"
+	case v1.WSEndpointProtocol:
+		return corev1.ProtocolTCP, "kubernetes.io/ws"
+	case v1.WSSEndpointProtocol:
+		return corev1.ProtocolTCP, "kubernetes.io/wss"
+	case v1.UDPEndpointProtocol:
+		return corev1.ProtocolUDP, ""
+	default:
+		return corev1.ProtocolTCP, ""
+	}
+}
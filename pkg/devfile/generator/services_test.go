@@ -0,0 +1,229 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetEndpointServices(t *testing.T) {
+	devfile := `schemaVersion: 2.2.0
+metadata:
+  name: services
+components:
+  - name: web
+    container:
+      image: web
+      annotation:
+        service:
+          team: web
+      endpoints:
+        - name: http
+          targetPort: 8080
+        - name: events
+          targetPort: 8080
+          protocol: ws
+        - name: dns
+          targetPort: 5353
+          protocol: udp
+        - name: dns-tcp
+          targetPort: 5353
+          protocol: tcp
+        - name: grpc
+          targetPort: 9000
+          attributes:
+            app-protocol: kubernetes.io/h2c
+        - name: metrics
+          targetPort: 9000
+          exposure: none
+        - name: debug
+          targetPort: 5858
+          exposure: none
+        - name: admin
+          targetPort: 8443
+          protocol: https
+          attributes:
+            service-type: NodePort
+            node-port: 30443
+  - name: db
+    container:
+      image: db
+      endpoints:
+        - name: postgres
+          targetPort: 5432
+          protocol: tcp
+          exposure: internal
+          attributes:
+            service-type: Headless
+`
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(devfile)})
+	selectorLabels := map[string]string{"app": "services"}
+	services, endpointServices, err := GetEndpointServices(devfileObj, EndpointServicesParams{
+		TypeMeta:       GetTypeMeta(serviceKind, serviceAPIVersion),
+		ObjectMeta:     metav1.ObjectMeta{Name: "app", Namespace: "my-ns"},
+		SelectorLabels: selectorLabels,
+	}, common.DevfileOptions{})
+	if err != nil {
+		t.Fatalf("GetEndpointServices() unexpected error: %v", err)
+	}
+
+	typeMeta := GetTypeMeta(serviceKind, serviceAPIVersion)
+	annotations := map[string]string{"team": "web"}
+	want := []*corev1.Service{
+		{
+			TypeMeta:   typeMeta,
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "my-ns", Annotations: annotations},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 8080, TargetPort: intstr.FromInt(8080), Protocol: corev1.ProtocolTCP, AppProtocol: pointer.String("http")},
+					{Name: "dns", Port: 5353, TargetPort: intstr.FromInt(5353), Protocol: corev1.ProtocolUDP},
+					{Name: "dns-tcp", Port: 5353, TargetPort: intstr.FromInt(5353), Protocol: corev1.ProtocolTCP},
+					{Name: "grpc", Port: 9000, TargetPort: intstr.FromInt(9000), Protocol: corev1.ProtocolTCP, AppProtocol: pointer.String("kubernetes.io/h2c")},
+				},
+				Selector: selectorLabels,
+			},
+		},
+		{
+			TypeMeta:   typeMeta,
+			ObjectMeta: metav1.ObjectMeta{Name: "app-nodeport", Namespace: "my-ns", Annotations: annotations},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "admin", Port: 8443, TargetPort: intstr.FromInt(8443), Protocol: corev1.ProtocolTCP, AppProtocol: pointer.String("https"), NodePort: 30443},
+				},
+				Selector: selectorLabels,
+				Type:     corev1.ServiceTypeNodePort,
+			},
+		},
+		{
+			TypeMeta:   typeMeta,
+			ObjectMeta: metav1.ObjectMeta{Name: "app-headless", Namespace: "my-ns", Annotations: annotations},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{Name: "postgres", Port: 5432, TargetPort: intstr.FromInt(5432), Protocol: corev1.ProtocolTCP},
+				},
+				Selector:  selectorLabels,
+				ClusterIP: corev1.ClusterIPNone,
+			},
+		},
+	}
+	if diff := cmp.Diff(want, services); diff != "" {
+		t.Errorf("GetEndpointServices() services mismatch (-want +got):\n%s", diff)
+	}
+	wantEndpointServices := map[string]string{
+		"http":     "app",
+		"events":   "app",
+		"dns":      "app",
+		"dns-tcp":  "app",
+		"grpc":     "app",
+		"metrics":  "app",
+		"admin":    "app-nodeport",
+		"postgres": "app-headless",
+	}
+	if diff := cmp.Diff(wantEndpointServices, endpointServices); diff != "" {
+		t.Errorf("GetEndpointServices() endpoint services mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetServiceForComponents(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: services
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+        - name: http
+          targetPort: 8080
+  - name: db
+    container:
+      image: db
+      dedicatedPod: true
+      annotation:
+        service:
+          team: db
+      endpoints:
+        - name: postgres
+          targetPort: 5432
+          protocol: tcp
+          exposure: internal
+`)})
+	service, err := getService(devfileObj, ServiceParams{ObjectMeta: metav1.ObjectMeta{Name: "app-db"}}, common.DevfileOptions{}, func(componentName string) bool {
+		return componentName == "db"
+	})
+	if err != nil {
+		t.Fatalf("getService() unexpected error: %v", err)
+	}
+	want := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-db", Annotations: map[string]string{"team": "db"}},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "postgres", Port: 5432, TargetPort: intstr.FromInt(5432)},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, service); diff != "" {
+		t.Errorf("getService() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetEndpointServicesErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes string
+		wantErr    string
+	}{
+		{
+			name:       "unsupported Service type",
+			attributes: "service-type: ExternalName",
+			wantErr:    `endpoint http of component web: unsupported Service type "ExternalName"`,
+		},
+		{
+			name:       "node port of a ClusterIP Service",
+			attributes: "node-port: 30080",
+			wantErr:    "endpoint http of component web: a node port requires a NodePort or LoadBalancer Service",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: services
+components:
+  - name: web
+    container:
+      image: web
+      endpoints:
+        - name: http
+          targetPort: 8080
+          attributes:
+            ` + tt.attributes + `
+`)})
+			_, _, err := GetEndpointServices(devfileObj, EndpointServicesParams{ObjectMeta: metav1.ObjectMeta{Name: "app"}}, common.DevfileOptions{})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("GetEndpointServices() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
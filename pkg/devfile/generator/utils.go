@@ -17,6 +17,7 @@ package generator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"reflect"
@@ -71,11 +72,7 @@ func convertPorts(endpoints []v1.Endpoint) []corev1.ContainerPort {
 		} else {
 			portProtocol = corev1.ProtocolTCP
 		}
-		name := endpoint.Name
-		if len(name) > 15 {
-			// to be compatible with endpoint longer than 15 chars
-			name = fmt.Sprintf("port-%v", portNumber)
-		}
+		name := getPortName(endpoint)
 
 		if _, exist := portMap[name]; !exist {
 			portMap[name] = true
@@ -89,6 +86,15 @@ func convertPorts(endpoints []v1.Endpoint) []corev1.ContainerPort {
 	return containerPorts
 }
 
+// getPortName returns the name of the port of the endpoint
+func getPortName(endpoint v1.Endpoint) string {
+	if len(endpoint.Name) > 15 {
+		// to be compatible with endpoint longer than 15 chars
+		return fmt.Sprintf("port-%v", endpoint.TargetPort)
+	}
+	return endpoint.Name
+}
+
 // getResourceReqs creates a kubernetes ResourceRequirements object based on resource requirements set in the devfile
 func getResourceReqs(comp v1.Component) (corev1.ResourceRequirements, error) {
 	reqs := corev1.ResourceRequirements{}
@@ -174,19 +180,10 @@ func addSyncFolder(container *corev1.Container, sourceVolumePath string, project
 		syncFolder = sourceVolumePath
 	} else {
 		// if there is one or more projects in the devfile, get the first project and check its clonepath
-		project := projects[0]
-		// If clonepath does not exist source would be synced to $PROJECTS_ROOT/projectName
-		syncFolder = filepath.ToSlash(filepath.Join(sourceVolumePath, project.Name))
-
-		if project.ClonePath != "" {
-			if strings.HasPrefix(project.ClonePath, "/") {
-				return fmt.Errorf("the clonePath %s in the devfile project %s must be a relative path", project.ClonePath, project.Name)
-			}
-			if strings.Contains(project.ClonePath, "..") {
-				return fmt.Errorf("the clonePath %s in the devfile project %s cannot escape the value defined by $PROJECTS_ROOT. Please avoid using \"..\" in clonePath", project.ClonePath, project.Name)
-			}
-			// If clonepath exist source would be synced to $PROJECTS_ROOT/clonePath
-			syncFolder = filepath.ToSlash(filepath.Join(sourceVolumePath, project.ClonePath))
+		var err error
+		syncFolder, err = getProjectPath(sourceVolumePath, projects[0])
+		if err != nil {
+			return err
 		}
 	}
 
@@ -200,6 +197,22 @@ func addSyncFolder(container *corev1.Container, sourceVolumePath string, project
 	return nil
 }
 
+// getProjectPath returns the path of the project in the projects volume mounted at sourceVolumePath
+func getProjectPath(sourceVolumePath string, project v1.Project) (string, error) {
+	if project.ClonePath == "" {
+		// If clonepath does not exist source would be synced to $PROJECTS_ROOT/projectName
+		return filepath.ToSlash(filepath.Join(sourceVolumePath, project.Name)), nil
+	}
+	if strings.HasPrefix(project.ClonePath, "/") {
+		return "", fmt.Errorf("the clonePath %s in the devfile project %s must be a relative path", project.ClonePath, project.Name)
+	}
+	if strings.Contains(project.ClonePath, "..") {
+		return "", fmt.Errorf("the clonePath %s in the devfile project %s cannot escape the value defined by $PROJECTS_ROOT. Please avoid using \"..\" in clonePath", project.ClonePath, project.Name)
+	}
+	// If clonepath exist source would be synced to $PROJECTS_ROOT/clonePath
+	return filepath.ToSlash(filepath.Join(sourceVolumePath, project.ClonePath)), nil
+}
+
 // containerParams is a struct that contains the required data to create a container object
 type containerParams struct {
 	Name         string
@@ -210,14 +223,20 @@ type containerParams struct {
 	EnvVars      []corev1.EnvVar
 	ResourceReqs corev1.ResourceRequirements
 	Ports        []corev1.ContainerPort
+	// ImagePullPolicy is the pull policy of the image. Defaults to Always.
+	ImagePullPolicy corev1.PullPolicy
 }
 
 // getContainer gets a container struct that can be used when creating a pod
 func getContainer(containerParams containerParams) *corev1.Container {
+	imagePullPolicy := containerParams.ImagePullPolicy
+	if imagePullPolicy == "" {
+		imagePullPolicy = corev1.PullAlways
+	}
 	container := &corev1.Container{
 		Name:            containerParams.Name,
 		Image:           containerParams.Image,
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: imagePullPolicy,
 		Resources:       containerParams.ResourceReqs,
 		Env:             containerParams.EnvVars,
 		Ports:           containerParams.Ports,
@@ -378,19 +397,28 @@ func getDeploymentSpec(deploySpecParams deploymentSpecParams) *appsv1.Deployment
 	return deploymentSpec
 }
 
-// getServiceSpec iterates through the devfile components and returns a ServiceSpec
-func getServiceSpec(devfileObj parser.DevfileObj, selectorLabels map[string]string, options common.DevfileOptions) (*corev1.ServiceSpec, error) {
+// getServiceSpec iterates through the devfile components and returns a ServiceSpec.
+// If include is set, only the container components it accepts are exposed, including the ones applied by events,
+// otherwise all the container components not applied by preStart and postStop events are exposed.
+func getServiceSpec(devfileObj parser.DevfileObj, selectorLabels map[string]string, options common.DevfileOptions, include func(componentName string) bool) (*corev1.ServiceSpec, error) {
 
 	var containerPorts []corev1.ContainerPort
-	portExposureMap, err := getPortExposure(devfileObj, options)
+	portExposureMap, err := getPortExposure(devfileObj, options, include)
 	if err != nil {
 		return nil, err
 	}
-	containers, err := GetContainers(devfileObj, options)
+	getContainers := GetContainers
+	if include != nil {
+		getContainers = getAllContainers
+	}
+	containers, err := getContainers(devfileObj, options)
 	if err != nil {
 		return nil, err
 	}
 	for _, c := range containers {
+		if include != nil && !include(c.Name) {
+			continue
+		}
 		for _, port := range c.Ports {
 			portExist := false
 			for _, entry := range containerPorts {
@@ -424,9 +452,10 @@ func getServiceSpec(devfileObj parser.DevfileObj, selectorLabels map[string]stri
 	return svcSpec, nil
 }
 
-// getPortExposure iterates through all endpoints and returns the highest exposure level of all TargetPort.
+// getPortExposure iterates through all endpoints of the components accepted by include, or of all the components
+// if include is nil, and returns the highest exposure level of all TargetPort.
 // exposure level: public > internal > none
-func getPortExposure(devfileObj parser.DevfileObj, options common.DevfileOptions) (map[int]v1.EndpointExposure, error) {
+func getPortExposure(devfileObj parser.DevfileObj, options common.DevfileOptions, include func(componentName string) bool) (map[int]v1.EndpointExposure, error) {
 	portExposureMap := make(map[int]v1.EndpointExposure)
 	options.ComponentOptions = common.ComponentOptions{
 		ComponentType: v1.ContainerComponentType,
@@ -436,6 +465,9 @@ func getPortExposure(devfileObj parser.DevfileObj, options common.DevfileOptions
 		return portExposureMap, err
 	}
 	for _, comp := range containerComponents {
+		if include != nil && !include(comp.Name) {
+			continue
+		}
 		for _, endpoint := range comp.Container.Endpoints {
 			// if exposure=public, no need to check for existence
 			if endpoint.Exposure == v1.PublicEndpointExposure || endpoint.Exposure == "" {
@@ -460,12 +492,16 @@ func getPortExposure(devfileObj parser.DevfileObj, options common.DevfileOptions
 // portNumber is the target port of the ingress
 // Path is the path of the ingress
 // TLSSecretName is the target TLS Secret name of the ingress
+// PathType is the pathType of the path of a networking v1 ingress, defaults to ImplementationSpecific
+// IngressClassName is the ingressClassName of a networking v1 ingress
 type IngressSpecParams struct {
-	ServiceName   string
-	IngressDomain string
-	PortNumber    intstr.IntOrString
-	TLSSecretName string
-	Path          string
+	ServiceName      string
+	IngressDomain    string
+	PortNumber       intstr.IntOrString
+	TLSSecretName    string
+	Path             string
+	PathType         networkingv1.PathType
+	IngressClassName string
 }
 
 // getIngressSpec gets an ingress spec
@@ -512,10 +548,13 @@ func getIngressSpec(ingressSpecParams IngressSpecParams) *extensionsv1.IngressSp
 // getNetworkingV1IngressSpec gets a networking v1 ingress spec
 func getNetworkingV1IngressSpec(ingressSpecParams IngressSpecParams) *networkingv1.IngressSpec {
 	path := "/"
-	pathTypeImplementationSpecific := networkingv1.PathTypeImplementationSpecific
+	pathType := networkingv1.PathTypeImplementationSpecific
 	if ingressSpecParams.Path != "" {
 		path = ingressSpecParams.Path
 	}
+	if ingressSpecParams.PathType != "" {
+		pathType = ingressSpecParams.PathType
+	}
 	ingressSpec := &networkingv1.IngressSpec{
 		Rules: []networkingv1.IngressRule{
 			{
@@ -534,7 +573,7 @@ func getNetworkingV1IngressSpec(ingressSpecParams IngressSpecParams) *networking
 									},
 								},
 								// Field is required to be set based on attempt to create the ingress
-								PathType: &pathTypeImplementationSpecific,
+								PathType: &pathType,
 							},
 						},
 					},
@@ -553,6 +592,9 @@ func getNetworkingV1IngressSpec(ingressSpecParams IngressSpecParams) *networking
 			},
 		}
 	}
+	if ingressSpecParams.IngressClassName != "" {
+		ingressSpec.IngressClassName = &ingressSpecParams.IngressClassName
+	}
 
 	return ingressSpec
 }
@@ -561,11 +603,26 @@ func getNetworkingV1IngressSpec(ingressSpecParams IngressSpecParams) *networking
 // serviceName is the name of the service for the target reference
 // portNumber is the target port of the ingress
 // Path is the path of the route
+// Secure terminates TLS at the edge of the router and redirects insecure traffic, unless TLSTermination is set
+// Host is the host of the route, generated by the router if empty
+// WildcardPolicy is the wildcard policy of the route, None if empty
+// TLSTermination is the TLS termination of the route, which is then served over TLS even if Secure is false
+// InsecureEdgeTerminationPolicy is the policy for insecure traffic of a route served over TLS, defaults to Redirect
+// Certificate, Key and CACertificate are the PEM encoded certificate, key and CA certificate served by the route
+// DestinationCACertificate is the PEM encoded CA certificate validating the certificate of the endpoint, for reencrypt termination
 type RouteSpecParams struct {
-	ServiceName string
-	PortNumber  intstr.IntOrString
-	Path        string
-	Secure      bool
+	ServiceName                   string
+	PortNumber                    intstr.IntOrString
+	Path                          string
+	Secure                        bool
+	Host                          string
+	WildcardPolicy                routev1.WildcardPolicyType
+	TLSTermination                routev1.TLSTerminationType
+	InsecureEdgeTerminationPolicy routev1.InsecureEdgeTerminationPolicyType
+	Certificate                   string
+	Key                           string
+	CACertificate                 string
+	DestinationCACertificate      string
 }
 
 // GetRouteSpec gets a route spec
@@ -574,7 +631,12 @@ func getRouteSpec(routeParams RouteSpecParams) *routev1.RouteSpec {
 	if routeParams.Path != "" {
 		routePath = routeParams.Path
 	}
+	if routeParams.TLSTermination == routev1.TLSTerminationPassthrough {
+		// passthrough routes cannot route paths, the router does not decrypt the requests
+		routePath = ""
+	}
 	routeSpec := &routev1.RouteSpec{
+		Host: routeParams.Host,
 		To: routev1.RouteTargetReference{
 			Kind: "Service",
 			Name: routeParams.ServiceName,
@@ -582,19 +644,76 @@ func getRouteSpec(routeParams RouteSpecParams) *routev1.RouteSpec {
 		Port: &routev1.RoutePort{
 			TargetPort: routeParams.PortNumber,
 		},
-		Path: routePath,
+		Path:           routePath,
+		WildcardPolicy: routeParams.WildcardPolicy,
 	}
 
-	if routeParams.Secure {
+	if routeParams.Secure || routeParams.TLSTermination != "" {
+		termination := routeParams.TLSTermination
+		if termination == "" {
+			termination = routev1.TLSTerminationEdge
+		}
+		insecurePolicy := routeParams.InsecureEdgeTerminationPolicy
+		if insecurePolicy == "" {
+			insecurePolicy = routev1.InsecureEdgeTerminationPolicyRedirect
+		}
 		routeSpec.TLS = &routev1.TLSConfig{
-			Termination:                   routev1.TLSTerminationEdge,
-			InsecureEdgeTerminationPolicy: routev1.InsecureEdgeTerminationPolicyRedirect,
+			Termination:                   termination,
+			InsecureEdgeTerminationPolicy: insecurePolicy,
+			Certificate:                   routeParams.Certificate,
+			Key:                           routeParams.Key,
+			CACertificate:                 routeParams.CACertificate,
+			DestinationCACertificate:      routeParams.DestinationCACertificate,
 		}
 	}
 
 	return routeSpec
 }
 
+// validateRouteSpecParams checks that the route spec params define a valid route
+func validateRouteSpecParams(routeParams RouteSpecParams) error {
+	tls := routeParams.Secure || routeParams.TLSTermination != ""
+	switch routeParams.TLSTermination {
+	case "", routev1.TLSTerminationEdge, routev1.TLSTerminationReencrypt:
+	case routev1.TLSTerminationPassthrough:
+		if routeParams.Path != "" && routeParams.Path != "/" {
+			return fmt.Errorf("a passthrough route cannot route the path %s", routeParams.Path)
+		}
+		if routeParams.Certificate != "" || routeParams.Key != "" || routeParams.CACertificate != "" {
+			return errors.New("a passthrough route cannot serve a certificate, the endpoint serves its own certificate")
+		}
+		if routeParams.InsecureEdgeTerminationPolicy == routev1.InsecureEdgeTerminationPolicyAllow {
+			return errors.New("a passthrough route cannot allow insecure traffic, only None and Redirect policies are supported")
+		}
+	default:
+		return fmt.Errorf("unsupported TLS termination %q", routeParams.TLSTermination)
+	}
+	if routeParams.DestinationCACertificate != "" && routeParams.TLSTermination != routev1.TLSTerminationReencrypt {
+		return errors.New("a destination CA certificate can only be set for a reencrypt route")
+	}
+	if !tls && (routeParams.Certificate != "" || routeParams.Key != "" || routeParams.CACertificate != "" || routeParams.InsecureEdgeTerminationPolicy != "") {
+		return errors.New("TLS settings are set for a route which is not secure")
+	}
+	if (routeParams.Certificate == "") != (routeParams.Key == "") {
+		return errors.New("a route certificate requires its key, and a key requires its certificate")
+	}
+	switch routeParams.InsecureEdgeTerminationPolicy {
+	case "", routev1.InsecureEdgeTerminationPolicyNone, routev1.InsecureEdgeTerminationPolicyAllow, routev1.InsecureEdgeTerminationPolicyRedirect:
+	default:
+		return fmt.Errorf("unsupported insecure edge termination policy %q", routeParams.InsecureEdgeTerminationPolicy)
+	}
+	switch routeParams.WildcardPolicy {
+	case "", routev1.WildcardPolicyNone:
+	case routev1.WildcardPolicySubdomain:
+		if routeParams.Host == "" {
+			return errors.New("a route with a Subdomain wildcard policy requires a host")
+		}
+	default:
+		return fmt.Errorf("unsupported wildcard policy %q", routeParams.WildcardPolicy)
+	}
+	return nil
+}
+
 // getPVCSpec gets a RWO pvc spec
 func getPVCSpec(quantity resource.Quantity) *corev1.PersistentVolumeClaimSpec {
 
@@ -689,6 +808,12 @@ func addVolumeMountToContainers(containers []corev1.Container, volumeName string
 
 // getAllContainers iterates through the devfile components and returns all container components
 func getAllContainers(devfileObj parser.DevfileObj, options common.DevfileOptions) ([]corev1.Container, error) {
+	return getAllContainersWithPullPolicy(devfileObj, options, "")
+}
+
+// getAllContainersWithPullPolicy returns all container components, pulling their images as returned by getImagePullPolicy
+// with the given default pull policy
+func getAllContainersWithPullPolicy(devfileObj parser.DevfileObj, options common.DevfileOptions, defaultPullPolicy corev1.PullPolicy) ([]corev1.Container, error) {
 	var containers []corev1.Container
 
 	options.ComponentOptions = common.ComponentOptions{
@@ -705,15 +830,20 @@ func getAllContainers(devfileObj parser.DevfileObj, options common.DevfileOption
 			return containers, err
 		}
 		ports := convertPorts(comp.Container.Endpoints)
+		imagePullPolicy, err := getImagePullPolicy(comp, defaultPullPolicy)
+		if err != nil {
+			return nil, err
+		}
 		containerParams := containerParams{
-			Name:         comp.Name,
-			Image:        comp.Container.Image,
-			IsPrivileged: false,
-			Command:      comp.Container.Command,
-			Args:         comp.Container.Args,
-			EnvVars:      envVars,
-			ResourceReqs: resourceReqs,
-			Ports:        ports,
+			Name:            comp.Name,
+			Image:           comp.Container.Image,
+			IsPrivileged:    false,
+			Command:         comp.Container.Command,
+			Args:            comp.Container.Args,
+			EnvVars:         envVars,
+			ResourceReqs:    resourceReqs,
+			Ports:           ports,
+			ImagePullPolicy: imagePullPolicy,
 		}
 		container := getContainer(containerParams)
 
@@ -803,8 +933,9 @@ func containerOverridesHandler(comp v1.Component, container *corev1.Container) (
 	return patched, nil
 }
 
-// getContainerAnnotations iterates through container components and returns all annotations
-func getContainerAnnotations(devfileObj parser.DevfileObj, options common.DevfileOptions) (v1.Annotation, error) {
+// getContainerAnnotations iterates through the container components accepted by include and returns all annotations.
+// A nil include accepts the container components running in the shared pod, i.e. without dedicatedPod.
+func getContainerAnnotations(devfileObj parser.DevfileObj, options common.DevfileOptions, include func(componentName string) bool) (v1.Annotation, error) {
 	options.ComponentOptions = common.ComponentOptions{
 		ComponentType: v1.ContainerComponentType,
 	}
@@ -816,8 +947,7 @@ func getContainerAnnotations(devfileObj parser.DevfileObj, options common.Devfil
 	annotations.Service = make(map[string]string)
 	annotations.Deployment = make(map[string]string)
 	for _, comp := range containerComponents {
-		// ToDo: dedicatedPod support: https://github.com/devfile/api/issues/670
-		if comp.Container.DedicatedPod != nil && *comp.Container.DedicatedPod {
+		if (include == nil && isDedicatedPod(comp)) || (include != nil && !include(comp.Name)) {
 			continue
 		}
 		if comp.Container.Annotation != nil {
@@ -829,6 +959,11 @@ func getContainerAnnotations(devfileObj parser.DevfileObj, options common.Devfil
 	return annotations, nil
 }
 
+// isDedicatedPod returns true if the container component runs in its own pod
+func isDedicatedPod(comp v1.Component) bool {
+	return comp.Container != nil && comp.Container.DedicatedPod != nil && *comp.Container.DedicatedPod
+}
+
 func mergeMaps(dest map[string]string, src map[string]string) map[string]string {
 	if dest == nil {
 		dest = make(map[string]string)
@@ -38,6 +38,7 @@ import (
 	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -862,7 +863,7 @@ func TestGetServiceSpec(t *testing.T) {
 				Data: mockDevfileData,
 			}
 
-			serviceSpec, err := getServiceSpec(devObj, tt.labels, tt.filterOptions)
+			serviceSpec, err := getServiceSpec(devObj, tt.labels, tt.filterOptions, nil)
 
 			// Unexpected error
 			if err != nil {
@@ -1226,7 +1227,7 @@ func TestGetPortExposure(t *testing.T) {
 				Data: mockDevfileData,
 			}
 
-			mapCreated, err := getPortExposure(devObj, tt.filterOptions)
+			mapCreated, err := getPortExposure(devObj, tt.filterOptions, nil)
 			// Checks for unexpected error cases
 			if err != nil {
 				t.Errorf("TestGetPortExposure() unexpected error: %v", err)
@@ -1300,6 +1301,19 @@ func TestGetNetworkingV1IngressSpec(t *testing.T) {
 				TLSSecretName: "testTLSSecret",
 			},
 		},
+		{
+			name: "with path type and ingress class",
+			parameter: IngressSpecParams{
+				ServiceName:   "service1",
+				IngressDomain: "test.1.2.3.4.nip.io",
+				PortNumber: intstr.IntOrString{
+					IntVal: 8080,
+				},
+				TLSSecretName:    "testTLSSecret",
+				PathType:         networkingv1.PathTypePrefix,
+				IngressClassName: "nginx",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -1307,6 +1321,19 @@ func TestGetNetworkingV1IngressSpec(t *testing.T) {
 
 			ingressSpec := getNetworkingV1IngressSpec(tt.parameter)
 
+			wantPathType := networkingv1.PathTypeImplementationSpecific
+			if tt.parameter.PathType != "" {
+				wantPathType = tt.parameter.PathType
+			}
+			if *ingressSpec.Rules[0].HTTP.Paths[0].PathType != wantPathType {
+				t.Errorf("TestGetNetworkingV1IngressSpec() error: expected PathType %s, actual %s", wantPathType, *ingressSpec.Rules[0].HTTP.Paths[0].PathType)
+			}
+
+			if (ingressSpec.IngressClassName == nil) != (tt.parameter.IngressClassName == "") ||
+				(ingressSpec.IngressClassName != nil && *ingressSpec.IngressClassName != tt.parameter.IngressClassName) {
+				t.Errorf("TestGetNetworkingV1IngressSpec() error: expected IngressClassName %q, actual %v", tt.parameter.IngressClassName, ingressSpec.IngressClassName)
+			}
+
 			if ingressSpec.Rules[0].Host != tt.parameter.IngressDomain {
 				t.Errorf("TestGetNetworkingV1IngressSpec() error: expected IngressDomain %s, actual %s", tt.parameter.IngressDomain, ingressSpec.Rules[0].Host)
 			}
@@ -1664,7 +1691,7 @@ func TestGetContainerAnnotations(t *testing.T) {
 			devObj := parser.DevfileObj{
 				Data: mockDevfileData,
 			}
-			annotations, err := getContainerAnnotations(devObj, common.DevfileOptions{})
+			annotations, err := getContainerAnnotations(devObj, common.DevfileOptions{}, nil)
 			// Checks for unexpected error cases
 			if err != nil {
 				t.Errorf("TestGetContainerAnnotations(): unexpected error %v", err)
@@ -0,0 +1,290 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Attributes of the volume components configuring their PVCs
+const (
+	// StorageClassAttribute is the storage class name of the PVC
+	StorageClassAttribute = "storage-class"
+	// AccessModesAttribute is the access mode, or the list of access modes, of the PVC.
+	// Defaults to ReadWriteOnce, or to ReadWriteMany if the volume is mounted in several pods.
+	AccessModesAttribute = "access-modes"
+	// VolumeModeAttribute is the volume mode of the PVC, Filesystem or Block
+	VolumeModeAttribute = "volume-mode"
+)
+
+// VolumeComponentsParams is a struct that contains the required data to create the PVCs of the volume components
+type VolumeComponentsParams struct {
+	TypeMeta metav1.TypeMeta
+	// ObjectMeta is the metadata of the PVCs. Each PVC is named <ObjectMeta.Name>-<volume component name>,
+	// or after the volume component if ObjectMeta.Name is empty.
+	ObjectMeta metav1.ObjectMeta
+	// DefaultSize is the size of the PVCs of the volume components without any size. Defaults to DefaultVolumeSize.
+	DefaultSize string
+}
+
+// GetPVCsFromVolumeComponents returns a PVC for each non-ephemeral volume component, and the volume info of every volume
+// component, to be passed to GetVolumesAndVolumeMounts to get the pod volumes and the volume mounts of the containers.
+//
+// The PVCs are configured by the StorageClassAttribute, AccessModesAttribute and VolumeModeAttribute attributes of the
+// volume components. As the containers with dedicatedPod run in their own pod, a volume mounted in several pods must be
+// shared: its PVC defaults to ReadWriteMany, and an error is returned if its access modes do not allow sharing the volume,
+// or if the volume is ephemeral.
+func GetPVCsFromVolumeComponents(devfileObj parser.DevfileObj, volumeParams VolumeComponentsParams) ([]*corev1.PersistentVolumeClaim, map[string]VolumeInfo, error) {
+	volumeComponents, err := devfileObj.Data.GetComponents(common.DevfileOptions{
+		ComponentOptions: common.ComponentOptions{ComponentType: v1.VolumeComponentType},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	volumePods, err := getVolumePods(devfileObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defaultSize := volumeParams.DefaultSize
+	if defaultSize == "" {
+		defaultSize = DefaultVolumeSize
+	}
+
+	var pvcs []*corev1.PersistentVolumeClaim
+	volumeInfos := make(map[string]VolumeInfo)
+	for _, comp := range volumeComponents {
+		pvcName := comp.Name
+		if volumeParams.ObjectMeta.Name != "" {
+			pvcName = getResourceName(volumeParams.ObjectMeta.Name, comp.Name)
+		}
+		volumeInfos[comp.Name] = VolumeInfo{
+			PVCName:    pvcName,
+			VolumeName: comp.Name,
+		}
+
+		shared := len(volumePods[comp.Name]) > 1
+		if comp.Volume.Ephemeral != nil && *comp.Volume.Ephemeral {
+			if shared {
+				return nil, nil, fmt.Errorf("ephemeral volume %s cannot be shared by the pods %s", comp.Name, strings.Join(volumePods[comp.Name], ", "))
+			}
+			continue
+		}
+
+		pvcParams, err := getVolumePVCParams(comp, defaultSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		pvcParams.TypeMeta = volumeParams.TypeMeta
+		pvcParams.ObjectMeta = *volumeParams.ObjectMeta.DeepCopy()
+		pvcParams.ObjectMeta.Name = pvcName
+
+		if shared {
+			if len(pvcParams.AccessModes) == 0 {
+				pvcParams.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+			} else if !canBeShared(pvcParams.AccessModes) {
+				return nil, nil, fmt.Errorf("volume %s is mounted by the pods %s, its access modes must include %s or %s",
+					comp.Name, strings.Join(volumePods[comp.Name], ", "), corev1.ReadWriteMany, corev1.ReadOnlyMany)
+			}
+		}
+		pvcs = append(pvcs, GetPVC(pvcParams))
+	}
+	return pvcs, volumeInfos, nil
+}
+
+// GetVolumeClaimTemplates returns the volume claim templates of a StatefulSet for the non-ephemeral volume components
+// mounted by the given containers, and adds the volume mounts to the containers. Each claim template is named after its
+// volume component, and is configured as the PVCs returned by GetPVCsFromVolumeComponents, except that each replica of
+// the StatefulSet gets its own PVC. The ephemeral volume components are left to GetVolumesAndVolumeMounts.
+// defaultSize is the size of the volume components without any size. Defaults to DefaultVolumeSize.
+func GetVolumeClaimTemplates(devfileObj parser.DevfileObj, containers []corev1.Container, defaultSize string) ([]corev1.PersistentVolumeClaim, error) {
+	return getVolumeClaimTemplates(devfileObj, containers, defaultSize, nil)
+}
+
+// getVolumeClaimTemplates returns the volume claim templates of the volume components accepted by include,
+// as described by GetVolumeClaimTemplates. A nil include accepts all the volume components.
+func getVolumeClaimTemplates(devfileObj parser.DevfileObj, containers []corev1.Container, defaultSize string,
+	include func(volumeName string) bool) ([]corev1.PersistentVolumeClaim, error) {
+	if defaultSize == "" {
+		defaultSize = DefaultVolumeSize
+	}
+	volumeComponents, err := devfileObj.Data.GetComponents(common.DevfileOptions{
+		ComponentOptions: common.ComponentOptions{ComponentType: v1.VolumeComponentType},
+	})
+	if err != nil {
+		return nil, err
+	}
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	running := make(map[string]bool, len(containers))
+	for _, container := range containers {
+		running[container.Name] = true
+	}
+
+	var claimTemplates []corev1.PersistentVolumeClaim
+	for _, comp := range volumeComponents {
+		if comp.Volume.Ephemeral != nil && *comp.Volume.Ephemeral || include != nil && !include(comp.Name) {
+			continue
+		}
+		containerNameToMountPaths := make(map[string][]string)
+		for _, containerComp := range containerComponents {
+			if !running[containerComp.Name] {
+				continue
+			}
+			for _, volumeMount := range containerComp.Container.VolumeMounts {
+				if volumeMount.Name == comp.Name {
+					containerNameToMountPaths[containerComp.Name] = append(containerNameToMountPaths[containerComp.Name], GetVolumeMountPath(volumeMount))
+				}
+			}
+		}
+		if len(containerNameToMountPaths) == 0 {
+			continue
+		}
+
+		pvcParams, err := getVolumePVCParams(comp, defaultSize)
+		if err != nil {
+			return nil, err
+		}
+		pvcParams.ObjectMeta.Name = comp.Name
+		claimTemplates = append(claimTemplates, *GetPVC(pvcParams))
+		addVolumeMountToContainers(containers, comp.Name, containerNameToMountPaths)
+	}
+	return claimTemplates, nil
+}
+
+// getVolumePVCParams returns the size and the attributes of the PVC of the volume component
+func getVolumePVCParams(comp v1.Component, defaultSize string) (PVCParams, error) {
+	size := comp.Volume.Size
+	if size == "" {
+		size = defaultSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return PVCParams{}, fmt.Errorf("error parsing size of volume component %s: %w", comp.Name, err)
+	}
+	pvcParams := PVCParams{Quantity: quantity}
+	if err = setPVCAttributes(comp, &pvcParams); err != nil {
+		return PVCParams{}, err
+	}
+	return pvcParams, nil
+}
+
+// setPVCAttributes sets the storage class, access modes and volume mode of the PVC from the attributes of the volume component
+func setPVCAttributes(comp v1.Component, pvcParams *PVCParams) error {
+	if comp.Attributes.Exists(StorageClassAttribute) {
+		var err error
+		storageClass := comp.Attributes.GetString(StorageClassAttribute, &err)
+		if err != nil {
+			return fmt.Errorf("invalid %s attribute of volume component %s: %w", StorageClassAttribute, comp.Name, err)
+		}
+		pvcParams.StorageClassName = &storageClass
+	}
+
+	if comp.Attributes.Exists(AccessModesAttribute) {
+		var accessModes []string
+		if err := comp.Attributes.GetInto(AccessModesAttribute, &accessModes); err != nil {
+			var accessMode string
+			if err = comp.Attributes.GetInto(AccessModesAttribute, &accessMode); err != nil {
+				return fmt.Errorf("invalid %s attribute of volume component %s, a string or a list of strings is expected", AccessModesAttribute, comp.Name)
+			}
+			accessModes = []string{accessMode}
+		}
+		for _, accessMode := range accessModes {
+			switch mode := corev1.PersistentVolumeAccessMode(accessMode); mode {
+			case corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany, corev1.ReadWriteOncePod:
+				pvcParams.AccessModes = append(pvcParams.AccessModes, mode)
+			default:
+				return fmt.Errorf("invalid access mode %s of volume component %s", accessMode, comp.Name)
+			}
+		}
+	}
+
+	if comp.Attributes.Exists(VolumeModeAttribute) {
+		var err error
+		volumeMode := corev1.PersistentVolumeMode(comp.Attributes.GetString(VolumeModeAttribute, &err))
+		if err != nil {
+			return fmt.Errorf("invalid %s attribute of volume component %s: %w", VolumeModeAttribute, comp.Name, err)
+		}
+		if volumeMode != corev1.PersistentVolumeFilesystem && volumeMode != corev1.PersistentVolumeBlock {
+			return fmt.Errorf("invalid volume mode %s of volume component %s", volumeMode, comp.Name)
+		}
+		pvcParams.VolumeMode = &volumeMode
+	}
+	return nil
+}
+
+// canBeShared returns true if the access modes allow mounting the volume in several pods
+func canBeShared(accessModes []corev1.PersistentVolumeAccessMode) bool {
+	for _, accessMode := range accessModes {
+		if accessMode == corev1.ReadWriteMany || accessMode == corev1.ReadOnlyMany {
+			return true
+		}
+	}
+	return false
+}
+
+// getVolumePods returns the pods mounting each volume component, indexed by volume name and sorted.
+// The pod shared by the container components without dedicatedPod is named after the first of these components.
+func getVolumePods(devfileObj parser.DevfileObj) (map[string][]string, error) {
+	containerComponents, err := devfileObj.Data.GetDevfileContainerComponents(common.DevfileOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var sharedPod string
+	for _, comp := range containerComponents {
+		if !isDedicatedPod(comp) {
+			sharedPod = comp.Name
+			break
+		}
+	}
+
+	volumePods := make(map[string][]string)
+	for _, comp := range containerComponents {
+		pod := comp.Name
+		if !isDedicatedPod(comp) {
+			pod = sharedPod
+		}
+		for _, volumeMount := range comp.Container.VolumeMounts {
+			if !contains(volumePods[volumeMount.Name], pod) {
+				volumePods[volumeMount.Name] = append(volumePods[volumeMount.Name], pod)
+			}
+		}
+	}
+	for _, pods := range volumePods {
+		sort.Strings(pods)
+	}
+	return volumePods, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
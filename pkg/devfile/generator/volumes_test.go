@@ -0,0 +1,154 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const volumesDevfileHeader = `schemaVersion: 2.2.0
+metadata:
+  name: volumes
+components:
+  - name: web
+    container:
+      image: web
+      volumeMounts:
+        - name: data
+        - name: cache
+  - name: worker
+    container:
+      image: worker
+      volumeMounts:
+        - name: data
+        - name: cache
+`
+
+func TestGetPVCsFromVolumeComponents(t *testing.T) {
+	blockMode := corev1.PersistentVolumeBlock
+	tests := []struct {
+		name            string
+		components      string
+		wantPVCs        []corev1.PersistentVolumeClaimSpec
+		wantVolumeInfos map[string]VolumeInfo
+		wantErr         string
+	}{
+		{
+			name: "volumes in the same pod",
+			components: `  - name: data
+    attributes:
+      storage-class: fast
+      access-modes: ReadWriteOncePod
+      volume-mode: Block
+    volume:
+      size: 5Gi
+  - name: cache
+    volume:
+      ephemeral: true
+`,
+			wantPVCs: []corev1.PersistentVolumeClaimSpec{
+				{
+					AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOncePod},
+					StorageClassName: pointer.String("fast"),
+					VolumeMode:       &blockMode,
+					Resources:        corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")}},
+				},
+			},
+			wantVolumeInfos: map[string]VolumeInfo{
+				"data":  {PVCName: "app-data", VolumeName: "data"},
+				"cache": {PVCName: "app-cache", VolumeName: "cache"},
+			},
+		},
+		{
+			name: "volume shared by dedicated pods",
+			components: `      dedicatedPod: true
+  - name: data
+    volume: {}
+`,
+			wantPVCs: []corev1.PersistentVolumeClaimSpec{
+				{
+					AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+					Resources:   corev1.VolumeResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("1Gi")}},
+				},
+			},
+			wantVolumeInfos: map[string]VolumeInfo{
+				"data": {PVCName: "app-data", VolumeName: "data"},
+			},
+		},
+		{
+			name: "read write once volume shared by dedicated pods",
+			components: `      dedicatedPod: true
+  - name: data
+    attributes:
+      access-modes: [ReadWriteOnce]
+    volume: {}
+`,
+			wantErr: "volume data is mounted by the pods web, worker, its access modes must include ReadWriteMany or ReadOnlyMany",
+		},
+		{
+			name: "ephemeral volume shared by dedicated pods",
+			components: `      dedicatedPod: true
+  - name: cache
+    volume:
+      ephemeral: true
+`,
+			wantErr: "ephemeral volume cache cannot be shared by the pods web, worker",
+		},
+		{
+			name: "invalid access mode",
+			components: `  - name: data
+    attributes:
+      access-modes: [ReadWriteAlways]
+    volume: {}
+`,
+			wantErr: "invalid access mode ReadWriteAlways of volume component data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(volumesDevfileHeader + tt.components)})
+			pvcs, volumeInfos, err := GetPVCsFromVolumeComponents(devfileObj, VolumeComponentsParams{
+				ObjectMeta: metav1.ObjectMeta{Name: "app"},
+			})
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetPVCsFromVolumeComponents() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPVCsFromVolumeComponents() unexpected error: %v", err)
+			}
+			var gotPVCs []corev1.PersistentVolumeClaimSpec
+			for _, pvc := range pvcs {
+				gotPVCs = append(gotPVCs, pvc.Spec)
+			}
+			if diff := cmp.Diff(tt.wantPVCs, gotPVCs); diff != "" {
+				t.Errorf("GetPVCsFromVolumeComponents() PVCs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantVolumeInfos, volumeInfos); diff != "" {
+				t.Errorf("GetPVCsFromVolumeComponents() volume infos mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
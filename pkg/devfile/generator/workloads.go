@@ -0,0 +1,257 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Attributes selecting the workload running the pods. As AutoscalingAttribute, they are set either on the devfile,
+// for all the pods, or on a container component, for its pod.
+//
+//	attributes:
+//	  workload-kind: CronJob
+//	  cronjob-schedule: "0 2 * * *"
+const (
+	// WorkloadKindAttribute is the kind of the workload running the pod: Deployment, StatefulSet, Job or CronJob
+	WorkloadKindAttribute = "workload-kind"
+	// CronJobScheduleAttribute is the schedule of a CronJob, in the cron format
+	CronJobScheduleAttribute = "cronjob-schedule"
+)
+
+// WorkloadKind is the kind of the workload running a pod
+type WorkloadKind string
+
+// The kinds of workloads which can run the pods
+const (
+	DeploymentWorkloadKind  WorkloadKind = "Deployment"
+	StatefulSetWorkloadKind WorkloadKind = "StatefulSet"
+	JobWorkloadKind         WorkloadKind = "Job"
+	CronJobWorkloadKind     WorkloadKind = "CronJob"
+)
+
+// DefaultJobBackoffLimit is the default number of retries of the Jobs. A devfile command runs once,
+// and the Job fails as soon as its pod fails.
+const DefaultJobBackoffLimit int32 = 0
+
+// GetWorkloadKind returns the kind of the workload running the pod, set by the WorkloadKindAttribute attribute of
+// the container components it runs or of the devfile, or defaultKind if it is not set.
+// defaultKind defaults to DeploymentWorkloadKind.
+func GetWorkloadKind(devfileObj parser.DevfileObj, podTemplateSpec *corev1.PodTemplateSpec, defaultKind WorkloadKind) (WorkloadKind, error) {
+	kind := defaultKind
+	if kind == "" {
+		kind = DeploymentWorkloadKind
+	}
+	if _, err := getPodAttribute(devfileObj, podTemplateSpec.Spec.Containers, WorkloadKindAttribute, &kind); err != nil {
+		return "", err
+	}
+	switch kind {
+	case DeploymentWorkloadKind, StatefulSetWorkloadKind, JobWorkloadKind, CronJobWorkloadKind:
+		return kind, nil
+	default:
+		return "", fmt.Errorf("invalid workload kind %s, expected %s, %s, %s or %s",
+			kind, DeploymentWorkloadKind, StatefulSetWorkloadKind, JobWorkloadKind, CronJobWorkloadKind)
+	}
+}
+
+// GetCronJobSchedule returns the schedule of the CronJob running the pod, set by the CronJobScheduleAttribute
+// attribute of the container components it runs or of the devfile
+func GetCronJobSchedule(devfileObj parser.DevfileObj, podTemplateSpec *corev1.PodTemplateSpec) (string, error) {
+	var schedule string
+	found, err := getPodAttribute(devfileObj, podTemplateSpec.Spec.Containers, CronJobScheduleAttribute, &schedule)
+	if err != nil {
+		return "", err
+	}
+	if !found || schedule == "" {
+		return "", fmt.Errorf("the %s attribute is required to run a pod as a CronJob", CronJobScheduleAttribute)
+	}
+	return schedule, nil
+}
+
+// StatefulSetParams is a struct that contains the required data to create a StatefulSet object
+type StatefulSetParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// PodTemplateSpec is the pod template of the StatefulSet, as returned by GetPodTemplateSpec
+	PodTemplateSpec   *corev1.PodTemplateSpec
+	PodSelectorLabels map[string]string
+	Replicas          *int32
+	// ServiceName is the name of the Service giving a network identity to the pods, preferably a headless Service
+	ServiceName string
+	// VolumeClaimTemplates are the PVCs created for each replica, as returned by GetVolumeClaimTemplates
+	VolumeClaimTemplates []corev1.PersistentVolumeClaim
+}
+
+// GetStatefulSet returns a StatefulSet running the pod template,
+// annotated with the deployment annotations of the container components without dedicatedPod
+func GetStatefulSet(devfileObj parser.DevfileObj, statefulSetParams StatefulSetParams) (*appsv1.StatefulSet, error) {
+	return getStatefulSet(devfileObj, statefulSetParams, nil)
+}
+
+// getStatefulSet returns a StatefulSet annotated with the deployment annotations of the container components accepted by include.
+// A nil include accepts the container components without dedicatedPod.
+func getStatefulSet(devfileObj parser.DevfileObj, statefulSetParams StatefulSetParams, include func(componentName string) bool) (*appsv1.StatefulSet, error) {
+	if statefulSetParams.PodTemplateSpec == nil {
+		return nil, errors.New("a PodTemplateSpec is required to create a StatefulSet")
+	}
+	objectMeta, err := getWorkloadObjectMeta(devfileObj, statefulSetParams.ObjectMeta, include)
+	if err != nil {
+		return nil, err
+	}
+	return &appsv1.StatefulSet{
+		TypeMeta:   statefulSetParams.TypeMeta,
+		ObjectMeta: objectMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: statefulSetParams.Replicas,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: statefulSetParams.PodSelectorLabels,
+			},
+			Template:             *statefulSetParams.PodTemplateSpec,
+			VolumeClaimTemplates: statefulSetParams.VolumeClaimTemplates,
+			ServiceName:          statefulSetParams.ServiceName,
+		},
+	}, nil
+}
+
+// JobParams is a struct that contains the required data to create a Job object
+type JobParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// PodTemplateSpec is the pod template of the Job, as returned by GetPodTemplateSpec
+	PodTemplateSpec *corev1.PodTemplateSpec
+	// BackoffLimit is the number of retries before the Job fails. Defaults to DefaultJobBackoffLimit.
+	BackoffLimit *int32
+	// TTLSecondsAfterFinished is the time after which the finished Job is deleted. The Job is kept if nil.
+	TTLSecondsAfterFinished *int32
+}
+
+// GetJob returns a Job running the pod template once, annotated with the deployment annotations of the container
+// components without dedicatedPod. Its pods are not restarted, unless the pod template sets the OnFailure restart policy,
+// so that the logs of a failed command are kept.
+func GetJob(devfileObj parser.DevfileObj, jobParams JobParams) (*batchv1.Job, error) {
+	return getJob(devfileObj, jobParams, nil)
+}
+
+// getJob returns a Job annotated with the deployment annotations of the container components accepted by include.
+// A nil include accepts the container components without dedicatedPod.
+func getJob(devfileObj parser.DevfileObj, jobParams JobParams, include func(componentName string) bool) (*batchv1.Job, error) {
+	jobSpec, err := getJobSpec(jobParams)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta, err := getWorkloadObjectMeta(devfileObj, jobParams.ObjectMeta, include)
+	if err != nil {
+		return nil, err
+	}
+	return &batchv1.Job{
+		TypeMeta:   jobParams.TypeMeta,
+		ObjectMeta: objectMeta,
+		Spec:       *jobSpec,
+	}, nil
+}
+
+// CronJobParams is a struct that contains the required data to create a CronJob object
+type CronJobParams struct {
+	TypeMeta   metav1.TypeMeta
+	ObjectMeta metav1.ObjectMeta
+	// Schedule is the schedule of the Jobs, in the cron format, as returned by GetCronJobSchedule
+	Schedule string
+	// ConcurrencyPolicy tells how to treat a Job scheduled while the previous one is still running.
+	// Defaults to Forbid, as a devfile command is not expected to run concurrently with itself.
+	ConcurrencyPolicy batchv1.ConcurrencyPolicy
+	// Job configures the Jobs created by the CronJob, as for GetJob. Its ObjectMeta is the metadata of the Jobs,
+	// and its TypeMeta is ignored.
+	Job JobParams
+}
+
+// GetCronJob returns a CronJob running the pod template on a schedule, in Jobs configured as described by GetJob.
+// The CronJob is annotated with the deployment annotations of the container components without dedicatedPod.
+func GetCronJob(devfileObj parser.DevfileObj, cronJobParams CronJobParams) (*batchv1.CronJob, error) {
+	return getCronJob(devfileObj, cronJobParams, nil)
+}
+
+// getCronJob returns a CronJob annotated with the deployment annotations of the container components accepted by include.
+// A nil include accepts the container components without dedicatedPod.
+func getCronJob(devfileObj parser.DevfileObj, cronJobParams CronJobParams, include func(componentName string) bool) (*batchv1.CronJob, error) {
+	if cronJobParams.Schedule == "" {
+		return nil, errors.New("a schedule is required to create a CronJob")
+	}
+	jobSpec, err := getJobSpec(cronJobParams.Job)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta, err := getWorkloadObjectMeta(devfileObj, cronJobParams.ObjectMeta, include)
+	if err != nil {
+		return nil, err
+	}
+	concurrencyPolicy := cronJobParams.ConcurrencyPolicy
+	if concurrencyPolicy == "" {
+		concurrencyPolicy = batchv1.ForbidConcurrent
+	}
+	return &batchv1.CronJob{
+		TypeMeta:   cronJobParams.TypeMeta,
+		ObjectMeta: objectMeta,
+		Spec: batchv1.CronJobSpec{
+			Schedule:          cronJobParams.Schedule,
+			ConcurrencyPolicy: concurrencyPolicy,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: cronJobParams.Job.ObjectMeta,
+				Spec:       *jobSpec,
+			},
+		},
+	}, nil
+}
+
+// getJobSpec returns the spec of a Job running the pod template once
+func getJobSpec(jobParams JobParams) (*batchv1.JobSpec, error) {
+	if jobParams.PodTemplateSpec == nil {
+		return nil, errors.New("a PodTemplateSpec is required to create a Job")
+	}
+	template := *jobParams.PodTemplateSpec.DeepCopy()
+	switch template.Spec.RestartPolicy {
+	case "", corev1.RestartPolicyAlways:
+		template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	}
+	backoffLimit := jobParams.BackoffLimit
+	if backoffLimit == nil {
+		defaultBackoffLimit := DefaultJobBackoffLimit
+		backoffLimit = &defaultBackoffLimit
+	}
+	return &batchv1.JobSpec{
+		Template:                template,
+		BackoffLimit:            backoffLimit,
+		TTLSecondsAfterFinished: jobParams.TTLSecondsAfterFinished,
+	}, nil
+}
+
+// getWorkloadObjectMeta returns the metadata of a workload, annotated with the deployment annotations of the container
+// components accepted by include
+func getWorkloadObjectMeta(devfileObj parser.DevfileObj, objectMeta metav1.ObjectMeta, include func(componentName string) bool) (metav1.ObjectMeta, error) {
+	containerAnnotations, err := getContainerAnnotations(devfileObj, common.DevfileOptions{}, include)
+	if err != nil {
+		return metav1.ObjectMeta{}, err
+	}
+	objectMeta.Annotations = mergeMaps(objectMeta.Annotations, containerAnnotations.Deployment)
+	return objectMeta, nil
+}
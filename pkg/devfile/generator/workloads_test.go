@@ -0,0 +1,259 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"testing"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetWorkloadKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		devfile     string
+		defaultKind WorkloadKind
+		wantKind    WorkloadKind
+		wantErr     string
+	}{
+		{
+			name: "default kind",
+			devfile: `components:
+  - name: web
+    container:
+      image: web
+`,
+			wantKind: DeploymentWorkloadKind,
+		},
+		{
+			name: "caller default kind",
+			devfile: `components:
+  - name: web
+    container:
+      image: web
+`,
+			defaultKind: StatefulSetWorkloadKind,
+			wantKind:    StatefulSetWorkloadKind,
+		},
+		{
+			name: "component attribute overriding the devfile attribute",
+			devfile: `attributes:
+  workload-kind: StatefulSet
+components:
+  - name: web
+    attributes:
+      workload-kind: Job
+    container:
+      image: web
+`,
+			wantKind: JobWorkloadKind,
+		},
+		{
+			name: "invalid kind",
+			devfile: `components:
+  - name: web
+    attributes:
+      workload-kind: DaemonSet
+    container:
+      image: web
+`,
+			wantErr: "invalid workload kind DaemonSet, expected Deployment, StatefulSet, Job or CronJob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: workloads
+` + tt.devfile)})
+			kind, err := GetWorkloadKind(devfileObj, podTemplateWithContainers(corev1.Container{Name: "web"}), tt.defaultKind)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("GetWorkloadKind() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetWorkloadKind() unexpected error: %v", err)
+			}
+			if kind != tt.wantKind {
+				t.Errorf("GetWorkloadKind() = %s, want %s", kind, tt.wantKind)
+			}
+		})
+	}
+}
+
+func TestGetJob(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: jobs
+components:
+  - name: task
+    container:
+      image: task
+`)})
+	tests := []struct {
+		name              string
+		restartPolicy     corev1.RestartPolicy
+		backoffLimit      *int32
+		wantRestartPolicy corev1.RestartPolicy
+		wantBackoffLimit  int32
+	}{
+		{
+			name:              "defaults",
+			wantRestartPolicy: corev1.RestartPolicyNever,
+			wantBackoffLimit:  DefaultJobBackoffLimit,
+		},
+		{
+			name:              "restart always is not supported by Jobs",
+			restartPolicy:     corev1.RestartPolicyAlways,
+			wantRestartPolicy: corev1.RestartPolicyNever,
+			wantBackoffLimit:  DefaultJobBackoffLimit,
+		},
+		{
+			name:              "restart on failure",
+			restartPolicy:     corev1.RestartPolicyOnFailure,
+			backoffLimit:      pointer.Int32(3),
+			wantRestartPolicy: corev1.RestartPolicyOnFailure,
+			wantBackoffLimit:  3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			podTemplate := podTemplateWithContainers(corev1.Container{Name: "task"})
+			podTemplate.Spec.RestartPolicy = tt.restartPolicy
+			job, err := GetJob(devfileObj, JobParams{
+				ObjectMeta:      metav1.ObjectMeta{Name: "task"},
+				PodTemplateSpec: podTemplate,
+				BackoffLimit:    tt.backoffLimit,
+			})
+			if err != nil {
+				t.Fatalf("GetJob() unexpected error: %v", err)
+			}
+			if got := job.Spec.Template.Spec.RestartPolicy; got != tt.wantRestartPolicy {
+				t.Errorf("GetJob() restart policy = %s, want %s", got, tt.wantRestartPolicy)
+			}
+			if got := *job.Spec.BackoffLimit; got != tt.wantBackoffLimit {
+				t.Errorf("GetJob() backoff limit = %d, want %d", got, tt.wantBackoffLimit)
+			}
+			if podTemplate.Spec.RestartPolicy != tt.restartPolicy {
+				t.Errorf("GetJob() modified the pod template")
+			}
+		})
+	}
+}
+
+func TestGetCronJob(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: cronjobs
+components:
+  - name: backup
+    attributes:
+      workload-kind: CronJob
+      cronjob-schedule: "0 2 * * *"
+    container:
+      image: backup
+  - name: report
+    attributes:
+      workload-kind: CronJob
+    container:
+      image: report
+      dedicatedPod: true
+`)})
+
+	podTemplate := podTemplateWithContainers(corev1.Container{Name: "backup"})
+	schedule, err := GetCronJobSchedule(devfileObj, podTemplate)
+	if err != nil {
+		t.Fatalf("GetCronJobSchedule() unexpected error: %v", err)
+	}
+	cronJob, err := GetCronJob(devfileObj, CronJobParams{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+		Schedule:   schedule,
+		Job:        JobParams{PodTemplateSpec: podTemplate},
+	})
+	if err != nil {
+		t.Fatalf("GetCronJob() unexpected error: %v", err)
+	}
+	if cronJob.Spec.Schedule != "0 2 * * *" {
+		t.Errorf("GetCronJob() schedule = %s, want 0 2 * * *", cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.ConcurrencyPolicy != batchv1.ForbidConcurrent {
+		t.Errorf("GetCronJob() concurrency policy = %s, want %s", cronJob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	}
+	if got := cronJob.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy; got != corev1.RestartPolicyNever {
+		t.Errorf("GetCronJob() restart policy = %s, want %s", got, corev1.RestartPolicyNever)
+	}
+
+	_, err = GetCronJobSchedule(devfileObj, podTemplateWithContainers(corev1.Container{Name: "report"}))
+	if wantErr := "the cronjob-schedule attribute is required to run a pod as a CronJob"; err == nil || err.Error() != wantErr {
+		t.Errorf("GetCronJobSchedule() error = %v, want %s", err, wantErr)
+	}
+}
+
+func TestGetVolumeClaimTemplates(t *testing.T) {
+	devfileObj := parseTestDevfile(t, parser.ParserArgs{Data: []byte(`schemaVersion: 2.2.0
+metadata:
+  name: statefulsets
+components:
+  - name: db
+    container:
+      image: db
+      volumeMounts:
+        - name: data
+          path: /data
+        - name: tmp
+  - name: data
+    attributes:
+      storage-class: fast
+    volume:
+      size: 5Gi
+  - name: tmp
+    volume:
+      ephemeral: true
+  - name: unused
+    volume: {}
+`)})
+	containers := []corev1.Container{{Name: "db"}}
+	claimTemplates, err := GetVolumeClaimTemplates(devfileObj, containers, "")
+	if err != nil {
+		t.Fatalf("GetVolumeClaimTemplates() unexpected error: %v", err)
+	}
+
+	wantClaimTemplates := []corev1.PersistentVolumeClaim{{
+		ObjectMeta: metav1.ObjectMeta{Name: "data"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: pointer.String("fast"),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			},
+		},
+	}}
+	if diff := cmp.Diff(wantClaimTemplates, claimTemplates); diff != "" {
+		t.Errorf("GetVolumeClaimTemplates() mismatch (-want +got):\n%s", diff)
+	}
+	wantMounts := []corev1.VolumeMount{{Name: "data", MountPath: "/data"}}
+	if diff := cmp.Diff(wantMounts, containers[0].VolumeMounts); diff != "" {
+		t.Errorf("GetVolumeClaimTemplates() volume mounts mismatch (-want +got):\n%s", diff)
+	}
+}
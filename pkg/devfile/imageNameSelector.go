@@ -20,19 +20,20 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 
 	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
 	"github.com/devfile/library/v2/pkg/devfile/parser"
 	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	parserUtil "github.com/devfile/library/v2/pkg/devfile/parser/util"
 	"github.com/distribution/distribution/v3/reference"
-	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/json"
 	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
 )
 
 var k8sSerializer = json.NewSerializerWithOptions(
@@ -58,15 +59,23 @@ var k8sSerializer = json.NewSerializerWithOptions(
 // match the base name of the relative image name of the Image Component).
 // But `nodejs-devtools2` or 'ghcr.io/some-user/nodejs-devtools3' do not match the 'nodejs-devtools' image name and won't be replaced.
 //
-// For Kubernetes and OpenShift components, this function assumes that the actual resource manifests are inlined in the components,
-// in order to perform any replacements for matching image names.
-// At the moment, this function only supports replacements in Kubernetes native resource types (Pod, CronJob, Job, DaemonSet; Deployment, ReplicaSet, ReplicationController, StatefulSet).
+// For Kubernetes and OpenShift components, replacements are performed at the image locations known for the kind of each resource,
+// i.e. the built-in locations (see defaultImageLocations) and the ones declared in selector.ImageLocations.
+// Resources of kinds without any known image location are left unchanged.
+// Manifests referenced via URIs are resolved, and inlined only if they contain matching image names.
+// In this case, the original URI is kept in the component attributes so that it can be restored when writing the Devfile.
 //
 // Absolute images and non-matching image references are left unchanged.
 //
 // And the replacement is done by using the following format: "<registry>/<devfileName>-<baseImageName>:<imageTag>",
 // where both <registry>  and <imageTag>  are set by the tool itself (either via auto-detection or via user input).
-func replaceImageNames(d *parser.DevfileObj, registry string, imageTag string) (err error) {
+func replaceImageNames(d *parser.DevfileObj, selector parser.ImageSelectorArgs, devfileUtilsClient parserUtil.DevfileUtils) (err error) {
+	var locations imageLocations
+	locations, err = newImageLocations(append(defaultImageLocations(), selector.ImageLocations...))
+	if err != nil {
+		return err
+	}
+
 	var imageComponents []v1.Component
 	imageComponents, err = d.Data.GetComponents(common.DevfileOptions{
 		ComponentOptions: common.ComponentOptions{ComponentType: v1.ImageComponentType},
@@ -92,11 +101,11 @@ func replaceImageNames(d *parser.DevfileObj, registry string, imageTag string) (
 		if d.GetMetadataName() != "" {
 			replacement = fmt.Sprintf("%s-%s", d.GetMetadataName(), replacement)
 		}
-		if registry != "" {
-			replacement = fmt.Sprintf("%s/%s", strings.TrimSuffix(registry, "/"), replacement)
+		if selector.Registry != "" {
+			replacement = fmt.Sprintf("%s/%s", strings.TrimSuffix(selector.Registry, "/"), replacement)
 		}
-		if imageTag != "" {
-			replacement += fmt.Sprintf(":%s", imageTag)
+		if selector.Tag != "" {
+			replacement += fmt.Sprintf(":%s", selector.Tag)
 		}
 
 		// Replace so that the image can be built and pushed to the registry specified by the tool.
@@ -109,7 +118,7 @@ func replaceImageNames(d *parser.DevfileObj, registry string, imageTag string) (
 		}
 
 		// Replace in matching Kubernetes and OpenShift components
-		err = handleKubernetesLikeComponents(d, baseImageName, replacement)
+		err = handleKubernetesLikeComponents(d, locations, baseImageName, replacement, devfileUtilsClient)
 		if err != nil {
 			return err
 		}
@@ -174,7 +183,7 @@ func handleContainerComponents(d *parser.DevfileObj, baseImageName, replacement
 	return nil
 }
 
-func handleKubernetesLikeComponents(d *parser.DevfileObj, baseImageName, replacement string) error {
+func handleKubernetesLikeComponents(d *parser.DevfileObj, locations imageLocations, baseImageName, replacement string, devfileUtilsClient parserUtil.DevfileUtils) error {
 	var allK8sOcComponents []v1.Component
 
 	k8sComponents, err := d.Data.GetComponents(common.DevfileOptions{
@@ -193,112 +202,285 @@ func handleKubernetesLikeComponents(d *parser.DevfileObj, baseImageName, replace
 	}
 	allK8sOcComponents = append(allK8sOcComponents, ocComponents...)
 
-	updateImageInPodSpecIfNeeded := func(obj runtime.Object, ps *corev1.PodSpec) (string, error) {
-		handleContainer := func(c *corev1.Container) (match bool, err error) {
-			match, err = hasMatch(baseImageName, c.Image)
+	replaceIfMatching := func(image string) (string, bool) {
+		match, err := hasMatch(baseImageName, image)
+		if err != nil || !match {
+			// Values that are not valid image references (like Tekton parameter references) are left unchanged
+			return image, false
+		}
+		return replacement, true
+	}
+
+	for _, comp := range allK8sOcComponents {
+		var location *v1.K8sLikeComponentLocation
+		if comp.Kubernetes != nil {
+			location = &comp.Kubernetes.K8sLikeComponentLocation
+		} else {
+			location = &comp.Openshift.K8sLikeComponentLocation
+		}
+
+		if location.Inlined != "" {
+			newContent, changed, err := replaceImagesInK8sContent(location.Inlined, locations, replaceIfMatching)
 			if err != nil {
-				return false, err
+				return err
 			}
-			if !match {
-				return false, nil
+			if changed {
+				location.Inlined = newContent
 			}
-			c.Image = replacement
-			return true, nil
+			continue
 		}
-		for i := range ps.Containers {
-			if _, err = handleContainer(&ps.Containers[i]); err != nil {
-				return "", err
-			}
+
+		if location.Uri == "" {
+			continue
 		}
-		for i := range ps.InitContainers {
-			if _, err = handleContainer(&ps.InitContainers[i]); err != nil {
-				return "", err
-			}
+		content, err := parser.ReadKubernetesDefinitionFromURI(location.Uri, d.Ctx, devfileUtilsClient)
+		if err != nil {
+			return err
+		}
+		newContent, changed, err := replaceImagesInK8sContent(string(content), locations, replaceIfMatching)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			continue
+		}
+		// Inline the updated content the same way the parser does when converting URIs to inlined content,
+		// so that the original URI is restored when writing the Devfile.
+		if comp.Attributes == nil {
+			comp.Attributes = attributes.Attributes{}
+		}
+		comp.Attributes.PutString(parser.K8sLikeComponentOriginalURIKey, location.Uri)
+		location.Inlined = newContent
+		location.Uri = ""
+		d.Ctx.SetConvertUriToInlined(true)
+		err = d.Data.UpdateComponent(comp)
+		if err != nil {
+			return err
 		}
-		for i := range ps.EphemeralContainers {
-			if _, err = handleContainer((*corev1.Container)(&ps.EphemeralContainers[i].EphemeralContainerCommon)); err != nil {
-				return "", err
+	}
+
+	return nil
+}
+
+// replaceImagesInK8sContent replaces the image names at the known image locations of all resources in the given multi-document content.
+// Resources without any replaced image name are kept as is.
+func replaceImagesInK8sContent(content string, locations imageLocations, replace func(image string) (string, bool)) (newContent string, changed bool, err error) {
+	multidocReader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewBufferString(content)))
+	var yamlAsStringList []string
+	var buf []byte
+	for {
+		buf, err = multidocReader.Read()
+		if err != nil {
+			if err == io.EOF {
+				break
 			}
+			return "", false, err
 		}
 
-		//Encode obj back into a YAML string
-		var s strings.Builder
-		err = k8sSerializer.Encode(obj, &s)
+		var newYaml string
+		var docChanged bool
+		newYaml, docChanged, err = replaceImagesInK8sResource(buf, locations, replace)
 		if err != nil {
-			return "", err
+			return "", false, err
 		}
+		changed = changed || docChanged
+		yamlAsStringList = append(yamlAsStringList, strings.TrimSpace(newYaml))
+	}
+
+	return strings.Join(yamlAsStringList, "\n---\n"), changed, nil
+}
 
-		return s.String(), nil
+// replaceImagesInK8sResource replaces the image names in a single resource, and returns the resource as a YAML string.
+func replaceImagesInK8sResource(buf []byte, locations imageLocations, replace func(image string) (string, bool)) (string, bool, error) {
+	var obj map[string]interface{}
+	if err := yaml.Unmarshal(buf, &obj); err != nil || obj == nil {
+		// Use raw string as it is, as it might not be a valid Kubernetes resource
+		return string(buf), false, nil
+	}
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return string(buf), false, nil
 	}
 
-	handleK8sContent := func(content string) (newContent string, err error) {
-		multidocReader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewBufferString(content)))
-		var yamlAsStringList []string
-		var buf []byte
-		var obj runtime.Object
-		for {
-			buf, err = multidocReader.Read()
-			if err != nil {
-				if err == io.EOF {
-					break
-				}
-				return "", err
-			}
+	changed := false
+	for _, path := range locations.pathsFor(gv.WithKind(kind)) {
+		changed = replaceAtImagePath(obj, path, replace) || changed
+	}
+	if !changed {
+		return string(buf), false, nil
+	}
 
-			obj, _, err = k8sSerializer.Decode(buf, nil, nil)
-			if err != nil {
-				// Use raw string as it is, as it might be a Custom Resource with a Kind that is not known
-				// by the K8s decoder.
-				yamlAsStringList = append(yamlAsStringList, strings.TrimSpace(string(buf)))
-				continue
-			}
+	yamlBytes, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", false, err
+	}
+	// Encode resources known by the K8s decoder via their typed form, so that they are output in their canonical form.
+	if typedObj, _, err := k8sSerializer.Decode(yamlBytes, nil, nil); err == nil {
+		var s strings.Builder
+		if err = k8sSerializer.Encode(typedObj, &s); err != nil {
+			return "", false, err
+		}
+		return s.String(), true, nil
+	}
+	return string(yamlBytes), true, nil
+}
 
-			newYaml := string(buf)
-			switch r := obj.(type) {
-			case *batchv1.CronJob:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.JobTemplate.Spec.Template.Spec)
-			case *appsv1.DaemonSet:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			case *appsv1.Deployment:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			case *batchv1.Job:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			case *corev1.Pod:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec)
-			case *appsv1.ReplicaSet:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			case *corev1.ReplicationController:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			case *appsv1.StatefulSet:
-				newYaml, err = updateImageInPodSpecIfNeeded(r, &r.Spec.Template.Spec)
-			}
+// defaultImageLocations returns the built-in image locations, for core Kubernetes workload resources
+// and for some common custom resources embedding pod or container specs.
+func defaultImageLocations() []parser.ImageLocation {
+	podSpecPaths := func(prefix string) []string {
+		return []string{
+			prefix + ".containers[*].image",
+			prefix + ".initContainers[*].image",
+			prefix + ".ephemeralContainers[*].image",
+		}
+	}
+	podTemplatePaths := podSpecPaths(".spec.template.spec")
+	tektonTaskPaths := []string{".spec.steps[*].image", ".spec.sidecars[*].image", ".spec.stepTemplate.image"}
+
+	return []parser.ImageLocation{
+		{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, Paths: podSpecPaths(".spec")},
+		{GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ReplicationController"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "DaemonSet"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "StatefulSet"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "Job"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "batch", Version: "v1", Kind: "CronJob"}, Paths: podSpecPaths(".spec.jobTemplate.spec.template.spec")},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "apps.openshift.io", Kind: "DeploymentConfig"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "serving.knative.dev", Kind: "Service"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "argoproj.io", Kind: "Rollout"}, Paths: podTemplatePaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "tekton.dev", Kind: "Task"}, Paths: tektonTaskPaths},
+		{GroupVersionKind: schema.GroupVersionKind{Group: "tekton.dev", Kind: "ClusterTask"}, Paths: tektonTaskPaths},
+	}
+}
+
+// imagePathSegment is a single segment of an image location path
+type imagePathSegment struct {
+	field string
+	// isList is true if the field holds a list of items
+	isList bool
+	// index is the index of the selected item in the list, or -1 if all items are selected
+	index int
+}
+
+// imageLocations indexes the parsed image location paths by Group and Kind
+type imageLocations map[schema.GroupKind][]parsedImageLocation
+
+type parsedImageLocation struct {
+	version string
+	paths   [][]imagePathSegment
+}
 
+// newImageLocations parses the paths of all the given image locations
+func newImageLocations(locations []parser.ImageLocation) (imageLocations, error) {
+	result := make(imageLocations)
+	for _, location := range locations {
+		if location.GroupVersionKind.Kind == "" {
+			return nil, fmt.Errorf("missing kind in image location %v", location.GroupVersionKind)
+		}
+		parsed := parsedImageLocation{version: location.GroupVersionKind.Version}
+		for _, p := range location.Paths {
+			segments, err := parseImagePath(p)
 			if err != nil {
-				return "", err
+				return nil, fmt.Errorf("invalid image location path for %v: %w", location.GroupVersionKind, err)
 			}
-
-			yamlAsStringList = append(yamlAsStringList, strings.TrimSpace(newYaml))
+			parsed.paths = append(parsed.paths, segments)
 		}
+		gk := location.GroupVersionKind.GroupKind()
+		result[gk] = append(result[gk], parsed)
+	}
+	return result, nil
+}
 
-		return strings.Join(yamlAsStringList, "\n---\n"), nil
+// pathsFor returns all the image location paths matching the given GroupVersionKind
+func (l imageLocations) pathsFor(gvk schema.GroupVersionKind) [][]imagePathSegment {
+	var paths [][]imagePathSegment
+	for _, location := range l[gvk.GroupKind()] {
+		if location.version == "" || location.version == gvk.Version {
+			paths = append(paths, location.paths...)
+		}
 	}
+	return paths
+}
 
-	var newContent string
-	for _, comp := range allK8sOcComponents {
-		if comp.Kubernetes != nil {
-			newContent, err = handleK8sContent(comp.Kubernetes.Inlined)
-			if err != nil {
-				return err
+// parseImagePath parses a JSONPath-style expression like ".spec.containers[*].image" into segments
+func parseImagePath(p string) ([]imagePathSegment, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(p), ".")
+	if trimmed == "" {
+		return nil, fmt.Errorf("empty path %q", p)
+	}
+	var segments []imagePathSegment
+	for _, s := range strings.Split(trimmed, ".") {
+		segment := imagePathSegment{field: s, index: -1}
+		if i := strings.Index(s, "["); i >= 0 {
+			if !strings.HasSuffix(s, "]") {
+				return nil, fmt.Errorf("unterminated list selector in path %q", p)
 			}
-			comp.Kubernetes.Inlined = newContent
-		} else {
-			newContent, err = handleK8sContent(comp.Openshift.Inlined)
-			if err != nil {
-				return err
+			segment.field = s[:i]
+			segment.isList = true
+			selector := s[i+1 : len(s)-1]
+			if selector != "*" {
+				index, err := strconv.Atoi(selector)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid list selector %q in path %q", selector, p)
+				}
+				segment.index = index
 			}
-			comp.Openshift.Inlined = newContent
 		}
+		if segment.field == "" {
+			return nil, fmt.Errorf("empty field name in path %q", p)
+		}
+		segments = append(segments, segment)
 	}
+	return segments, nil
+}
 
-	return nil
+// replaceAtImagePath walks the given unstructured object along the path, and calls replace on every string value found at the end of the path.
+// It returns true if any value has been replaced.
+func replaceAtImagePath(node interface{}, path []imagePathSegment, replace func(image string) (string, bool)) bool {
+	m, ok := node.(map[string]interface{})
+	if !ok || len(path) == 0 {
+		return false
+	}
+	segment := path[0]
+	value, ok := m[segment.field]
+	if !ok {
+		return false
+	}
+	last := len(path) == 1
+
+	handle := func(v interface{}, set func(interface{})) bool {
+		if !last {
+			return replaceAtImagePath(v, path[1:], replace)
+		}
+		image, ok := v.(string)
+		if !ok {
+			return false
+		}
+		newImage, replaced := replace(image)
+		if replaced {
+			set(newImage)
+		}
+		return replaced
+	}
+
+	if !segment.isList {
+		return handle(value, func(v interface{}) { m[segment.field] = v })
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return false
+	}
+	changed := false
+	for i := range items {
+		if segment.index >= 0 && segment.index != i {
+			continue
+		}
+		i := i
+		changed = handle(items[i], func(v interface{}) { items[i] = v }) || changed
+	}
+	return changed
 }
@@ -17,15 +17,19 @@ package devfile
 
 import (
 	"fmt"
+	"path"
 	"strings"
 	"testing"
 
 	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
 	"github.com/devfile/library/v2/pkg/devfile/parser"
+	devfileCtx "github.com/devfile/library/v2/pkg/devfile/parser/context"
 	"github.com/devfile/library/v2/pkg/devfile/parser/data"
 	"github.com/devfile/library/v2/pkg/devfile/parser/data/v2/common"
+	"github.com/devfile/library/v2/pkg/testingutil/filesystem"
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func Test_replaceImageNames(t *testing.T) {
@@ -264,7 +268,7 @@ func Test_replaceImageNames(t *testing.T) {
 				t.Errorf("unexpected error while building DevfileObj: %v", err)
 				return
 			}
-			err = replaceImageNames(devfileObj, targetRegistry, targetImageTag)
+			err = replaceImageNames(devfileObj, parser.ImageSelectorArgs{Registry: targetRegistry, Tag: targetImageTag}, nil)
 			if tt.wantErr != (err != nil) {
 				t.Errorf("replaceImageNames() unexpected error: %v, wantErr: %v", err, tt.wantErr)
 			}
@@ -717,3 +721,158 @@ spec:
   randomField: 77
 `)
 }
+
+func Test_replaceImageNames_customLocationsAndURIs(t *testing.T) {
+	const (
+		devfileName    = "my-component-app"
+		targetRegistry = "localhost:5000/my-org"
+		targetImageTag = "dev"
+		devfilePath    = "/my-devfile/devfile.yaml"
+		manifestURI    = "kubernetes/manifests.yaml"
+	)
+	replacement := fmt.Sprintf("%s/%s-%s:%s", targetRegistry, devfileName, "my-app", targetImageTag)
+
+	knativeManifest := func(image string) string {
+		return strings.TrimSpace(fmt.Sprintf(`apiVersion: serving.knative.dev/v1
+kind: Service
+metadata:
+  name: my-ksvc
+spec:
+  template:
+    spec:
+      containers:
+      - image: %s
+        name: app
+`, image))
+	}
+	tektonManifest := func(image string) string {
+		return strings.TrimSpace(fmt.Sprintf(`apiVersion: tekton.dev/v1
+kind: Task
+metadata:
+  name: my-task
+spec:
+  steps:
+  - image: %s
+    name: build
+  - image: $(params.builderImage)
+    name: other
+`, image))
+	}
+	crontabManifest := func(image string) string {
+		return strings.TrimSpace(fmt.Sprintf(`apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-crontab
+spec:
+  image: %s
+`, image))
+	}
+
+	tests := []struct {
+		name             string
+		imageLocations   []parser.ImageLocation
+		uriContent       string
+		wantURIContent   string
+		wantURIInlined   bool
+		wantInlined      string
+		inlinedComponent string
+		wantErr          bool
+	}{
+		{
+			name:             "built-in locations for custom resources in inlined and URI-referenced manifests",
+			inlinedComponent: strings.Join([]string{knativeManifest("my-app:1"), crontabManifest("my-app")}, "\n---\n"),
+			wantInlined:      strings.Join([]string{knativeManifest(replacement), crontabManifest("my-app")}, "\n---\n"),
+			uriContent:       tektonManifest("my-app"),
+			wantURIContent:   tektonManifest(replacement),
+			wantURIInlined:   true,
+		},
+		{
+			name: "locations declared by the caller",
+			imageLocations: []parser.ImageLocation{
+				{GroupVersionKind: schema.GroupVersionKind{Group: "stable.example.com", Version: "v1", Kind: "CronTab"}, Paths: []string{".spec.image"}},
+			},
+			inlinedComponent: crontabManifest("my-app"),
+			wantInlined:      crontabManifest(replacement),
+			uriContent:       crontabManifest("quay.io/other/image"),
+		},
+		{
+			name: "invalid location path",
+			imageLocations: []parser.ImageLocation{
+				{GroupVersionKind: schema.GroupVersionKind{Kind: "CronTab"}, Paths: []string{".spec.containers[x].image"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := filesystem.NewFakeFs()
+			if err := fs.WriteFile(path.Join(path.Dir(devfilePath), manifestURI), []byte(tt.uriContent), 0644); err != nil {
+				t.Fatalf("unexpected error while writing manifest: %v", err)
+			}
+			dData, err := data.NewDevfileData(string(data.APISchemaVersion220))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			metadata := dData.GetMetadata()
+			metadata.Name = devfileName
+			dData.SetMetadata(metadata)
+			uriComponent := v1.Component{
+				Name: "k-uri",
+				ComponentUnion: v1.ComponentUnion{
+					Kubernetes: &v1.KubernetesComponent{
+						K8sLikeComponent: v1.K8sLikeComponent{
+							K8sLikeComponentLocation: v1.K8sLikeComponentLocation{Uri: manifestURI},
+						},
+					},
+				},
+			}
+			inlinedComponent := buildInlinedKubernetesComponent("k-inlined", "", "")
+			inlinedComponent.Kubernetes.Inlined = tt.inlinedComponent
+			err = dData.AddComponents([]v1.Component{buildImageComponent("i-my-app", "my-app"), uriComponent, inlinedComponent})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			d := parser.DevfileObj{Ctx: devfileCtx.FakeContext(fs, devfilePath), Data: dData}
+
+			err = replaceImageNames(&d, parser.ImageSelectorArgs{Registry: targetRegistry, Tag: targetImageTag, ImageLocations: tt.imageLocations}, nil)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("replaceImageNames() unexpected error: %v, wantErr: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			components, err := d.Data.GetComponents(common.DevfileOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, comp := range components {
+				switch comp.Name {
+				case "k-inlined":
+					if diff := cmp.Diff(tt.wantInlined, comp.Kubernetes.Inlined); diff != "" {
+						t.Errorf("replaceImageNames() mismatch with inlined component (-want +got):\n%s", diff)
+					}
+				case "k-uri":
+					if !tt.wantURIInlined {
+						if comp.Kubernetes.Uri != manifestURI || comp.Kubernetes.Inlined != "" {
+							t.Errorf("replaceImageNames() unexpected change of component referenced by URI: %v", comp.Kubernetes)
+						}
+						continue
+					}
+					if diff := cmp.Diff(tt.wantURIContent, comp.Kubernetes.Inlined); diff != "" {
+						t.Errorf("replaceImageNames() mismatch with URI-referenced component (-want +got):\n%s", diff)
+					}
+					if comp.Kubernetes.Uri != "" {
+						t.Errorf("replaceImageNames() expected uri to be reset, got %q", comp.Kubernetes.Uri)
+					}
+					if got := comp.Attributes.GetString(parser.K8sLikeComponentOriginalURIKey, nil); got != manifestURI {
+						t.Errorf("replaceImageNames() expected original URI attribute %q, got %q", manifestURI, got)
+					}
+					if !d.Ctx.GetConvertUriToInlined() {
+						t.Errorf("replaceImageNames() expected devfile context to be marked as converted from URI to inlined")
+					}
+				}
+			}
+		})
+	}
+}
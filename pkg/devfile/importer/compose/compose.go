@@ -0,0 +1,656 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compose imports a Compose file into a devfile.
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	devfilepkg "github.com/devfile/api/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/importer"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RunCommandID is the id of the run command derived from the command of a service
+	RunCommandID = "run"
+
+	defaultDockerfile = "Dockerfile"
+	maxEndpointLength = 15
+)
+
+// idleCommand keeps running the container of the service whose command is run by the run command
+var idleCommand = []string{"tail", "-f", "/dev/null"}
+
+// Options is a struct that contains the options to import a Compose file
+type Options struct {
+	// Name is the name of the devfile. Defaults to the name of the Compose project.
+	Name string
+	// DevfilePath is the path the devfile is written to. Defaults to importer.DefaultDevfilePath.
+	DevfilePath string
+	// BaseDir is the directory of the Compose file, relative to the directory of the devfile. The relative paths of
+	// the Compose file, such as the build contexts, are resolved against it. Defaults to the directory of the devfile.
+	BaseDir string
+	// SchemaVersion is the schema version of the devfile. Defaults to importer.DefaultSchemaVersion.
+	SchemaVersion string
+}
+
+// Import builds a devfile from the content of a Compose file.
+// It returns the devfile, and warnings for the Compose constructs which cannot be represented in a devfile.
+//
+// Each service becomes a container component named after the service:
+// - the entrypoint and command become the container command and args
+// - the published ports become public endpoints, and the exposed ports internal endpoints
+// - the named volumes become volume components mounted in the container
+// - a bind mount of the project directory becomes the sourceMapping of the container
+// - the resource limits and reservations become the container resource limits and requests
+// - a build section becomes an Image component building the image of the container, with a Dockerfile uri and buildContext
+//
+// The run command of the devfile runs the command of a service, preferably one which is built from the project. As
+// the run command is executed in the running container, the container of this service runs an idle command instead.
+func Import(content []byte, opts Options) (parser.DevfileObj, []string, error) {
+	var file composeFile
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return parser.DevfileObj{}, nil, errors.Wrap(err, "failed to parse Compose file")
+	}
+	if len(file.Services) == 0 {
+		return parser.DevfileObj{}, nil, errors.New("the Compose file has no service")
+	}
+
+	devfileObj, err := importer.NewDevfileObj(opts.DevfilePath, opts.SchemaVersion)
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+
+	i := &composeImporter{
+		baseDir:        path.Clean("./" + opts.BaseDir),
+		names:          importer.NewNames(),
+		endpointNames:  importer.NewNames(),
+		volumes:        map[string]string{},
+		volumeServices: map[string][]string{},
+	}
+	for key := range file.Others {
+		i.warnf("top-level element %s is not supported", key)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = file.Name
+	}
+	if name != "" {
+		devfileObj.Data.SetMetadata(devfilepkg.DevfileMetadata{Name: importer.SanitizeName(name, 0)})
+	}
+
+	serviceNames := make([]string, 0, len(file.Services))
+	for serviceName := range file.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+
+	// component names are reserved first, so that they are derived from the service names whenever possible
+	componentNames := map[string]string{}
+	for _, serviceName := range serviceNames {
+		componentNames[serviceName] = i.names.Unique(serviceName, 0)
+	}
+
+	var containers, images []v1.Component
+	services := map[string]*importedService{}
+	for _, serviceName := range serviceNames {
+		s, err := i.importService(serviceName, componentNames[serviceName], file.Services[serviceName])
+		if err != nil {
+			return parser.DevfileObj{}, nil, errors.Wrapf(err, "failed to import service %s", serviceName)
+		}
+		services[serviceName] = s
+		containers = append(containers, s.container)
+		if s.image != nil {
+			images = append(images, *s.image)
+		}
+	}
+
+	volumes, err := i.importVolumes(file.Volumes)
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+
+	var commands []v1.Command
+	runServiceName := selectRunService(serviceNames, services)
+	if runServiceName != "" {
+		s := services[runServiceName]
+		container := s.container.Container
+		commandLine := importer.ShellJoin(append(append([]string{}, s.entrypoint...), s.command...))
+		container.Command = idleCommand[:1]
+		container.Args = idleCommand[1:]
+		commands = append(commands, v1.Command{
+			Id: RunCommandID,
+			CommandUnion: v1.CommandUnion{
+				Exec: &v1.ExecCommand{
+					LabeledCommand: v1.LabeledCommand{
+						BaseCommand: v1.BaseCommand{
+							Group: &v1.CommandGroup{Kind: v1.RunCommandGroupKind, IsDefault: pointer.Bool(true)},
+						},
+					},
+					CommandLine: commandLine,
+					Component:   s.container.Name,
+					WorkingDir:  s.workingDir,
+				},
+			},
+		})
+	} else {
+		i.warnf("no service has a command, no run command is defined")
+	}
+	for _, serviceName := range serviceNames {
+		if services[serviceName].workingDir != "" && serviceName != runServiceName {
+			i.warnf("service %s: working_dir is only supported for the service run by the run command", serviceName)
+		}
+	}
+
+	components := append(append(containers, volumes...), images...)
+	if err = devfileObj.Data.AddComponents(components); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if err = devfileObj.Data.AddCommands(commands); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+
+	return devfileObj, i.warnings, nil
+}
+
+// selectRunService selects the service whose command is run by the run command: the first service built from the
+// project with a command, or else the first service with a command
+func selectRunService(serviceNames []string, services map[string]*importedService) string {
+	for _, built := range []bool{true, false} {
+		for _, serviceName := range serviceNames {
+			s := services[serviceName]
+			if len(s.entrypoint)+len(s.command) > 0 && (!built || s.image != nil) {
+				return serviceName
+			}
+		}
+	}
+	return ""
+}
+
+// composeFile is the content of a Compose file
+type composeFile struct {
+	Name     string                            `json:"name,omitempty"`
+	Services map[string]map[string]interface{} `json:"services"`
+	Volumes  map[string]map[string]interface{} `json:"volumes,omitempty"`
+	// Others are the top-level elements which are not supported
+	Others map[string]interface{} `json:"-"`
+}
+
+func (f *composeFile) UnmarshalJSON(data []byte) error {
+	type plain composeFile
+	if err := json.Unmarshal(data, (*plain)(f)); err != nil {
+		return err
+	}
+	var all map[string]interface{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for key, value := range all {
+		switch key {
+		case "name", "services", "volumes", "version":
+		default:
+			if f.Others == nil {
+				f.Others = map[string]interface{}{}
+			}
+			f.Others[key] = value
+		}
+	}
+	return nil
+}
+
+// importedService is the result of the import of a service
+type importedService struct {
+	container  v1.Component
+	image      *v1.Component
+	entrypoint []string
+	command    []string
+	workingDir string
+}
+
+// composeImporter holds the state shared by the functions importing the services
+type composeImporter struct {
+	// baseDir is the directory of the Compose file, relative to the directory of the devfile
+	baseDir       string
+	names         *importer.Names
+	endpointNames *importer.Names
+	// volumes are the names of the volume components, indexed by Compose volume name
+	volumes map[string]string
+	// volumeServices are the services mounting each Compose volume
+	volumeServices map[string][]string
+	warnings       []string
+}
+
+func (i *composeImporter) warnf(format string, a ...interface{}) {
+	i.warnings = append(i.warnings, fmt.Sprintf(format, a...))
+}
+
+func (i *composeImporter) importService(serviceName, componentName string, service map[string]interface{}) (*importedService, error) {
+	s := &importedService{}
+	container := &v1.ContainerComponent{
+		Container: v1.Container{
+			MountSources: pointer.Bool(false),
+		},
+	}
+	var err error
+
+	for _, key := range sortedKeys(service) {
+		value := service[key]
+		switch key {
+		case "image":
+			container.Image = fmt.Sprint(value)
+		case "build":
+			s.image, err = i.importBuild(serviceName, value)
+		case "entrypoint":
+			s.entrypoint, err = stringOrList(value)
+		case "command":
+			s.command, err = stringOrList(value)
+		case "working_dir":
+			s.workingDir = fmt.Sprint(value)
+		case "environment":
+			container.Env, err = importEnvironment(value)
+		case "ports":
+			err = i.importPorts(serviceName, container, value)
+		case "expose":
+			err = i.importExpose(container, value)
+		case "volumes":
+			err = i.importServiceVolumes(serviceName, container, value)
+		case "deploy":
+			err = i.importDeploy(serviceName, container, value)
+		default:
+			i.warnf("service %s: %s is not supported", serviceName, key)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", key)
+		}
+	}
+
+	if s.image != nil {
+		if container.Image == "" {
+			container.Image = importer.SanitizeName(serviceName, 0)
+		}
+		s.image.Image.ImageName = container.Image
+	}
+	if container.Image == "" {
+		return nil, errors.New("the service has neither an image nor a build section")
+	}
+	container.Command = s.entrypoint
+	container.Args = s.command
+
+	s.container = v1.Component{
+		Name: componentName,
+		ComponentUnion: v1.ComponentUnion{
+			Container: container,
+		},
+	}
+	return s, nil
+}
+
+// importBuild converts a build section into an Image component
+func (i *composeImporter) importBuild(serviceName string, value interface{}) (*v1.Component, error) {
+	buildContext := ""
+	dockerfile := defaultDockerfile
+	switch build := value.(type) {
+	case string:
+		buildContext = build
+	case map[string]interface{}:
+		for _, key := range sortedKeys(build) {
+			v := build[key]
+			switch key {
+			case "context":
+				buildContext = fmt.Sprint(v)
+			case "dockerfile":
+				dockerfile = fmt.Sprint(v)
+			default:
+				i.warnf("service %s: build %s is not supported", serviceName, key)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected build section %v", value)
+	}
+	if buildContext == "" {
+		buildContext = "."
+	}
+	if i.baseDir != "." && !isURL(buildContext) && !path.IsAbs(buildContext) {
+		buildContext = path.Join(i.baseDir, buildContext)
+	}
+
+	uri := dockerfile
+	if !isURL(buildContext) && !path.IsAbs(dockerfile) {
+		uri = path.Join(buildContext, dockerfile)
+	}
+	return &v1.Component{
+		Name: i.names.Unique(serviceName+"-image", 0),
+		ComponentUnion: v1.ComponentUnion{
+			Image: &v1.ImageComponent{
+				Image: v1.Image{
+					ImageUnion: v1.ImageUnion{
+						Dockerfile: &v1.DockerfileImage{
+							DockerfileSrc: v1.DockerfileSrc{Uri: uri},
+							Dockerfile:    v1.Dockerfile{BuildContext: buildContext},
+						},
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+// importEnvironment converts an environment section, either a map or a list of NAME=VALUE
+func importEnvironment(value interface{}) ([]v1.EnvVar, error) {
+	var env []v1.EnvVar
+	switch environment := value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(environment))
+		for name := range environment {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			envValue := ""
+			if environment[name] != nil {
+				envValue = fmt.Sprint(environment[name])
+			}
+			env = append(env, v1.EnvVar{Name: name, Value: envValue})
+		}
+	case []interface{}:
+		for _, item := range environment {
+			name, envValue, _ := strings.Cut(fmt.Sprint(item), "=")
+			env = append(env, v1.EnvVar{Name: name, Value: envValue})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected environment %v", value)
+	}
+	return env, nil
+}
+
+// portPattern matches the short syntax of a port: [[host_ip:]published:]target[/protocol]
+var portPattern = regexp.MustCompile(`^(?:(?:(\[[^\]]*\]|[^:]*):)?([0-9-]*):)?([0-9]+)(?:/(tcp|udp))?$`)
+
+// importPorts converts the published ports into public endpoints
+func (i *composeImporter) importPorts(serviceName string, container *v1.ContainerComponent, value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected ports %v", value)
+	}
+	for _, item := range items {
+		var target, published, protocol string
+		switch port := item.(type) {
+		case map[string]interface{}:
+			target = fmt.Sprint(port["target"])
+			if port["published"] != nil {
+				published = fmt.Sprint(port["published"])
+			}
+			if port["protocol"] != nil {
+				protocol = fmt.Sprint(port["protocol"])
+			}
+		default:
+			matches := portPattern.FindStringSubmatch(fmt.Sprint(port))
+			if matches == nil {
+				i.warnf("service %s: port %v is not supported", serviceName, port)
+				continue
+			}
+			published, target, protocol = matches[2], matches[3], matches[4]
+		}
+		targetPort, err := strconv.Atoi(target)
+		if err != nil {
+			return fmt.Errorf("invalid target port %s", target)
+		}
+		if published != "" && published != target {
+			i.warnf("service %s: port %s is published on %s, the endpoint uses the target port", serviceName, target, published)
+		}
+		i.addEndpoint(container, targetPort, protocol, v1.PublicEndpointExposure)
+	}
+	return nil
+}
+
+// importExpose converts the exposed ports into internal endpoints
+func (i *composeImporter) importExpose(container *v1.ContainerComponent, value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected expose %v", value)
+	}
+	for _, item := range items {
+		target, protocol, _ := strings.Cut(fmt.Sprint(item), "/")
+		targetPort, err := strconv.Atoi(target)
+		if err != nil {
+			return fmt.Errorf("invalid port %v", item)
+		}
+		i.addEndpoint(container, targetPort, protocol, v1.InternalEndpointExposure)
+	}
+	return nil
+}
+
+func (i *composeImporter) addEndpoint(container *v1.ContainerComponent, targetPort int, protocol string, exposure v1.EndpointExposure) {
+	for j := range container.Endpoints {
+		if container.Endpoints[j].TargetPort == targetPort {
+			// a port both exposed and published is public
+			if exposure == v1.PublicEndpointExposure {
+				container.Endpoints[j].Exposure = exposure
+			}
+			return
+		}
+	}
+	endpoint := v1.Endpoint{
+		TargetPort: targetPort,
+		Exposure:   exposure,
+	}
+	if protocol == "udp" {
+		endpoint.Protocol = v1.UDPEndpointProtocol
+		endpoint.Name = i.endpointNames.Unique(fmt.Sprintf("udp-%d", targetPort), maxEndpointLength)
+	} else {
+		endpoint.Name = i.endpointNames.Unique(fmt.Sprintf("port-%d", targetPort), maxEndpointLength)
+	}
+	container.Endpoints = append(container.Endpoints, endpoint)
+}
+
+// importServiceVolumes converts the named volumes into volume mounts, and the bind mount of the project directory
+// into the sourceMapping of the container
+func (i *composeImporter) importServiceVolumes(serviceName string, container *v1.ContainerComponent, value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected volumes %v", value)
+	}
+	for _, item := range items {
+		var volumeType, source, target string
+		switch volume := item.(type) {
+		case map[string]interface{}:
+			volumeType = fmt.Sprint(volume["type"])
+			if volume["source"] != nil {
+				source = fmt.Sprint(volume["source"])
+			}
+			target = fmt.Sprint(volume["target"])
+		default:
+			parts := strings.Split(fmt.Sprint(volume), ":")
+			switch {
+			case len(parts) == 1:
+				volumeType, target = "volume", parts[0]
+			default:
+				source, target = parts[0], parts[1]
+				volumeType = "volume"
+				if strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") {
+					volumeType = "bind"
+				}
+			}
+		}
+
+		switch {
+		case volumeType == "bind" && !path.IsAbs(source) && path.Join(i.baseDir, source) == ".":
+			container.MountSources = pointer.Bool(true)
+			container.SourceMapping = target
+		case volumeType == "volume" && source != "":
+			componentName, ok := i.volumes[source]
+			if !ok {
+				componentName = i.names.Unique(source, 0)
+				i.volumes[source] = componentName
+			}
+			i.volumeServices[source] = append(i.volumeServices[source], serviceName)
+			container.VolumeMounts = append(container.VolumeMounts, v1.VolumeMount{Name: componentName, Path: target})
+		default:
+			i.warnf("service %s: %s volume %s is not supported", serviceName, volumeType, target)
+		}
+	}
+	return nil
+}
+
+// importDeploy converts the resource limits and reservations of a deploy section
+func (i *composeImporter) importDeploy(serviceName string, container *v1.ContainerComponent, value interface{}) error {
+	deploy, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("unexpected deploy section %v", value)
+	}
+	for _, key := range sortedKeys(deploy) {
+		if key != "resources" {
+			i.warnf("service %s: deploy %s is not supported", serviceName, key)
+			continue
+		}
+		resources, _ := deploy[key].(map[string]interface{})
+		for _, kind := range sortedKeys(resources) {
+			constraints, _ := resources[kind].(map[string]interface{})
+			var memory, cpu *string
+			switch kind {
+			case "limits":
+				memory, cpu = &container.MemoryLimit, &container.CpuLimit
+			case "reservations":
+				memory, cpu = &container.MemoryRequest, &container.CpuRequest
+			default:
+				i.warnf("service %s: deploy resources %s is not supported", serviceName, kind)
+				continue
+			}
+			for _, name := range sortedKeys(constraints) {
+				constraint := constraints[name]
+				var err error
+				switch name {
+				case "memory":
+					*memory, err = toMemoryQuantity(fmt.Sprint(constraint))
+				case "cpus":
+					*cpu, err = toCPUQuantity(fmt.Sprint(constraint))
+				default:
+					i.warnf("service %s: deploy resources %s %s is not supported", serviceName, kind, name)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// importVolumes converts the Compose volumes mounted by services into volume components
+func (i *composeImporter) importVolumes(declared map[string]map[string]interface{}) ([]v1.Component, error) {
+	volumeNames := make([]string, 0, len(i.volumes))
+	for volumeName := range i.volumes {
+		volumeNames = append(volumeNames, volumeName)
+	}
+	sort.Strings(volumeNames)
+	declaredNames := make([]string, 0, len(declared))
+	for volumeName := range declared {
+		declaredNames = append(declaredNames, volumeName)
+	}
+	sort.Strings(declaredNames)
+	for _, volumeName := range declaredNames {
+		if _, ok := i.volumes[volumeName]; !ok {
+			i.warnf("volume %s: the volume is not mounted by any service, it is ignored", volumeName)
+		}
+	}
+
+	var components []v1.Component
+	for _, volumeName := range volumeNames {
+		if _, ok := declared[volumeName]; !ok {
+			return nil, fmt.Errorf("volume %s is mounted by services %v but is not declared", volumeName, i.volumeServices[volumeName])
+		}
+		for _, key := range sortedKeys(declared[volumeName]) {
+			i.warnf("volume %s: %s is not supported", volumeName, key)
+		}
+		components = append(components, v1.Component{
+			Name: i.volumes[volumeName],
+			ComponentUnion: v1.ComponentUnion{
+				Volume: &v1.VolumeComponent{},
+			},
+		})
+	}
+	return components, nil
+}
+
+// stringOrList converts a command, either a list or a string split as a shell would do, into a list
+func stringOrList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return importer.ShellSplit(v)
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("unexpected command %v", value)
+	}
+}
+
+// memoryPattern matches a Compose byte value: a number with an optional b, k, m or g unit
+var memoryPattern = regexp.MustCompile(`^([0-9]+)\s*([bkmg]?)b?$`)
+
+// toMemoryQuantity converts a Compose byte value into a Kubernetes quantity
+func toMemoryQuantity(value string) (string, error) {
+	matches := memoryPattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(value)))
+	if matches == nil {
+		return "", fmt.Errorf("invalid memory %s", value)
+	}
+	bytes, err := strconv.ParseInt(matches[1], 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid memory %s: %w", value, err)
+	}
+	shift := map[string]uint{"": 0, "b": 0, "k": 10, "m": 20, "g": 30}[matches[2]]
+	return resource.NewQuantity(bytes<<shift, resource.BinarySI).String(), nil
+}
+
+// toCPUQuantity converts a Compose number of CPUs into a Kubernetes quantity
+func toCPUQuantity(value string) (string, error) {
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid cpus %s: %w", value, err)
+	}
+	return quantity.String(), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isURL(s string) bool {
+	return strings.Contains(s, "://") || strings.HasPrefix(s, "git@")
+}
@@ -0,0 +1,114 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compose
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	devfilepkg "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestImport(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "compose.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read Compose file: %v", err)
+	}
+	devfileObj, warnings, err := Import(content, Options{})
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+
+	got, err := yaml.Marshal(devfileObj.Data)
+	if err != nil {
+		t.Fatalf("failed to marshal devfile: %v", err)
+	}
+	goldenPath := filepath.Join("testdata", "devfile.yaml")
+	if *update {
+		if err = os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("Import() mismatch with golden file (-want +got):\n%s", diff)
+	}
+
+	if _, _, err = devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Data: got}); err != nil {
+		t.Errorf("imported devfile is not valid: %v", err)
+	}
+
+	wantWarnings := []string{
+		"top-level element networks is not supported",
+		"service db: healthcheck is not supported",
+		"service db: port 5432 is published on 15432, the endpoint uses the target port",
+		"service db: bind volume /docker-entrypoint-initdb.d/init.sql is not supported",
+		"service web: depends_on is not supported",
+		"service web: networks is not supported",
+		"volume unused: the volume is not mounted by any service, it is ignored",
+		"volume node_modules: driver is not supported",
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Errorf("Import() warnings mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "no service",
+			content: "volumes:\n  data: {}\n",
+			wantErr: "the Compose file has no service",
+		},
+		{
+			name:    "service without image",
+			content: "services:\n  app:\n    command: run\n",
+			wantErr: "failed to import service app: the service has neither an image nor a build section",
+		},
+		{
+			name:    "undeclared volume",
+			content: "services:\n  app:\n    image: app\n    volumes:\n      - data:/data\n",
+			wantErr: "volume data is mounted by services [app] but is not declared",
+		},
+		{
+			name:    "invalid memory",
+			content: "services:\n  app:\n    image: app\n    deploy:\n      resources:\n        limits:\n          memory: lots\n",
+			wantErr: "failed to import service app: invalid deploy: invalid memory lots",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Import([]byte(tt.content), Options{})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Import() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
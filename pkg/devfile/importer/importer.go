@@ -0,0 +1,158 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package importer holds the helpers shared by the importers building devfiles from other formats.
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	devfileCtx "github.com/devfile/library/v2/pkg/devfile/parser/context"
+	"github.com/devfile/library/v2/pkg/devfile/parser/data"
+	"github.com/devfile/library/v2/pkg/util"
+)
+
+const (
+	// DefaultSchemaVersion is the schema version of the imported devfiles
+	DefaultSchemaVersion = "2.2.0"
+	// DefaultDevfilePath is the path the imported devfiles are written to, unless another path is provided
+	DefaultDevfilePath = "devfile.yaml"
+
+	// maxNameLength is the maximum length of the name of a devfile component
+	maxNameLength = 63
+)
+
+var invalidNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// NewDevfileObj returns an empty devfile object with the given schema version, to be written to the given path.
+// The schema version defaults to DefaultSchemaVersion, and the path to DefaultDevfilePath.
+func NewDevfileObj(path string, schemaVersion string) (parser.DevfileObj, error) {
+	if path == "" {
+		path = DefaultDevfilePath
+	}
+	if schemaVersion == "" {
+		schemaVersion = DefaultSchemaVersion
+	}
+	devfileData, err := data.NewDevfileData(schemaVersion)
+	if err != nil {
+		return parser.DevfileObj{}, err
+	}
+	devfileData.SetSchemaVersion(schemaVersion)
+	return parser.DevfileObj{
+		Ctx:  devfileCtx.NewDevfileCtx(path),
+		Data: devfileData,
+	}, nil
+}
+
+// Names generates the names of the devfile components and endpoints, which must be unique in a devfile
+type Names struct {
+	used map[string]bool
+}
+
+// NewNames returns an empty set of names
+func NewNames() *Names {
+	return &Names{used: map[string]bool{}}
+}
+
+// Unique returns a valid and unused name derived from the given name, with at most maxLength characters,
+// and reserves it. A maxLength of 0 stands for the maximum length of a component name.
+func (n *Names) Unique(name string, maxLength int) string {
+	if maxLength <= 0 || maxLength > maxNameLength {
+		maxLength = maxNameLength
+	}
+	base := SanitizeName(name, maxLength)
+	unique := base
+	for i := 2; n.used[unique]; i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		unique = strings.TrimRight(util.TruncateString(base, maxLength-len(suffix)), "-") + suffix
+	}
+	n.used[unique] = true
+	return unique
+}
+
+// SanitizeName converts a name into a valid devfile name, made of lower case alphanumeric characters or '-',
+// starting and ending with an alphanumeric character, with at most maxLength characters.
+// A maxLength of 0 stands for the maximum length of a component name.
+func SanitizeName(name string, maxLength int) string {
+	if maxLength <= 0 || maxLength > maxNameLength {
+		maxLength = maxNameLength
+	}
+	name = invalidNameCharacters.ReplaceAllString(strings.ToLower(name), "-")
+	name = strings.Trim(util.TruncateString(strings.Trim(name, "-"), maxLength), "-")
+	if name == "" {
+		name = "x"
+	}
+	return name
+}
+
+// ShellSplit splits a command line into words, handling quotes and backslash escapes
+func ShellSplit(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inWord = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inWord = r, true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", s)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// ShellJoin joins words into a command line, quoting the words as needed
+func ShellJoin(words []string) string {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		if shellSafe.MatchString(word) {
+			quoted = append(quoted, word)
+		} else {
+			quoted = append(quoted, "'"+strings.ReplaceAll(word, "'", `'"'"'`)+"'")
+		}
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,73 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package importer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNamesUnique(t *testing.T) {
+	names := NewNames()
+	tests := []struct {
+		name      string
+		maxLength int
+		want      string
+	}{
+		{name: "My_App", want: "my-app"},
+		{name: "my-app", want: "my-app-2"},
+		{name: "-My App-", want: "my-app-3"},
+		{name: "port-65535-udp", maxLength: 15, want: "port-65535-udp"},
+		{name: "port-65535-udp", maxLength: 15, want: "port-65535-ud-2"},
+		{name: "___", want: "x"},
+	}
+	for _, tt := range tests {
+		if got := names.Unique(tt.name, tt.maxLength); got != tt.want {
+			t.Errorf("Unique(%q, %d) = %q, want %q", tt.name, tt.maxLength, got, tt.want)
+		}
+	}
+}
+
+func TestNewDevfileObj(t *testing.T) {
+	devfileObj, err := NewDevfileObj("", "")
+	if err != nil {
+		t.Fatalf("NewDevfileObj() unexpected error: %v", err)
+	}
+	if got := devfileObj.Data.GetSchemaVersion(); got != DefaultSchemaVersion {
+		t.Errorf("NewDevfileObj() schema version = %q, want %q", got, DefaultSchemaVersion)
+	}
+	if _, err = NewDevfileObj("", "1.0.0"); err == nil {
+		t.Errorf("NewDevfileObj() expected error for an unsupported schema version")
+	}
+}
+
+func TestShellSplitAndJoin(t *testing.T) {
+	words, err := ShellSplit(`npm run dev -- --name "my app" 'it''s' a\ b`)
+	if err != nil {
+		t.Fatalf("ShellSplit() unexpected error: %v", err)
+	}
+	wantWords := []string{"npm", "run", "dev", "--", "--name", "my app", "its", "a b"}
+	if diff := cmp.Diff(wantWords, words); diff != "" {
+		t.Errorf("ShellSplit() mismatch (-want +got):\n%s", diff)
+	}
+	if got, want := ShellJoin(words), `npm run dev -- --name 'my app' its 'a b'`; got != want {
+		t.Errorf("ShellJoin() = %q, want %q", got, want)
+	}
+	if _, err = ShellSplit(`echo "unterminated`); err == nil {
+		t.Errorf("ShellSplit() expected error for an unterminated quote")
+	}
+}
@@ -0,0 +1,594 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubernetes imports Kubernetes manifests, as parsed by parser.ParseKubernetesYaml, into a devfile.
+package kubernetes
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	v1 "github.com/devfile/api/v2/pkg/apis/workspaces/v1alpha2"
+	"github.com/devfile/api/v2/pkg/attributes"
+	devfilepkg "github.com/devfile/api/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/importer"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	persistentVolumeClaimKind = "PersistentVolumeClaim"
+	maxEndpointLength         = 15
+)
+
+// Options is a struct that contains the options to import Kubernetes manifests
+type Options struct {
+	// Name is the name of the devfile. Defaults to the name of the first Deployment.
+	Name string
+	// DevfilePath is the path the devfile is written to. Defaults to importer.DefaultDevfilePath.
+	DevfilePath string
+	// SchemaVersion is the schema version of the devfile. Defaults to importer.DefaultSchemaVersion.
+	SchemaVersion string
+}
+
+// Import builds a devfile from Kubernetes resources.
+// It returns the devfile, and warnings for the resources fields which cannot be represented in a devfile.
+//
+// The containers of the Deployments become container components:
+// - the image, command, args, env, and cpu and memory resources are set on the components
+// - the container ports become endpoints, with a public exposure if they are reached by an Ingress or a Route,
+// an internal exposure if they are reached by a Service only, and a none exposure otherwise
+// - the volume mounts of PersistentVolumeClaims become volume components, sized after the PersistentVolumeClaims
+// found in the resources, and the volume mounts of emptyDir volumes become ephemeral volume components
+// - the other fields of the containers are preserved in the container-overrides attribute
+//
+// The init containers become container components applied by preStart events. The fields of the pod spec of
+// the first Deployment are preserved in the top-level pod-overrides attribute, so that generator.GetPodTemplateSpec
+// reproduces the original pod. The containers of the other Deployments are set with dedicatedPod, and the fields
+// of their pod spec are preserved in the pod-overrides attribute of their first container.
+//
+// The other resources become inlined Kubernetes components.
+func Import(resources parser.KubernetesResources, opts Options) (parser.DevfileObj, []string, error) {
+	if len(resources.Deployments) == 0 {
+		return parser.DevfileObj{}, nil, errors.New("no Deployment to import")
+	}
+
+	devfileObj, err := importer.NewDevfileObj(opts.DevfilePath, opts.SchemaVersion)
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	name := opts.Name
+	if name == "" {
+		name = resources.Deployments[0].Name
+	}
+	devfileObj.Data.SetMetadata(devfilepkg.DevfileMetadata{Name: importer.SanitizeName(name, 0)})
+
+	i := &kubernetesImporter{
+		resources:       resources,
+		names:           importer.NewNames(),
+		endpointNames:   importer.NewNames(),
+		commandIDs:      importer.NewNames(),
+		claims:          map[string]string{},
+		usedClaims:      map[string]bool{},
+		claimComponents: map[string]string{},
+	}
+	if err = i.indexOthers(); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+
+	var containers, volumes []v1.Component
+	var commands []v1.Command
+	var preStart []string
+	for d := range resources.Deployments {
+		deployment := &resources.Deployments[d]
+		imported, err := i.importDeployment(deployment, d > 0)
+		if err != nil {
+			return parser.DevfileObj{}, nil, errors.Wrapf(err, "failed to import Deployment %s", deployment.Name)
+		}
+		containers = append(containers, imported.containers...)
+		volumes = append(volumes, imported.volumes...)
+		commands = append(commands, imported.commands...)
+		preStart = append(preStart, imported.preStart...)
+
+		if imported.podOverrides == nil {
+			continue
+		}
+		if d == 0 {
+			if err = devfileObj.Data.AddAttributes(generator.PodOverridesAttribute, imported.podOverrides); err != nil {
+				return parser.DevfileObj{}, nil, err
+			}
+		} else if len(imported.containers) > 0 {
+			var putErr error
+			first := &containers[len(containers)-len(imported.containers)]
+			first.Attributes = getAttributes(first.Attributes).Put(generator.PodOverridesAttribute, imported.podOverrides, &putErr)
+			if putErr != nil {
+				return parser.DevfileObj{}, nil, putErr
+			}
+		}
+	}
+
+	kubernetesComponents, err := i.importOthers()
+	if err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+
+	components := append(append(containers, volumes...), kubernetesComponents...)
+	if err = devfileObj.Data.AddComponents(components); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if err = devfileObj.Data.AddCommands(commands); err != nil {
+		return parser.DevfileObj{}, nil, err
+	}
+	if len(preStart) > 0 {
+		if err = devfileObj.Data.AddEvents(v1.Events{DevWorkspaceEvents: v1.DevWorkspaceEvents{PreStart: preStart}}); err != nil {
+			return parser.DevfileObj{}, nil, err
+		}
+	}
+	return devfileObj, i.warnings, nil
+}
+
+// kubernetesImporter holds the state shared by the functions importing the resources
+type kubernetesImporter struct {
+	resources     parser.KubernetesResources
+	names         *importer.Names
+	endpointNames *importer.Names
+	commandIDs    *importer.Names
+	// claims are the sizes of the PersistentVolumeClaims found in the resources, indexed by name
+	claims map[string]string
+	// usedClaims are the PersistentVolumeClaims converted into volume components
+	usedClaims map[string]bool
+	// claimComponents are the names of the volume components, indexed by PersistentVolumeClaim name
+	claimComponents map[string]string
+	warnings        []string
+}
+
+func (i *kubernetesImporter) warnf(format string, a ...interface{}) {
+	i.warnings = append(i.warnings, fmt.Sprintf(format, a...))
+}
+
+// importedDeployment is the result of the import of a Deployment
+type importedDeployment struct {
+	containers   []v1.Component
+	volumes      []v1.Component
+	commands     []v1.Command
+	preStart     []string
+	podOverrides map[string]interface{}
+}
+
+// endpointInfo describes how a container port is reached
+type endpointInfo struct {
+	exposure v1.EndpointExposure
+	path     string
+	secure   bool
+}
+
+func (i *kubernetesImporter) importDeployment(deployment *appsv1.Deployment, dedicatedPod bool) (*importedDeployment, error) {
+	imported := &importedDeployment{}
+	podSpec := deployment.Spec.Template.Spec
+	endpoints := i.getEndpointInfos(deployment)
+
+	// volumeComponents are the names of the volume components, indexed by pod volume name
+	volumeComponents := map[string]string{}
+	for _, volume := range podSpec.Volumes {
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claimName := volume.PersistentVolumeClaim.ClaimName
+			if componentName, ok := i.claimComponents[claimName]; ok {
+				volumeComponents[volume.Name] = componentName
+				continue
+			}
+			componentName := i.names.Unique(volume.Name, 0)
+			i.claimComponents[claimName] = componentName
+			volumeComponents[volume.Name] = componentName
+			size, ok := i.claims[claimName]
+			if ok {
+				i.usedClaims[claimName] = true
+			} else {
+				i.warnf("Deployment %s: PersistentVolumeClaim %s is not found, the volume %s has the default size", deployment.Name, claimName, volume.Name)
+			}
+			imported.volumes = append(imported.volumes, v1.Component{
+				Name: componentName,
+				ComponentUnion: v1.ComponentUnion{
+					Volume: &v1.VolumeComponent{Volume: v1.Volume{Size: size}},
+				},
+			})
+		case volume.EmptyDir != nil:
+			componentName := i.names.Unique(volume.Name, 0)
+			volumeComponents[volume.Name] = componentName
+			vol := v1.Volume{Ephemeral: pointer.Bool(true)}
+			if volume.EmptyDir.SizeLimit != nil {
+				vol.Size = volume.EmptyDir.SizeLimit.String()
+			}
+			imported.volumes = append(imported.volumes, v1.Component{
+				Name: componentName,
+				ComponentUnion: v1.ComponentUnion{
+					Volume: &v1.VolumeComponent{Volume: vol},
+				},
+			})
+		default:
+			i.warnf("Deployment %s: volume %s is neither a PersistentVolumeClaim nor an emptyDir, it is not supported", deployment.Name, volume.Name)
+		}
+	}
+
+	for _, container := range podSpec.Containers {
+		component, err := i.importContainer(deployment.Name, container, endpoints, volumeComponents)
+		if err != nil {
+			return nil, err
+		}
+		if dedicatedPod {
+			component.Container.DedicatedPod = pointer.Bool(true)
+		}
+		imported.containers = append(imported.containers, component)
+	}
+
+	for _, container := range podSpec.InitContainers {
+		component, err := i.importContainer(deployment.Name, container, nil, volumeComponents)
+		if err != nil {
+			return nil, err
+		}
+		i.warnf("Deployment %s: init container %s is applied by a preStart event, it is renamed in the generated pod", deployment.Name, container.Name)
+		imported.containers = append(imported.containers, component)
+		id := i.commandIDs.Unique("init-"+component.Name, 0)
+		imported.commands = append(imported.commands, v1.Command{
+			Id: id,
+			CommandUnion: v1.CommandUnion{
+				Apply: &v1.ApplyCommand{Component: component.Name},
+			},
+		})
+		imported.preStart = append(imported.preStart, id)
+	}
+
+	// the fields of the pod spec which cannot be expressed by the devfile are preserved in the pod-overrides attribute
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+	if err != nil {
+		return nil, err
+	}
+	delete(spec, "containers")
+	delete(spec, "initContainers")
+	delete(spec, "volumes")
+	if spec = pruneEmpty(spec); len(spec) > 0 {
+		imported.podOverrides = map[string]interface{}{"spec": spec}
+	}
+	return imported, nil
+}
+
+// importContainer converts a container into a container component
+func (i *kubernetesImporter) importContainer(deploymentName string, container corev1.Container, endpoints map[int32]endpointInfo, volumeComponents map[string]string) (v1.Component, error) {
+	c := &v1.ContainerComponent{
+		Container: v1.Container{
+			Image:        container.Image,
+			Command:      container.Command,
+			Args:         container.Args,
+			MountSources: pointer.Bool(false),
+		},
+	}
+
+	for _, env := range container.Env {
+		if env.ValueFrom != nil {
+			i.warnf("Deployment %s: container %s: env %s is set from a source, which is not supported", deploymentName, container.Name, env.Name)
+			continue
+		}
+		c.Env = append(c.Env, v1.EnvVar{Name: env.Name, Value: env.Value})
+	}
+	if len(container.EnvFrom) > 0 {
+		i.warnf("Deployment %s: container %s: envFrom is not supported", deploymentName, container.Name)
+	}
+
+	if q, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+		c.MemoryLimit = q.String()
+	}
+	if q, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+		c.CpuLimit = q.String()
+	}
+	if q, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+		c.MemoryRequest = q.String()
+	}
+	if q, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+		c.CpuRequest = q.String()
+	}
+
+	for _, port := range container.Ports {
+		info, ok := endpoints[port.ContainerPort]
+		if !ok {
+			info = endpointInfo{exposure: v1.NoneEndpointExposure}
+		}
+		name := port.Name
+		if name == "" {
+			name = "port-" + strconv.Itoa(int(port.ContainerPort))
+		}
+		endpoint := v1.Endpoint{
+			Name:       i.endpointNames.Unique(name, maxEndpointLength),
+			TargetPort: int(port.ContainerPort),
+			Exposure:   info.exposure,
+			Path:       info.path,
+		}
+		if info.secure {
+			endpoint.Secure = pointer.Bool(true)
+		}
+		if port.Protocol == corev1.ProtocolUDP {
+			endpoint.Protocol = v1.UDPEndpointProtocol
+		}
+		c.Endpoints = append(c.Endpoints, endpoint)
+	}
+
+	for _, volumeMount := range container.VolumeMounts {
+		componentName, ok := volumeComponents[volumeMount.Name]
+		if !ok {
+			i.warnf("Deployment %s: container %s: the volume mount %s is not supported", deploymentName, container.Name, volumeMount.MountPath)
+			continue
+		}
+		if volumeMount.SubPath != "" || volumeMount.SubPathExpr != "" || volumeMount.ReadOnly {
+			i.warnf("Deployment %s: container %s: the subPath and readOnly of the volume mount %s are not supported", deploymentName, container.Name, volumeMount.MountPath)
+		}
+		c.VolumeMounts = append(c.VolumeMounts, v1.VolumeMount{Name: componentName, Path: volumeMount.MountPath})
+	}
+
+	component := v1.Component{
+		Name:           i.names.Unique(container.Name, 0),
+		ComponentUnion: v1.ComponentUnion{Container: c},
+	}
+
+	// the fields of the container which cannot be expressed by the devfile are preserved in the container-overrides attribute
+	overrides, err := getContainerOverrides(container)
+	if err != nil {
+		return v1.Component{}, err
+	}
+	if len(overrides) > 0 {
+		var putErr error
+		component.Attributes = attributes.Attributes{}.Put(generator.ContainerOverridesAttribute, overrides, &putErr)
+		if putErr != nil {
+			return v1.Component{}, putErr
+		}
+	}
+	return component, nil
+}
+
+// getContainerOverrides returns the fields of the container which are not set from the container component
+func getContainerOverrides(container corev1.Container) (map[string]interface{}, error) {
+	overrides, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&container)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range []string{"name", "image", "command", "args", "env", "ports", "volumeMounts"} {
+		delete(overrides, field)
+	}
+	// the containers generated from a devfile pull the images pinned by digest if they are not present,
+	// and always pull the other images
+	defaultPullPolicy := corev1.PullAlways
+	if strings.Contains(container.Image, "@") {
+		defaultPullPolicy = corev1.PullIfNotPresent
+	}
+	if container.ImagePullPolicy == defaultPullPolicy {
+		delete(overrides, "imagePullPolicy")
+	}
+	if resources, ok := overrides["resources"].(map[string]interface{}); ok {
+		for _, kind := range []string{"limits", "requests"} {
+			if list, ok := resources[kind].(map[string]interface{}); ok {
+				delete(list, string(corev1.ResourceCPU))
+				delete(list, string(corev1.ResourceMemory))
+			}
+		}
+	}
+	return pruneEmpty(overrides), nil
+}
+
+// getEndpointInfos returns how the ports of the pods of the Deployment are reached, indexed by container port
+func (i *kubernetesImporter) getEndpointInfos(deployment *appsv1.Deployment) map[int32]endpointInfo {
+	podLabels := labels.Set(deployment.Spec.Template.Labels)
+	containerPorts := map[string]int32{}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name != "" {
+				containerPorts[port.Name] = port.ContainerPort
+			}
+		}
+	}
+	resolve := func(target intstr.IntOrString) (int32, bool) {
+		if target.Type == intstr.String {
+			port, ok := containerPorts[target.StrVal]
+			return port, ok
+		}
+		return target.IntVal, true
+	}
+
+	infos := map[int32]endpointInfo{}
+	setInfo := func(port int32, info endpointInfo) {
+		// a port reached by an Ingress or a Route is public, whatever the other Services
+		if existing, ok := infos[port]; ok && existing.exposure == v1.PublicEndpointExposure {
+			return
+		}
+		infos[port] = info
+	}
+
+	// servicePorts are the container ports reached by the service ports, indexed by service name and service port
+	servicePorts := map[string]map[string]int32{}
+	for _, service := range i.resources.Services {
+		if len(service.Spec.Selector) == 0 || !labels.SelectorFromSet(service.Spec.Selector).Matches(podLabels) {
+			continue
+		}
+		ports := map[string]int32{}
+		for _, port := range service.Spec.Ports {
+			target := port.TargetPort
+			if target.Type == intstr.Int && target.IntVal == 0 {
+				target = intstr.FromInt(int(port.Port))
+			}
+			containerPort, ok := resolve(target)
+			if !ok {
+				continue
+			}
+			ports[strconv.Itoa(int(port.Port))] = containerPort
+			// Routes reference the target port of the Service
+			ports["target:"+target.String()] = containerPort
+			if port.Name != "" {
+				ports[port.Name] = containerPort
+			}
+			setInfo(containerPort, endpointInfo{exposure: v1.InternalEndpointExposure})
+		}
+		servicePorts[service.Name] = ports
+	}
+
+	for _, ingress := range i.resources.Ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			secure := false
+			for _, tls := range ingress.Spec.TLS {
+				if len(tls.Hosts) == 0 {
+					secure = true
+				}
+				for _, host := range tls.Hosts {
+					secure = secure || host == rule.Host
+				}
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service == nil {
+					continue
+				}
+				key := path.Backend.Service.Port.Name
+				if key == "" {
+					key = strconv.Itoa(int(path.Backend.Service.Port.Number))
+				}
+				if containerPort, ok := servicePorts[path.Backend.Service.Name][key]; ok {
+					setInfo(containerPort, endpointInfo{exposure: v1.PublicEndpointExposure, path: path.Path, secure: secure})
+				}
+			}
+		}
+	}
+
+	for _, route := range i.resources.Routes {
+		ports, ok := servicePorts[route.Spec.To.Name]
+		if !ok || (route.Spec.To.Kind != "" && route.Spec.To.Kind != "Service") {
+			continue
+		}
+		info := endpointInfo{exposure: v1.PublicEndpointExposure, path: route.Spec.Path, secure: route.Spec.TLS != nil}
+		if route.Spec.Port == nil {
+			// without a port, the Route reaches all the ports of the Service
+			for key, containerPort := range ports {
+				if _, err := strconv.Atoi(key); err == nil {
+					setInfo(containerPort, info)
+				}
+			}
+			continue
+		}
+		target := route.Spec.Port.TargetPort
+		if containerPort, ok := ports[target.StrVal]; ok && target.Type == intstr.String {
+			setInfo(containerPort, info)
+		} else if containerPort, ok := ports["target:"+target.String()]; ok {
+			setInfo(containerPort, info)
+		}
+	}
+	return infos
+}
+
+// indexOthers indexes the PersistentVolumeClaims found in the other resources
+func (i *kubernetesImporter) indexOthers() error {
+	for _, other := range i.resources.Others {
+		obj, ok := other.(map[string]interface{})
+		if !ok || obj["kind"] != persistentVolumeClaimKind {
+			continue
+		}
+		var pvc corev1.PersistentVolumeClaim
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(normalize(obj), &pvc); err != nil {
+			return errors.Wrap(err, "failed to decode PersistentVolumeClaim")
+		}
+		size := ""
+		if q, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			size = q.String()
+		}
+		i.claims[pvc.Name] = size
+	}
+	return nil
+}
+
+// importOthers converts the other resources, except the PersistentVolumeClaims converted into volume components,
+// into inlined Kubernetes components
+func (i *kubernetesImporter) importOthers() ([]v1.Component, error) {
+	var components []v1.Component
+	for _, other := range i.resources.Others {
+		obj, ok := other.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		metadata, _ := obj["metadata"].(map[string]interface{})
+		name := fmt.Sprint(metadata["name"])
+		if obj["kind"] == persistentVolumeClaimKind && i.usedClaims[name] {
+			continue
+		}
+		inlined, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		components = append(components, v1.Component{
+			Name: i.names.Unique(fmt.Sprintf("%v-%s", obj["kind"], name), 0),
+			ComponentUnion: v1.ComponentUnion{
+				Kubernetes: &v1.KubernetesComponent{
+					K8sLikeComponent: v1.K8sLikeComponent{
+						K8sLikeComponentLocation: v1.K8sLikeComponentLocation{Inlined: string(inlined)},
+					},
+				},
+			},
+		})
+	}
+	return components, nil
+}
+
+// normalize converts the values decoded by ReadKubernetesYaml into the JSON types expected by the unstructured converter
+func normalize(obj map[string]interface{}) map[string]interface{} {
+	b, err := yaml.Marshal(obj)
+	if err != nil {
+		return obj
+	}
+	var normalized map[string]interface{}
+	if err = yaml.Unmarshal(b, &normalized); err != nil {
+		return obj
+	}
+	return normalized
+}
+
+// pruneEmpty removes the empty maps, lists and nil values from an unstructured object
+func pruneEmpty(obj map[string]interface{}) map[string]interface{} {
+	for key, value := range obj {
+		switch v := value.(type) {
+		case nil:
+			delete(obj, key)
+		case map[string]interface{}:
+			if len(pruneEmpty(v)) == 0 {
+				delete(obj, key)
+			}
+		case []interface{}:
+			if len(v) == 0 {
+				delete(obj, key)
+			}
+		}
+	}
+	return obj
+}
+
+func getAttributes(a attributes.Attributes) attributes.Attributes {
+	if a == nil {
+		return attributes.Attributes{}
+	}
+	return a
+}
@@ -0,0 +1,114 @@
+//
+// Copyright Red Hat
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kubernetes
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	devfilepkg "github.com/devfile/library/v2/pkg/devfile"
+	"github.com/devfile/library/v2/pkg/devfile/generator"
+	"github.com/devfile/library/v2/pkg/devfile/parser"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/yaml"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func readResources(t *testing.T) parser.KubernetesResources {
+	content, err := os.ReadFile(filepath.Join("testdata", "manifests.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read manifests: %v", err)
+	}
+	values, err := parser.ReadKubernetesYaml(parser.YamlSrc{Data: content}, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to read manifests: %v", err)
+	}
+	resources, err := parser.ParseKubernetesYaml(values)
+	if err != nil {
+		t.Fatalf("failed to parse manifests: %v", err)
+	}
+	return resources
+}
+
+func TestImport(t *testing.T) {
+	resources := readResources(t)
+	devfileObj, warnings, err := Import(resources, Options{})
+	if err != nil {
+		t.Fatalf("Import() unexpected error: %v", err)
+	}
+
+	got, err := yaml.Marshal(devfileObj.Data)
+	if err != nil {
+		t.Fatalf("failed to marshal devfile: %v", err)
+	}
+	goldenPath := filepath.Join("testdata", "devfile.yaml")
+	if *update {
+		if err = os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("Import() mismatch with golden file (-want +got):\n%s", diff)
+	}
+
+	wantWarnings := []string{
+		"Deployment shop: volume config is neither a PersistentVolumeClaim nor an emptyDir, it is not supported",
+		"Deployment shop: container web: env DB_PASSWORD is set from a source, which is not supported",
+		"Deployment shop: container web: the volume mount /etc/shop is not supported",
+		"Deployment shop: init container migrate is applied by a preStart event, it is renamed in the generated pod",
+	}
+	if diff := cmp.Diff(wantWarnings, warnings); diff != "" {
+		t.Errorf("Import() warnings mismatch (-want +got):\n%s", diff)
+	}
+
+	// The pod generated from the imported devfile must reproduce the original pod, except for the fields reported as warnings
+	parsed, _, err := devfilepkg.ParseDevfileAndValidate(parser.ParserArgs{Data: got})
+	if err != nil {
+		t.Fatalf("imported devfile is not valid: %v", err)
+	}
+	podTemplateSpec, err := generator.GetPodTemplateSpec(parsed, generator.PodTemplateParams{})
+	if err != nil {
+		t.Fatalf("GetPodTemplateSpec() unexpected error: %v", err)
+	}
+	wantPodSpec := resources.Deployments[0].Spec.Template.Spec.DeepCopy()
+	wantPodSpec.Volumes = nil
+	// The init container is renamed after the preStart event applying it, as reported by the warnings
+	wantPodSpec.InitContainers[0].Name = "migrate-init-migrate-1"
+	wantPodSpec.Containers[0].Env = wantPodSpec.Containers[0].Env[:1]
+	for i := range wantPodSpec.Containers {
+		wantPodSpec.Containers[i].VolumeMounts = nil
+	}
+	gotPodSpec := podTemplateSpec.Spec
+	if !equality.Semantic.DeepEqual(*wantPodSpec, gotPodSpec) {
+		t.Errorf("GetPodTemplateSpec() mismatch with the original pod (-want +got):\n%s", cmp.Diff(*wantPodSpec, gotPodSpec))
+	}
+}
+
+func TestImportWithoutDeployment(t *testing.T) {
+	_, _, err := Import(parser.KubernetesResources{Services: []corev1.Service{{}}}, Options{})
+	if err == nil || err.Error() != "no Deployment to import" {
+		t.Errorf("Import() error = %v, want no Deployment to import", err)
+	}
+}
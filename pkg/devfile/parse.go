@@ -113,7 +113,7 @@ func ParseDevfileAndValidate(args parser.ParserArgs) (d parser.DevfileObj, varWa
 	// Use image names as selectors after variable substitution,
 	// as users might be using variables for image names.
 	if args.ImageNamesAsSelector != nil && args.ImageNamesAsSelector.Registry != "" {
-		err = replaceImageNames(&d, args.ImageNamesAsSelector.Registry, args.ImageNamesAsSelector.Tag)
+		err = replaceImageNames(&d, *args.ImageNamesAsSelector, args.DevfileUtilsClient)
 		if err != nil {
 			return d, varWarning, err
 		}
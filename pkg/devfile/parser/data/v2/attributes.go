@@ -60,6 +60,9 @@ func (d *DevfileV2) AddAttributes(key string, value interface{}) error {
 	case "2.0.0":
 		return fmt.Errorf("top-level attributes is not supported in devfile schema version 2.0.0")
 	default:
+		if d.Attributes == nil {
+			d.Attributes = attributes.Attributes{}
+		}
 		d.Attributes.Put(key, value, &err)
 	}
 
@@ -231,6 +231,19 @@ func TestAddAttributes(t *testing.T) {
 			value:          nestedValue,
 			wantAttributes: attributes.Attributes{}.PutString("key1", "value1").Put("key3", nestedValue, nil).PutString("key2", "value2"),
 		},
+		{
+			name: "Schema 2.1.0 without attributes",
+			devfilev2: &DevfileV2{
+				v1alpha2.Devfile{
+					DevfileHeader: devfilepkg.DevfileHeader{
+						SchemaVersion: "2.1.0",
+					},
+				},
+			},
+			key:            "key1",
+			value:          "value1",
+			wantAttributes: attributes.Attributes{}.PutString("key1", "value1"),
+		},
 		{
 			name: "If Schema 2.1.0 has an attribute already present, it should overwrite",
 			devfilev2: &DevfileV2{
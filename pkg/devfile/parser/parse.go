@@ -32,6 +32,7 @@ import (
 	errPkg "github.com/devfile/library/v2/pkg/devfile/parser/errors"
 	"github.com/devfile/library/v2/pkg/util"
 	registryLibrary "github.com/devfile/registry-support/registry-library/library"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog"
@@ -129,9 +130,13 @@ type ParserArgs struct {
 // The fields defined here will be used together to compute the final image names that will be built and pushed,
 // and replaced in all matching Image, Container or Kubernetes/OpenShift components.
 //
-// For Kubernetes/OpenShift components, replacement is done only in core Kubernetes resources
-// (CronJob, DaemonSet, Deployment, Job, Pod, ReplicaSet, ReplicationController, StatefulSet) that are *inlined* in those components.
-// Resources referenced via URIs will not be resolved. So you may want to also set ConvertKubernetesContentInUri to true in the parser args.
+// For Kubernetes/OpenShift components, replacement is done in the resources (either inlined or referenced via URIs)
+// whose kind has a known image location. Built-in locations are provided for core Kubernetes resources
+// (CronJob, DaemonSet, Deployment, Job, Pod, ReplicaSet, ReplicationController, StatefulSet) and for some common custom resources
+// (Knative Service, Argo Rollout, Tekton Task, OpenShift DeploymentConfig). Additional locations can be declared with ImageLocations.
+//
+// Resources referenced via URIs are resolved and inlined only if they contain a matching image name.
+// The original URI is kept in the component attributes, so that it is restored when writing the Devfile back.
 //
 // For example, if Registry is set to "<local-registry>/<user-org>" and Tag is set to "some-dynamic-unique-tag",
 // all container and Kubernetes/OpenShift components matching a relative image name (say "my-image-name") of an Image component
@@ -144,6 +149,22 @@ type ImageSelectorArgs struct {
 	// Tag represents a tag identifier under which images matching selectors will be built and pushed to.
 	// This should ideally be set to a unique identifier for each run of the caller tool.
 	Tag string
+	// ImageLocations declares additional locations of image names in Kubernetes/OpenShift resources.
+	// They are used in addition to the built-in locations.
+	ImageLocations []ImageLocation
+}
+
+// ImageLocation declares where image names can be found in Kubernetes/OpenShift resources of a given kind.
+type ImageLocation struct {
+	// GroupVersionKind identifies the resources this location applies to.
+	// An empty Version matches all versions of the Group and Kind.
+	GroupVersionKind schema.GroupVersionKind
+	// Paths is the list of JSONPath-style expressions pointing to image name fields, relative to the resource root.
+	// A path is made of dot-separated field names. Fields holding lists must be suffixed with either [*] to select all items,
+	// or [<index>] to select a single item.
+	//
+	// Example: .spec.template.spec.containers[*].image
+	Paths []string
 }
 
 // ParseDevfile func populates the devfile data, parses and validates the devfile integrity.
@@ -170,24 +191,7 @@ func ParseDevfile(args ParserArgs) (d DevfileObj, err error) {
 		d.Ctx.SetToken(args.Token)
 	}
 
-	if args.DevfileUtilsClient == nil {
-		args.DevfileUtilsClient = parserUtil.NewDevfileUtilsClient()
-	}
-
-	downloadGitResources := true
-	if args.DownloadGitResources != nil {
-		downloadGitResources = *args.DownloadGitResources
-	}
-
-	tool := resolverTools{
-		defaultNamespace:     args.DefaultNamespace,
-		registryURLs:         args.RegistryURLs,
-		context:              args.Context,
-		k8sClient:            args.K8sClient,
-		httpTimeout:          args.HTTPTimeout,
-		downloadGitResources: downloadGitResources,
-		devfileUtilsClient:   args.DevfileUtilsClient,
-	}
+	tool := newResolverTools(args)
 
 	flattenedDevfile := true
 	if args.FlattenedDevfile != nil {
@@ -246,6 +250,60 @@ type resolverTools struct {
 	devfileUtilsClient parserUtil.DevfileUtils
 }
 
+// newResolverTools returns the tools resolving the parent and the plugins of a devfile with the given parser args
+func newResolverTools(args ParserArgs) resolverTools {
+	if args.DevfileUtilsClient == nil {
+		args.DevfileUtilsClient = parserUtil.NewDevfileUtilsClient()
+	}
+
+	downloadGitResources := true
+	if args.DownloadGitResources != nil {
+		downloadGitResources = *args.DownloadGitResources
+	}
+
+	return resolverTools{
+		defaultNamespace:     args.DefaultNamespace,
+		registryURLs:         args.RegistryURLs,
+		context:              args.Context,
+		k8sClient:            args.K8sClient,
+		httpTimeout:          args.HTTPTimeout,
+		downloadGitResources: downloadGitResources,
+		devfileUtilsClient:   args.DevfileUtilsClient,
+	}
+}
+
+// FlattenDevfile returns a copy of a devfile parsed with FlattenedDevfile set to false, with the content of its parent
+// and plugins merged into it, as ParseDevfile does with a flattened content. The devfile source of args is ignored:
+// the parent and the plugins are resolved relatively to the devfile, with the other settings of args.
+func FlattenDevfile(d DevfileObj, args ParserArgs) (DevfileObj, error) {
+	content, err := json.Marshal(d.Data)
+	if err != nil {
+		return DevfileObj{}, err
+	}
+	flattened := DevfileObj{Ctx: d.Ctx}
+	devfileVersion := d.Ctx.GetApiVersion()
+	if devfileVersion == "" {
+		devfileVersion = strings.Split(d.Data.GetSchemaVersion(), "-")[0]
+	}
+	flattened.Data, err = data.NewDevfileData(devfileVersion)
+	if err != nil {
+		return DevfileObj{}, err
+	}
+	if err = json.Unmarshal(content, &flattened.Data); err != nil {
+		return DevfileObj{}, err
+	}
+
+	if err = parseParentAndPlugin(flattened, &resolutionContextTree{}, newResolverTools(args)); err != nil {
+		return DevfileObj{}, err
+	}
+	if args.SetBooleanDefaults == nil || *args.SetBooleanDefaults {
+		if err = setDefaults(flattened); err != nil {
+			return DevfileObj{}, errors.Wrap(err, "failed to setDefaults")
+		}
+	}
+	return flattened, nil
+}
+
 func populateAndParseDevfile(d DevfileObj, resolveCtx *resolutionContextTree, tool resolverTools, flattenedDevfile bool) (DevfileObj, error) {
 	var err error
 	if err = resolveCtx.hasCycle(); err != nil {
@@ -843,6 +901,15 @@ func convertK8sLikeCompUriToInlined(component *v1.Component, d devfileCtx.Devfil
 	return nil
 }
 
+// ReadKubernetesDefinitionFromURI reads in the kubernetes resources definition referenced by the uri of a Kubernetes/OpenShift component.
+// Relative URIs are resolved against the location of the devfile.
+func ReadKubernetesDefinitionFromURI(uri string, d devfileCtx.DevfileCtx, devfileUtilsClient parserUtil.DevfileUtils) ([]byte, error) {
+	if devfileUtilsClient == nil {
+		devfileUtilsClient = parserUtil.NewDevfileUtilsClient()
+	}
+	return getKubernetesDefinitionFromUri(uri, d, devfileUtilsClient)
+}
+
 // getKubernetesDefinitionFromUri read in kubernetes resources definition from uri and returns the raw content
 func getKubernetesDefinitionFromUri(uri string, d devfileCtx.DevfileCtx, devfileUtilsClient parserUtil.DevfileUtils) ([]byte, error) {
 	// validate URI